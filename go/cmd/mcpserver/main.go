@@ -0,0 +1,233 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/matpadley/MCPServer_Demo/go/internal/data"
+	"github.com/matpadley/MCPServer_Demo/go/internal/server"
+)
+
+// config holds the runtime configuration of the server
+type config struct {
+	port            string
+	store           string
+	dbPath          string
+	stdio           bool
+	shutdownTimeout time.Duration
+	callTimeout     time.Duration
+	pageSize        int
+	sessionIdle     time.Duration
+	maxSessions     int
+	logLevel        slog.Level
+	migrateStatus   bool
+}
+
+func main() {
+	cfg := parseConfig()
+
+	// In stdio mode stdout carries protocol messages, so logs must stay on stderr
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: cfg.logLevel})))
+
+	if err := run(cfg); err != nil {
+		slog.Error("mcpserver failed", "error", err)
+		os.Exit(1)
+	}
+}
+
+// parseConfig reads configuration from flags, falling back to environment variables
+func parseConfig() config {
+	var cfg config
+	flag.StringVar(&cfg.port, "port", envOrDefault("PORT", "8080"), "HTTP port to listen on (env PORT)")
+	flag.StringVar(&cfg.store, "store", envOrDefault("STORE", "sqlite"), "todo storage backend: sqlite, or memory to keep todos only until exit (env STORE)")
+	flag.StringVar(&cfg.dbPath, "db", envOrDefault("DB_PATH", "./todos.db"), "path to the SQLite database file (env DB_PATH)")
+	flag.BoolVar(&cfg.stdio, "stdio", false, "serve MCP over stdin/stdout instead of HTTP")
+	flag.DurationVar(&cfg.shutdownTimeout, "shutdown-timeout", 10*time.Second, "time allowed for in-flight requests to drain on shutdown")
+	flag.DurationVar(&cfg.callTimeout, "call-timeout", server.DefaultCallTimeout, "maximum time a single MCP request may run")
+	flag.IntVar(&cfg.pageSize, "page-size", server.DefaultPageSize, "number of items in each page of the MCP list methods")
+	flag.DurationVar(&cfg.sessionIdle, "session-idle-timeout", server.DefaultSessionIdleTimeout, "time an HTTP session may go unused before it is ended")
+	flag.IntVar(&cfg.maxSessions, "max-sessions", server.DefaultMaxSessions, "maximum number of live HTTP sessions")
+	flag.BoolVar(&cfg.migrateStatus, "migrate-status", false, "print the database schema version and pending migrations, then exit without changing the database")
+	flag.TextVar(&cfg.logLevel, "log-level", parseLogLevel(envOrDefault("LOG_LEVEL", "info")), "minimum level of the process log: debug, info, warn or error (env LOG_LEVEL)")
+	flag.Parse()
+	return cfg
+}
+
+// envOrDefault returns the value of an environment variable, or fallback when unset
+func envOrDefault(key, fallback string) string {
+	if value, ok := os.LookupEnv(key); ok && value != "" {
+		return value
+	}
+	return fallback
+}
+
+// parseLogLevel parses a slog level name, falling back to info when it is not recognised
+func parseLogLevel(name string) slog.Level {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(name)); err != nil {
+		return slog.LevelInfo
+	}
+	return level
+}
+
+// run wires the database into the MCP server and serves until a shutdown signal arrives
+func run(cfg config) error {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if cfg.pageSize < 1 {
+		return fmt.Errorf("page size must be at least 1, got %d", cfg.pageSize)
+	}
+	if cfg.sessionIdle <= 0 || cfg.maxSessions < 1 {
+		return fmt.Errorf("session idle timeout and max sessions must be positive, got %s and %d", cfg.sessionIdle, cfg.maxSessions)
+	}
+
+	if cfg.migrateStatus {
+		status, err := data.InspectMigrations(ctx, cfg.dbPath)
+		if status != nil {
+			printMigrationStatus(os.Stdout, cfg.dbPath, status)
+		}
+		if err != nil {
+			return fmt.Errorf("failed to inspect database %s: %w", cfg.dbPath, err)
+		}
+		return nil
+	}
+
+	db, err := openStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := db.Close(); err != nil {
+			slog.Error("Failed to close database", "error", err)
+		}
+	}()
+
+	mcpServer := server.NewMCPServer(db)
+	mcpServer.SetCallTimeout(cfg.callTimeout)
+	mcpServer.SetPageSize(cfg.pageSize)
+	mcpServer.SetSessionLimits(cfg.sessionIdle, cfg.maxSessions)
+
+	if cfg.stdio {
+		slog.Info("Serving MCP over stdio", storeLogAttrs(cfg)...)
+		return mcpServer.ServeStdio(ctx, os.Stdin, os.Stdout)
+	}
+
+	return serveHTTP(ctx, cfg, db, mcpServer)
+}
+
+// openStore opens the storage backend named by the configuration
+func openStore(cfg config) (data.TodoStore, error) {
+	switch cfg.store {
+	case "sqlite":
+		db, err := data.NewDatabaseContext(cfg.dbPath)
+		if err != nil {
+			return nil, fmt.Errorf("failed to open database %s: %w", cfg.dbPath, err)
+		}
+		return db, nil
+	case "memory":
+		return data.NewMemoryStore(), nil
+	default:
+		return nil, fmt.Errorf("unknown store %q, expected sqlite or memory", cfg.store)
+	}
+}
+
+// storeLogAttrs describes the storage backend for the startup log. Only the
+// sqlite store has a database path.
+func storeLogAttrs(cfg config) []any {
+	if cfg.store == "sqlite" {
+		return []any{"store", cfg.store, "database", cfg.dbPath}
+	}
+	return []any{"store", cfg.store}
+}
+
+// printMigrationStatus describes the schema version of a database and the
+// migrations startup would apply to it
+func printMigrationStatus(w io.Writer, dbPath string, status *data.MigrationStatus) {
+	fmt.Fprintf(w, "Database: %s\n", dbPath)
+
+	version := fmt.Sprintf("Schema version: %d of %d", status.Current, status.Latest)
+	if status.Inferred {
+		version += " (inferred from a database created before migrations were recorded)"
+	}
+	fmt.Fprintln(w, version)
+
+	if status.Current > status.Latest {
+		fmt.Fprintln(w, "The database is newer than this binary; the server will refuse to start.")
+		return
+	}
+	if len(status.Pending) == 0 {
+		fmt.Fprintln(w, "No pending migrations.")
+		return
+	}
+	fmt.Fprintln(w, "Pending migrations:")
+	for _, migration := range status.Pending {
+		fmt.Fprintf(w, "  %04d_%s\n", migration.Version, migration.Name)
+	}
+}
+
+// serveHTTP runs the HTTP transport and drains in-flight requests once ctx is cancelled
+func serveHTTP(ctx context.Context, cfg config, db data.TodoStore, mcpServer *server.MCPServer) error {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/mcp", mcpServer.HandleMCP)
+	mux.HandleFunc("/health", healthHandler(db))
+
+	httpServer := &http.Server{
+		Addr:              ":" + cfg.port,
+		Handler:           mux,
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	// Long-lived SSE streams would otherwise hold Shutdown open until the timeout
+	httpServer.RegisterOnShutdown(mcpServer.Close)
+
+	serveErr := make(chan error, 1)
+	go func() {
+		slog.Info("MCP server listening", append([]any{"url", "http://localhost:" + cfg.port + "/mcp"}, storeLogAttrs(cfg)...)...)
+		serveErr <- httpServer.ListenAndServe()
+	}()
+
+	select {
+	case err := <-serveErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			return fmt.Errorf("http server failed: %w", err)
+		}
+		return nil
+	case <-ctx.Done():
+	}
+
+	slog.Info("Shutting down, waiting for in-flight requests", "timeout", cfg.shutdownTimeout)
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), cfg.shutdownTimeout)
+	defer cancel()
+
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		return fmt.Errorf("failed to shut down cleanly: %w", err)
+	}
+	return nil
+}
+
+// healthHandler reports whether the server and its database are reachable
+func healthHandler(db data.TodoStore) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+
+		status := http.StatusOK
+		body := map[string]string{"status": "ok"}
+		if err := db.Ping(r.Context()); err != nil {
+			status = http.StatusServiceUnavailable
+			body = map[string]string{"status": "unavailable", "error": err.Error()}
+		}
+
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(body)
+	}
+}
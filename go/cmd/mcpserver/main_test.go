@@ -0,0 +1,113 @@
+package main
+
+import (
+	"bytes"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/matpadley/MCPServer_Demo/go/internal/data"
+)
+
+func TestHealthHandler(t *testing.T) {
+	db, err := data.NewInMemoryDatabaseContext()
+	if err != nil {
+		t.Fatalf("Failed to create in-memory database: %v", err)
+	}
+
+	handler := healthHandler(db)
+
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status 200, got %d", rec.Code)
+	}
+	if !strings.Contains(rec.Body.String(), `"ok"`) {
+		t.Errorf("Expected ok status, got %s", rec.Body.String())
+	}
+
+	db.Close()
+
+	rec = httptest.NewRecorder()
+	handler(rec, httptest.NewRequest(http.MethodGet, "/health", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("Expected status 503 after close, got %d", rec.Code)
+	}
+}
+
+func TestEnvOrDefault(t *testing.T) {
+	t.Setenv("MCPSERVER_TEST_VALUE", "")
+	if got := envOrDefault("MCPSERVER_TEST_VALUE", "fallback"); got != "fallback" {
+		t.Errorf("Expected fallback for empty variable, got %s", got)
+	}
+
+	t.Setenv("MCPSERVER_TEST_VALUE", "9090")
+	if got := envOrDefault("MCPSERVER_TEST_VALUE", "fallback"); got != "9090" {
+		t.Errorf("Expected 9090, got %s", got)
+	}
+}
+
+func TestParseLogLevel(t *testing.T) {
+	tests := map[string]slog.Level{
+		"debug":   slog.LevelDebug,
+		"WARN":    slog.LevelWarn,
+		"error":   slog.LevelError,
+		"verbose": slog.LevelInfo,
+	}
+	for name, expected := range tests {
+		if got := parseLogLevel(name); got != expected {
+			t.Errorf("parseLogLevel(%q): expected %v, got %v", name, expected, got)
+		}
+	}
+}
+
+func TestPrintMigrationStatus(t *testing.T) {
+	var out bytes.Buffer
+	printMigrationStatus(&out, "todos.db", &data.MigrationStatus{
+		Current:  1,
+		Latest:   3,
+		Inferred: true,
+		Pending: []data.Migration{
+			{Version: 2, Name: "add_todo_status"},
+			{Version: 3, Name: "add_todo_due_dates"},
+		},
+	})
+
+	for _, expected := range []string{"Database: todos.db", "Schema version: 1 of 3 (inferred", "0002_add_todo_status", "0003_add_todo_due_dates"} {
+		if !strings.Contains(out.String(), expected) {
+			t.Errorf("Expected output to contain %q, got:\n%s", expected, out.String())
+		}
+	}
+
+	out.Reset()
+	printMigrationStatus(&out, "todos.db", &data.MigrationStatus{Current: 3, Latest: 3})
+	if !strings.Contains(out.String(), "No pending migrations.") {
+		t.Errorf("Expected no pending migrations, got:\n%s", out.String())
+	}
+}
+
+func TestOpenStore(t *testing.T) {
+	store, err := openStore(config{store: "memory"})
+	if err != nil {
+		t.Fatalf("Failed to open memory store: %v", err)
+	}
+	if _, ok := store.(*data.MemoryStore); !ok {
+		t.Errorf("Expected a memory store, got %T", store)
+	}
+	store.Close()
+
+	if _, err := openStore(config{store: "postgres"}); err == nil {
+		t.Error("Expected an unknown store to be rejected")
+	}
+}
+
+func TestStoreLogAttrs(t *testing.T) {
+	if attrs := storeLogAttrs(config{store: "memory", dbPath: "todos.db"}); len(attrs) != 2 {
+		t.Errorf("Expected no database path for the memory store, got %v", attrs)
+	}
+	if attrs := storeLogAttrs(config{store: "sqlite", dbPath: "todos.db"}); len(attrs) != 4 || attrs[3] != "todos.db" {
+		t.Errorf("Expected the database path for the sqlite store, got %v", attrs)
+	}
+}
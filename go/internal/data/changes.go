@@ -0,0 +1,71 @@
+package data
+
+import "sync"
+
+// ChangeKind identifies how a todo was mutated
+type ChangeKind int
+
+const (
+	// TodoCreated is reported after a todo is inserted
+	TodoCreated ChangeKind = iota
+	// TodoUpdated is reported after an existing todo is modified
+	TodoUpdated
+	// TodoDeleted is reported after a todo is removed
+	TodoDeleted
+)
+
+// String returns the name of the change kind
+func (k ChangeKind) String() string {
+	switch k {
+	case TodoCreated:
+		return "created"
+	case TodoUpdated:
+		return "updated"
+	case TodoDeleted:
+		return "deleted"
+	default:
+		return "unknown"
+	}
+}
+
+// TodoChange describes a committed mutation of a single todo
+type TodoChange struct {
+	Kind ChangeKind
+	ID   int
+	// PreviousProjectID is the project the todo was in before the change. It
+	// is nil for a created todo and for a todo that was in no project.
+	PreviousProjectID *int
+	// ProjectID is the project the todo is in after the change. It is nil for
+	// a deleted todo and for a todo that is in no project.
+	ProjectID *int
+}
+
+// ChangeListener is notified after each committed todo mutation. Listeners run
+// synchronously on the mutating goroutine, so they must not block or call back
+// into the database.
+type ChangeListener func(change TodoChange)
+
+// changeListeners holds the listeners registered with a store. Stores embed
+// it to gain OnChange and call notifyChange after each committed mutation.
+type changeListeners struct {
+	mu        sync.RWMutex
+	listeners []ChangeListener
+}
+
+// OnChange registers a listener for todo mutations
+func (c *changeListeners) OnChange(listener ChangeListener) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.listeners = append(c.listeners, listener)
+}
+
+// notifyChange reports a committed mutation to every registered listener
+func (c *changeListeners) notifyChange(change TodoChange) {
+	c.mu.RLock()
+	listeners := c.listeners
+	c.mu.RUnlock()
+
+	for _, listener := range listeners {
+		listener(change)
+	}
+}
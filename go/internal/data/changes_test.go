@@ -0,0 +1,55 @@
+//go:build cgo
+
+package data
+
+import (
+	"testing"
+	"time"
+)
+
+func TestOnChange(t *testing.T) {
+	db, err := NewInMemoryDatabaseContext()
+	if err != nil {
+		t.Fatalf("Failed to create database context: %v", err)
+	}
+	defer db.Close()
+
+	var changes []TodoChange
+	db.OnChange(func(change TodoChange) {
+		changes = append(changes, change)
+	})
+
+	todo, err := db.CreateTodoAsync(t.Context(), CreateTodoInput{Description: "Watched", CreatedDate: time.Now()})
+	if err != nil {
+		t.Fatalf("Failed to create todo: %v", err)
+	}
+	description := "Changed"
+	if _, err := db.UpdateTodoAsync(t.Context(), todo.ID, UpdateTodoInput{Description: &description}); err != nil {
+		t.Fatalf("Failed to update todo: %v", err)
+	}
+	if _, err := db.DeleteTodoAsync(t.Context(), todo.ID); err != nil {
+		t.Fatalf("Failed to delete todo: %v", err)
+	}
+
+	// Calls that change nothing must not be reported
+	if _, err := db.UpdateTodoAsync(t.Context(), todo.ID, UpdateTodoInput{Description: &description}); err != nil {
+		t.Fatalf("Failed to update missing todo: %v", err)
+	}
+	if _, err := db.DeleteTodoAsync(t.Context(), todo.ID); err != nil {
+		t.Fatalf("Failed to delete missing todo: %v", err)
+	}
+
+	expected := []TodoChange{
+		{Kind: TodoCreated, ID: todo.ID},
+		{Kind: TodoUpdated, ID: todo.ID},
+		{Kind: TodoDeleted, ID: todo.ID},
+	}
+	if len(changes) != len(expected) {
+		t.Fatalf("Expected %d changes, got %v", len(expected), changes)
+	}
+	for i := range expected {
+		if changes[i] != expected[i] {
+			t.Errorf("Change %d: expected %v, got %v", i, expected[i], changes[i])
+		}
+	}
+}
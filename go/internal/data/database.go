@@ -1,19 +1,33 @@
 package data
 
 import (
+	"context"
 	"database/sql"
+	"encoding/json"
 	"fmt"
 	"strings"
+	"time"
 
 	_ "github.com/mattn/go-sqlite3"
 )
 
-// DatabaseContext handles all database operations for todos
+// DatabaseContext is the SQLite implementation of TodoStore
 type DatabaseContext struct {
+	changeListeners
+
 	db *sql.DB
 }
 
-// NewDatabaseContext creates a new database context
+// todoColumns are the columns scanned by queryTodos, in order. The last is
+// the todo's tag names as a JSON array, in alphabetical order.
+const todoColumns = `id, description, created_date, status, completed_at, due_date, due_time, due_time_zone, project_id,
+	(SELECT json_group_array(name) FROM (
+		SELECT tags.name FROM todo_tags JOIN tags ON tags.id = todo_tags.tag_id
+		WHERE todo_tags.todo_id = todos.id ORDER BY tags.name))`
+
+// NewDatabaseContext creates a new database context, applying any pending
+// schema migrations. It fails with a *SchemaTooNewError when the database was
+// migrated by a newer binary.
 func NewDatabaseContext(dbPath string) (*DatabaseContext, error) {
 	db, err := sql.Open("sqlite3", dbPath)
 	if err != nil {
@@ -24,12 +38,17 @@ func NewDatabaseContext(dbPath string) (*DatabaseContext, error) {
 		return nil, fmt.Errorf("failed to ping database: %w", err)
 	}
 
-	ctx := &DatabaseContext{db: db}
-	if err := ctx.initializeSchema(); err != nil {
-		return nil, fmt.Errorf("failed to initialize schema: %w", err)
+	// SQLite serialises writers, and every connection to ":memory:" opens a
+	// separate empty database, so concurrent callers share a single connection
+	db.SetMaxOpenConns(1)
+
+	dc := &DatabaseContext{db: db}
+	if err := dc.migrate(context.Background()); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to migrate schema: %w", err)
 	}
 
-	return ctx, nil
+	return dc, nil
 }
 
 // NewInMemoryDatabaseContext creates a new in-memory database context for testing
@@ -38,56 +57,166 @@ func NewInMemoryDatabaseContext() (*DatabaseContext, error) {
 }
 
 // Close closes the database connection
-func (ctx *DatabaseContext) Close() error {
-	if ctx.db != nil {
-		return ctx.db.Close()
+func (dc *DatabaseContext) Close() error {
+	if dc.db != nil {
+		return dc.db.Close()
 	}
 	return nil
 }
 
-// initializeSchema creates the todos table if it doesn't exist
-func (ctx *DatabaseContext) initializeSchema() error {
-	query := `
-		CREATE TABLE IF NOT EXISTS todos (
-			id INTEGER PRIMARY KEY AUTOINCREMENT,
-			description TEXT,
-			created_date DATETIME NOT NULL
-		);`
-
-	_, err := ctx.db.Exec(query)
-	return err
+// Ping verifies the database connection is still alive
+func (dc *DatabaseContext) Ping(ctx context.Context) error {
+	return dc.db.PingContext(ctx)
 }
 
 // CreateTodoAsync creates a new todo and returns it
-func (ctx *DatabaseContext) CreateTodoAsync(input CreateTodoInput) (*Todo, error) {
-	query := `INSERT INTO todos (description, created_date) VALUES (?, ?) RETURNING id`
+func (dc *DatabaseContext) CreateTodoAsync(ctx context.Context, input CreateTodoInput) (*Todo, error) {
+	query := `INSERT INTO todos (description, created_date, due_date, due_time, due_time_zone, project_id) VALUES (?, ?, ?, ?, ?, ?) RETURNING id`
 	
+	// A time or time zone without a date has nothing to qualify
+	dueTime, dueTimeZone := input.DueTime, input.DueTimeZone
+	if input.DueDate == nil {
+		dueTime, dueTimeZone = nil, nil
+	}
+
+	tx, err := dc.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create todo: %w", err)
+	}
+	defer tx.Rollback()
+
 	var id int
-	err := ctx.db.QueryRow(query, input.Description, input.CreatedDate).Scan(&id)
+	err = tx.QueryRowContext(ctx, query, input.Description, input.CreatedDate, input.DueDate, dueTime, dueTimeZone, input.ProjectID).Scan(&id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create todo: %w", err)
 	}
+	if _, err := linkTags(ctx, tx, id, input.Tags); err != nil {
+		return nil, err
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to create todo: %w", err)
+	}
+	dc.notifyChange(TodoChange{Kind: TodoCreated, ID: id, ProjectID: input.ProjectID})
 
 	return &Todo{
 		ID:          id,
 		Description: &input.Description,
 		CreatedDate: input.CreatedDate,
+		Status:      StatusOpen,
+		DueDate:     input.DueDate,
+		DueTime:     dueTime,
+		DueTimeZone: dueTimeZone,
+		Tags:        tagSet(input.Tags),
+		ProjectID:   input.ProjectID,
 	}, nil
 }
 
 // ReadTodosAsync retrieves all todos or a specific todo by ID
-func (ctx *DatabaseContext) ReadTodosAsync(id ...int) ([]Todo, error) {
+func (dc *DatabaseContext) ReadTodosAsync(ctx context.Context, id ...int) ([]Todo, error) {
 	var query string
 	var args []interface{}
 
 	if len(id) > 0 && id[0] > 0 {
-		query = `SELECT id, description, created_date FROM todos WHERE id = ? ORDER BY id`
+		query = `SELECT ` + todoColumns + ` FROM todos WHERE id = ? ORDER BY id`
 		args = []interface{}{id[0]}
 	} else {
-		query = `SELECT id, description, created_date FROM todos ORDER BY id`
+		query = `SELECT ` + todoColumns + ` FROM todos ORDER BY id`
 	}
 
-	rows, err := ctx.db.Query(query, args...)
+	return dc.queryTodos(ctx, query, args...)
+}
+
+// ListTodosAsync retrieves a page of todos selected by query, ordered by id,
+// and reports whether more todos follow the page. It uses keyset pagination on
+// the id, so pages stay stable and cheap however deep the caller reads.
+func (dc *DatabaseContext) ListTodosAsync(ctx context.Context, query TodoQuery) ([]Todo, bool, error) {
+	conditions := []string{"id > ?"}
+	args := []interface{}{query.AfterID}
+	if query.Status != "" {
+		conditions = append(conditions, "status = ?")
+		args = append(args, query.Status)
+	}
+	if query.DueOnOrBefore != "" {
+		conditions = append(conditions, "due_date <= ?")
+		args = append(args, query.DueOnOrBefore)
+	}
+	if query.ProjectID != nil {
+		conditions = append(conditions, "project_id = ?")
+		args = append(args, *query.ProjectID)
+	}
+	if tags := tagSet(query.Tags); len(tags) > 0 {
+		tagged := `SELECT todo_tags.todo_id FROM todo_tags JOIN tags ON tags.id = todo_tags.tag_id WHERE tags.name IN (` + placeholders(len(tags)) + `)`
+		for _, tag := range tags {
+			args = append(args, tag)
+		}
+		if query.MatchAllTags {
+			tagged += " GROUP BY todo_tags.todo_id HAVING COUNT(*) = ?"
+			args = append(args, len(tags))
+		}
+		conditions = append(conditions, "id IN ("+tagged+")")
+	}
+	sqlQuery := fmt.Sprintf("SELECT %s FROM todos WHERE %s ORDER BY id", todoColumns, strings.Join(conditions, " AND "))
+
+	if query.Limit <= 0 {
+		todos, err := dc.queryTodos(ctx, sqlQuery, args...)
+		return todos, false, err
+	}
+
+	// Fetch one extra row to learn whether another page follows
+	todos, err := dc.queryTodos(ctx, sqlQuery+" LIMIT ?", append(args, query.Limit+1)...)
+	if err != nil {
+		return nil, false, err
+	}
+	if len(todos) > query.Limit {
+		return todos[:query.Limit], true, nil
+	}
+	return todos, false, nil
+}
+
+// SearchTodosAsync finds todos whose id starts with term or whose description
+// contains it, ignoring ASCII case, with id matches first and each group in id
+// order. It returns at most limit todos, or every match when limit is zero or
+// less, along with the number of todos that match.
+func (dc *DatabaseContext) SearchTodosAsync(ctx context.Context, term string, limit int) ([]Todo, int, error) {
+	const where = `CAST(id AS TEXT) LIKE ? ESCAPE '\' OR description LIKE ? ESCAPE '\'`
+	escaped := escapeLike(term)
+	prefix := escaped + "%"
+	substring := "%" + escaped + "%"
+
+	var total int
+	if err := dc.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM todos WHERE `+where, prefix, substring).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count todos: %w", err)
+	}
+
+	query := `
+		SELECT ` + todoColumns + ` FROM todos
+		WHERE ` + where + `
+		ORDER BY CASE WHEN CAST(id AS TEXT) LIKE ? ESCAPE '\' THEN 0 ELSE 1 END, id
+		LIMIT ?`
+	todos, err := dc.queryTodos(ctx, query, prefix, substring, prefix, sqlLimit(limit))
+	if err != nil {
+		return nil, 0, err
+	}
+	return todos, total, nil
+}
+
+// sqlLimit converts a limit where zero or less means no limit to the LIMIT
+// value SQLite treats the same way
+func sqlLimit(limit int) int {
+	if limit <= 0 {
+		return -1
+	}
+	return limit
+}
+
+// escapeLike escapes the LIKE wildcards in a search term
+func escapeLike(term string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(term)
+}
+
+// queryTodos runs a query selecting todoColumns and scans the todos
+func (dc *DatabaseContext) queryTodos(ctx context.Context, query string, args ...interface{}) ([]Todo, error) {
+	rows, err := dc.db.QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to query todos: %w", err)
 	}
@@ -96,10 +225,14 @@ func (ctx *DatabaseContext) ReadTodosAsync(id ...int) ([]Todo, error) {
 	var todos []Todo
 	for rows.Next() {
 		var todo Todo
-		err := rows.Scan(&todo.ID, &todo.Description, &todo.CreatedDate)
+		var tags string
+		err := rows.Scan(&todo.ID, &todo.Description, &todo.CreatedDate, &todo.Status, &todo.CompletedAt, &todo.DueDate, &todo.DueTime, &todo.DueTimeZone, &todo.ProjectID, &tags)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan todo: %w", err)
 		}
+		if err := json.Unmarshal([]byte(tags), &todo.Tags); err != nil {
+			return nil, fmt.Errorf("failed to decode tags of todo %d: %w", todo.ID, err)
+		}
 		todos = append(todos, todo)
 	}
 
@@ -111,9 +244,8 @@ func (ctx *DatabaseContext) ReadTodosAsync(id ...int) ([]Todo, error) {
 }
 
 // UpdateTodoAsync updates a todo by ID
-func (ctx *DatabaseContext) UpdateTodoAsync(id int, input UpdateTodoInput) (bool, error) {
-	// First check if todo exists
-	exists, err := ctx.todoExists(id)
+func (dc *DatabaseContext) UpdateTodoAsync(ctx context.Context, id int, input UpdateTodoInput) (bool, error) {
+	projectID, exists, err := dc.todoProject(ctx, id)
 	if err != nil {
 		return false, err
 	}
@@ -135,26 +267,88 @@ func (ctx *DatabaseContext) UpdateTodoAsync(id int, input UpdateTodoInput) (bool
 		args = append(args, *input.CreatedDate)
 	}
 
-	if len(setParts) == 0 {
+	if input.ClearDue {
+		setParts = append(setParts, "due_date = NULL", "due_time = NULL", "due_time_zone = NULL")
+	} else if input.DueDate != nil {
+		setParts = append(setParts, "due_date = ?", "due_time = ?", "due_time_zone = ?")
+		args = append(args, *input.DueDate, input.DueTime, input.DueTimeZone)
+	}
+
+	if len(setParts) == 0 && input.Tags == nil {
 		// Nothing to update, but todo exists
 		return true, nil
 	}
 
-	query := fmt.Sprintf("UPDATE todos SET %s WHERE id = ?", strings.Join(setParts, ", "))
-	args = append(args, id)
-
-	_, err = ctx.db.Exec(query, args...)
+	tx, err := dc.db.BeginTx(ctx, nil)
 	if err != nil {
 		return false, fmt.Errorf("failed to update todo: %w", err)
 	}
+	defer tx.Rollback()
+
+	if len(setParts) > 0 {
+		query := fmt.Sprintf("UPDATE todos SET %s WHERE id = ?", strings.Join(setParts, ", "))
+		args = append(args, id)
+		if _, err := tx.ExecContext(ctx, query, args...); err != nil {
+			return false, fmt.Errorf("failed to update todo: %w", err)
+		}
+	}
+	if input.Tags != nil {
+		if _, err := tx.ExecContext(ctx, `DELETE FROM todo_tags WHERE todo_id = ?`, id); err != nil {
+			return false, fmt.Errorf("failed to replace tags: %w", err)
+		}
+		if _, err := linkTags(ctx, tx, id, input.Tags); err != nil {
+			return false, err
+		}
+		if err := pruneTags(ctx, tx); err != nil {
+			return false, err
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to update todo: %w", err)
+	}
+	dc.notifyChange(TodoChange{Kind: TodoUpdated, ID: id, PreviousProjectID: projectID, ProjectID: projectID})
+
+	return true, nil
+}
+
+// CompleteTodoAsync marks a todo as done at completedAt. Completing a todo
+// that is already done keeps its original completion time. It reports false
+// when no todo has the ID.
+func (dc *DatabaseContext) CompleteTodoAsync(ctx context.Context, id int, completedAt time.Time) (bool, error) {
+	return dc.setTodoStatus(ctx, id, StatusDone, &completedAt)
+}
+
+// ReopenTodoAsync marks a todo as open again and clears its completion time.
+// It reports false when no todo has the ID.
+func (dc *DatabaseContext) ReopenTodoAsync(ctx context.Context, id int) (bool, error) {
+	return dc.setTodoStatus(ctx, id, StatusOpen, nil)
+}
+
+// setTodoStatus moves a todo to status, leaving todos already in that status untouched
+func (dc *DatabaseContext) setTodoStatus(ctx context.Context, id int, status TodoStatus, completedAt *time.Time) (bool, error) {
+	projectID, exists, err := dc.todoProject(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	if !exists {
+		return false, nil
+	}
+
+	query := `UPDATE todos SET status = ?, completed_at = ? WHERE id = ? AND status <> ?`
+	result, err := dc.db.ExecContext(ctx, query, status, completedAt, id, status)
+	if err != nil {
+		return false, fmt.Errorf("failed to set todo status: %w", err)
+	}
+	if changed, err := result.RowsAffected(); err == nil && changed > 0 {
+		dc.notifyChange(TodoChange{Kind: TodoUpdated, ID: id, PreviousProjectID: projectID, ProjectID: projectID})
+	}
 
 	return true, nil
 }
 
 // DeleteTodoAsync deletes a todo by ID
-func (ctx *DatabaseContext) DeleteTodoAsync(id int) (bool, error) {
-	// First check if todo exists
-	exists, err := ctx.todoExists(id)
+func (dc *DatabaseContext) DeleteTodoAsync(ctx context.Context, id int) (bool, error) {
+	projectID, exists, err := dc.todoProject(ctx, id)
 	if err != nil {
 		return false, err
 	}
@@ -162,25 +356,304 @@ func (ctx *DatabaseContext) DeleteTodoAsync(id int) (bool, error) {
 		return false, nil
 	}
 
-	query := `DELETE FROM todos WHERE id = ?`
-	_, err = ctx.db.Exec(query, id)
+	tx, err := dc.db.BeginTx(ctx, nil)
 	if err != nil {
 		return false, fmt.Errorf("failed to delete todo: %w", err)
 	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, `DELETE FROM todo_tags WHERE todo_id = ?`, id); err != nil {
+		return false, fmt.Errorf("failed to delete todo tags: %w", err)
+	}
+	if _, err := tx.ExecContext(ctx, `DELETE FROM todos WHERE id = ?`, id); err != nil {
+		return false, fmt.Errorf("failed to delete todo: %w", err)
+	}
+	if err := pruneTags(ctx, tx); err != nil {
+		return false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to delete todo: %w", err)
+	}
+	dc.notifyChange(TodoChange{Kind: TodoDeleted, ID: id, PreviousProjectID: projectID})
 
 	return true, nil
 }
 
-// todoExists checks if a todo with the given ID exists
-func (ctx *DatabaseContext) todoExists(id int) (bool, error) {
-	query := `SELECT 1 FROM todos WHERE id = ? LIMIT 1`
-	var exists int
-	err := ctx.db.QueryRow(query, id).Scan(&exists)
-	if err == sql.ErrNoRows {
+// MoveTodoAsync moves a todo into a project, or out of every project when
+// projectID is nil. It reports false when no todo has the ID.
+func (dc *DatabaseContext) MoveTodoAsync(ctx context.Context, id int, projectID *int) (bool, error) {
+	previousProjectID, exists, err := dc.todoProject(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	if !exists {
 		return false, nil
 	}
+
+	query := `UPDATE todos SET project_id = ? WHERE id = ? AND project_id IS NOT ?`
+	result, err := dc.db.ExecContext(ctx, query, projectID, id, projectID)
 	if err != nil {
-		return false, fmt.Errorf("failed to check todo existence: %w", err)
+		return false, fmt.Errorf("failed to move todo: %w", err)
+	}
+	if changed, err := result.RowsAffected(); err == nil && changed > 0 {
+		dc.notifyChange(TodoChange{Kind: TodoUpdated, ID: id, PreviousProjectID: previousProjectID, ProjectID: projectID})
 	}
+
 	return true, nil
+}
+
+// AddTagsAsync adds tags to a todo, creating tags that do not exist yet. Tags
+// the todo already has are left alone. It reports false when no todo has the ID.
+func (dc *DatabaseContext) AddTagsAsync(ctx context.Context, id int, tags []string) (bool, error) {
+	projectID, exists, err := dc.todoProject(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	if !exists {
+		return false, nil
+	}
+
+	tx, err := dc.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to add tags: %w", err)
+	}
+	defer tx.Rollback()
+
+	added, err := linkTags(ctx, tx, id, tags)
+	if err != nil {
+		return false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to add tags: %w", err)
+	}
+	if added > 0 {
+		dc.notifyChange(TodoChange{Kind: TodoUpdated, ID: id, PreviousProjectID: projectID, ProjectID: projectID})
+	}
+
+	return true, nil
+}
+
+// RemoveTagsAsync removes tags from a todo, ignoring tags it does not have. It
+// reports false when no todo has the ID.
+func (dc *DatabaseContext) RemoveTagsAsync(ctx context.Context, id int, tags []string) (bool, error) {
+	projectID, exists, err := dc.todoProject(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	if !exists {
+		return false, nil
+	}
+	tags = tagSet(tags)
+	if len(tags) == 0 {
+		return true, nil
+	}
+
+	tx, err := dc.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, fmt.Errorf("failed to remove tags: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `DELETE FROM todo_tags WHERE todo_id = ? AND tag_id IN (SELECT id FROM tags WHERE name IN (` + placeholders(len(tags)) + `))`
+	args := []interface{}{id}
+	for _, tag := range tags {
+		args = append(args, tag)
+	}
+	result, err := tx.ExecContext(ctx, query, args...)
+	if err != nil {
+		return false, fmt.Errorf("failed to remove tags: %w", err)
+	}
+	if err := pruneTags(ctx, tx); err != nil {
+		return false, err
+	}
+	if err := tx.Commit(); err != nil {
+		return false, fmt.Errorf("failed to remove tags: %w", err)
+	}
+	if removed, err := result.RowsAffected(); err == nil && removed > 0 {
+		dc.notifyChange(TodoChange{Kind: TodoUpdated, ID: id, PreviousProjectID: projectID, ProjectID: projectID})
+	}
+
+	return true, nil
+}
+
+// ListTagsAsync returns every tag in use with the number of todos that carry
+// it, in alphabetical order
+func (dc *DatabaseContext) ListTagsAsync(ctx context.Context) ([]TagCount, error) {
+	query := `
+		SELECT tags.name, COUNT(*) FROM tags
+		JOIN todo_tags ON todo_tags.tag_id = tags.id
+		GROUP BY tags.id ORDER BY tags.name`
+
+	rows, err := dc.db.QueryContext(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query tags: %w", err)
+	}
+	defer rows.Close()
+
+	var tags []TagCount
+	for rows.Next() {
+		var tag TagCount
+		if err := rows.Scan(&tag.Name, &tag.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan tag: %w", err)
+		}
+		tags = append(tags, tag)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating tags: %w", err)
+	}
+
+	return tags, nil
+}
+
+// CreateProjectAsync creates a new project and returns it. It returns
+// ErrProjectNameTaken when another project has the name, ignoring ASCII case.
+func (dc *DatabaseContext) CreateProjectAsync(ctx context.Context, input CreateProjectInput) (*Project, error) {
+	tx, err := dc.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create project: %w", err)
+	}
+	defer tx.Rollback()
+
+	// The name column compares without case, so this also finds "Work" for "work"
+	var taken int
+	if err := tx.QueryRowContext(ctx, `SELECT COUNT(*) FROM projects WHERE name = ?`, input.Name).Scan(&taken); err != nil {
+		return nil, fmt.Errorf("failed to check project name: %w", err)
+	}
+	if taken > 0 {
+		return nil, ErrProjectNameTaken
+	}
+
+	var id int
+	query := `INSERT INTO projects (name, created_date) VALUES (?, ?) RETURNING id`
+	if err := tx.QueryRowContext(ctx, query, input.Name, input.CreatedDate).Scan(&id); err != nil {
+		return nil, fmt.Errorf("failed to create project: %w", err)
+	}
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to create project: %w", err)
+	}
+
+	return &Project{ID: id, Name: input.Name, CreatedDate: input.CreatedDate}, nil
+}
+
+// ReadProjectsAsync retrieves all projects, archived or not, or a specific
+// project if an ID is provided
+func (dc *DatabaseContext) ReadProjectsAsync(ctx context.Context, id ...int) ([]Project, error) {
+	if len(id) > 0 && id[0] > 0 {
+		return dc.queryProjects(ctx, `SELECT id, name, created_date, archived_at FROM projects WHERE id = ? ORDER BY id`, id[0])
+	}
+	return dc.queryProjects(ctx, `SELECT id, name, created_date, archived_at FROM projects ORDER BY id`)
+}
+
+// SearchProjectsAsync finds projects that are not archived whose id starts
+// with term or whose name contains it, ignoring ASCII case, with id matches
+// first and each group in id order. It returns at most limit projects, or
+// every match when limit is zero or less, along with the number that match.
+func (dc *DatabaseContext) SearchProjectsAsync(ctx context.Context, term string, limit int) ([]Project, int, error) {
+	const where = `archived_at IS NULL AND (CAST(id AS TEXT) LIKE ? ESCAPE '\' OR name LIKE ? ESCAPE '\')`
+	escaped := escapeLike(term)
+	prefix := escaped + "%"
+	substring := "%" + escaped + "%"
+
+	var total int
+	if err := dc.db.QueryRowContext(ctx, `SELECT COUNT(*) FROM projects WHERE `+where, prefix, substring).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count projects: %w", err)
+	}
+
+	query := `
+		SELECT id, name, created_date, archived_at FROM projects
+		WHERE ` + where + `
+		ORDER BY CASE WHEN CAST(id AS TEXT) LIKE ? ESCAPE '\' THEN 0 ELSE 1 END, id
+		LIMIT ?`
+	projects, err := dc.queryProjects(ctx, query, prefix, substring, prefix, sqlLimit(limit))
+	if err != nil {
+		return nil, 0, err
+	}
+	return projects, total, nil
+}
+
+// queryProjects runs a query selecting project columns and scans the projects
+func (dc *DatabaseContext) queryProjects(ctx context.Context, query string, args ...interface{}) ([]Project, error) {
+	rows, err := dc.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query projects: %w", err)
+	}
+	defer rows.Close()
+
+	var projects []Project
+	for rows.Next() {
+		var project Project
+		if err := rows.Scan(&project.ID, &project.Name, &project.CreatedDate, &project.ArchivedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan project: %w", err)
+		}
+		projects = append(projects, project)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating projects: %w", err)
+	}
+
+	return projects, nil
+}
+
+// ArchiveProjectAsync archives a project at archivedAt. Archiving a project
+// that is already archived keeps its original archive time. It reports false
+// when no project has the ID.
+func (dc *DatabaseContext) ArchiveProjectAsync(ctx context.Context, id int, archivedAt time.Time) (bool, error) {
+	result, err := dc.db.ExecContext(ctx, `UPDATE projects SET archived_at = COALESCE(archived_at, ?) WHERE id = ?`, archivedAt, id)
+	if err != nil {
+		return false, fmt.Errorf("failed to archive project: %w", err)
+	}
+	changed, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to archive project: %w", err)
+	}
+	return changed > 0, nil
+}
+
+// linkTags attaches tags to a todo, creating tags that do not exist yet, and
+// returns how many the todo did not already have
+func linkTags(ctx context.Context, tx *sql.Tx, id int, tags []string) (int64, error) {
+	var linked int64
+	for _, tag := range tagSet(tags) {
+		if _, err := tx.ExecContext(ctx, `INSERT OR IGNORE INTO tags (name) VALUES (?)`, tag); err != nil {
+			return 0, fmt.Errorf("failed to create tag %q: %w", tag, err)
+		}
+		result, err := tx.ExecContext(ctx, `INSERT OR IGNORE INTO todo_tags (todo_id, tag_id) SELECT ?, id FROM tags WHERE name = ?`, id, tag)
+		if err != nil {
+			return 0, fmt.Errorf("failed to tag todo with %q: %w", tag, err)
+		}
+		if added, err := result.RowsAffected(); err == nil {
+			linked += added
+		}
+	}
+	return linked, nil
+}
+
+// pruneTags deletes the tags no todo carries any more
+func pruneTags(ctx context.Context, tx *sql.Tx) error {
+	if _, err := tx.ExecContext(ctx, `DELETE FROM tags WHERE id NOT IN (SELECT tag_id FROM todo_tags)`); err != nil {
+		return fmt.Errorf("failed to prune tags: %w", err)
+	}
+	return nil
+}
+
+// placeholders returns n comma-separated query placeholders
+func placeholders(n int) string {
+	return strings.TrimSuffix(strings.Repeat("?, ", n), ", ")
+}
+
+// todoProject reports whether a todo with the given ID exists and, if so, the
+// project it is in
+func (dc *DatabaseContext) todoProject(ctx context.Context, id int) (*int, bool, error) {
+	query := `SELECT project_id FROM todos WHERE id = ?`
+	var projectID *int
+	err := dc.db.QueryRowContext(ctx, query, id).Scan(&projectID)
+	if err == sql.ErrNoRows {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to check todo existence: %w", err)
+	}
+	return projectID, true, nil
 }
\ No newline at end of file
@@ -1,6 +1,13 @@
+//go:build cgo
+
 package data
 
 import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"path/filepath"
 	"testing"
 	"time"
 )
@@ -17,7 +24,7 @@ func TestCreateTodoAsync(t *testing.T) {
 		CreatedDate: time.Now(),
 	}
 
-	todo, err := db.CreateTodoAsync(input)
+	todo, err := db.CreateTodoAsync(t.Context(), input)
 	if err != nil {
 		t.Fatalf("Failed to create todo: %v", err)
 	}
@@ -41,16 +48,16 @@ func TestReadTodosAsync_All(t *testing.T) {
 	defer db.Close()
 
 	// Create test todos
-	todo1, _ := db.CreateTodoAsync(CreateTodoInput{
+	todo1, _ := db.CreateTodoAsync(t.Context(), CreateTodoInput{
 		Description: "Todo 1",
 		CreatedDate: time.Now(),
 	})
-	todo2, _ := db.CreateTodoAsync(CreateTodoInput{
+	todo2, _ := db.CreateTodoAsync(t.Context(), CreateTodoInput{
 		Description: "Todo 2",
 		CreatedDate: time.Now().Add(time.Hour),
 	})
 
-	todos, err := db.ReadTodosAsync()
+	todos, err := db.ReadTodosAsync(t.Context())
 	if err != nil {
 		t.Fatalf("Failed to read todos: %v", err)
 	}
@@ -76,12 +83,12 @@ func TestReadTodosAsync_ById(t *testing.T) {
 	defer db.Close()
 
 	// Create test todo
-	todo, _ := db.CreateTodoAsync(CreateTodoInput{
+	todo, _ := db.CreateTodoAsync(t.Context(), CreateTodoInput{
 		Description: "Test todo",
 		CreatedDate: time.Now(),
 	})
 
-	todos, err := db.ReadTodosAsync(todo.ID)
+	todos, err := db.ReadTodosAsync(t.Context(), todo.ID)
 	if err != nil {
 		t.Fatalf("Failed to read todo by ID: %v", err)
 	}
@@ -101,7 +108,7 @@ func TestReadTodosAsync_NonExistent(t *testing.T) {
 	}
 	defer db.Close()
 
-	todos, err := db.ReadTodosAsync(999)
+	todos, err := db.ReadTodosAsync(t.Context(), 999)
 	if err != nil {
 		t.Fatalf("Failed to read non-existent todo: %v", err)
 	}
@@ -119,13 +126,13 @@ func TestUpdateTodoAsync_Description(t *testing.T) {
 	defer db.Close()
 
 	// Create test todo
-	todo, _ := db.CreateTodoAsync(CreateTodoInput{
+	todo, _ := db.CreateTodoAsync(t.Context(), CreateTodoInput{
 		Description: "Original description",
 		CreatedDate: time.Now(),
 	})
 
 	newDescription := "Updated description"
-	updated, err := db.UpdateTodoAsync(todo.ID, UpdateTodoInput{
+	updated, err := db.UpdateTodoAsync(t.Context(), todo.ID, UpdateTodoInput{
 		Description: &newDescription,
 	})
 	if err != nil {
@@ -136,7 +143,7 @@ func TestUpdateTodoAsync_Description(t *testing.T) {
 	}
 
 	// Verify update
-	todos, _ := db.ReadTodosAsync(todo.ID)
+	todos, _ := db.ReadTodosAsync(t.Context(), todo.ID)
 	if len(todos) != 1 {
 		t.Fatalf("Expected 1 todo after update, got %d", len(todos))
 	}
@@ -153,13 +160,13 @@ func TestUpdateTodoAsync_CreatedDate(t *testing.T) {
 	defer db.Close()
 
 	originalDate := time.Now()
-	todo, _ := db.CreateTodoAsync(CreateTodoInput{
+	todo, _ := db.CreateTodoAsync(t.Context(), CreateTodoInput{
 		Description: "Test todo",
 		CreatedDate: originalDate,
 	})
 
 	newDate := originalDate.Add(24 * time.Hour)
-	updated, err := db.UpdateTodoAsync(todo.ID, UpdateTodoInput{
+	updated, err := db.UpdateTodoAsync(t.Context(), todo.ID, UpdateTodoInput{
 		CreatedDate: &newDate,
 	})
 	if err != nil {
@@ -170,7 +177,7 @@ func TestUpdateTodoAsync_CreatedDate(t *testing.T) {
 	}
 
 	// Verify update
-	todos, _ := db.ReadTodosAsync(todo.ID)
+	todos, _ := db.ReadTodosAsync(t.Context(), todo.ID)
 	if len(todos) != 1 {
 		t.Fatalf("Expected 1 todo after update, got %d", len(todos))
 	}
@@ -187,13 +194,13 @@ func TestUpdateTodoAsync_EmptyDescription(t *testing.T) {
 	defer db.Close()
 
 	originalDescription := "Original description"
-	todo, _ := db.CreateTodoAsync(CreateTodoInput{
+	todo, _ := db.CreateTodoAsync(t.Context(), CreateTodoInput{
 		Description: originalDescription,
 		CreatedDate: time.Now(),
 	})
 
 	emptyDescription := ""
-	updated, err := db.UpdateTodoAsync(todo.ID, UpdateTodoInput{
+	updated, err := db.UpdateTodoAsync(t.Context(), todo.ID, UpdateTodoInput{
 		Description: &emptyDescription,
 	})
 	if err != nil {
@@ -204,7 +211,7 @@ func TestUpdateTodoAsync_EmptyDescription(t *testing.T) {
 	}
 
 	// Verify description wasn't changed (empty string should be ignored)
-	todos, _ := db.ReadTodosAsync(todo.ID)
+	todos, _ := db.ReadTodosAsync(t.Context(), todo.ID)
 	if len(todos) != 1 {
 		t.Fatalf("Expected 1 todo after update, got %d", len(todos))
 	}
@@ -221,7 +228,7 @@ func TestUpdateTodoAsync_NonExistent(t *testing.T) {
 	defer db.Close()
 
 	newDescription := "New description"
-	updated, err := db.UpdateTodoAsync(999, UpdateTodoInput{
+	updated, err := db.UpdateTodoAsync(t.Context(), 999, UpdateTodoInput{
 		Description: &newDescription,
 	})
 	if err != nil {
@@ -240,12 +247,12 @@ func TestDeleteTodoAsync(t *testing.T) {
 	defer db.Close()
 
 	// Create test todo
-	todo, _ := db.CreateTodoAsync(CreateTodoInput{
+	todo, _ := db.CreateTodoAsync(t.Context(), CreateTodoInput{
 		Description: "Test todo",
 		CreatedDate: time.Now(),
 	})
 
-	deleted, err := db.DeleteTodoAsync(todo.ID)
+	deleted, err := db.DeleteTodoAsync(t.Context(), todo.ID)
 	if err != nil {
 		t.Fatalf("Failed to delete todo: %v", err)
 	}
@@ -254,7 +261,7 @@ func TestDeleteTodoAsync(t *testing.T) {
 	}
 
 	// Verify deletion
-	todos, _ := db.ReadTodosAsync(todo.ID)
+	todos, _ := db.ReadTodosAsync(t.Context(), todo.ID)
 	if len(todos) != 0 {
 		t.Errorf("Expected 0 todos after deletion, got %d", len(todos))
 	}
@@ -267,11 +274,308 @@ func TestDeleteTodoAsync_NonExistent(t *testing.T) {
 	}
 	defer db.Close()
 
-	deleted, err := db.DeleteTodoAsync(999)
+	deleted, err := db.DeleteTodoAsync(t.Context(), 999)
 	if err != nil {
 		t.Fatalf("Failed to delete non-existent todo: %v", err)
 	}
 	if deleted {
 		t.Error("Expected todo not to be deleted (non-existent)")
 	}
-}
\ No newline at end of file
+}
+func TestSearchTodosAsync(t *testing.T) {
+	db, err := NewInMemoryDatabaseContext()
+	if err != nil {
+		t.Fatalf("Failed to create in-memory database: %v", err)
+	}
+	defer db.Close()
+
+	// Ids 1..12; todo 3 mentions "1" in its description and todo 4 contains a LIKE wildcard
+	for i := 1; i <= 12; i++ {
+		description := fmt.Sprintf("Task %c", 'a'+i-1)
+		switch i {
+		case 3:
+			description = "Call 1st customer"
+		case 4:
+			description = "Reach 90% coverage"
+		}
+		if _, err := db.CreateTodoAsync(t.Context(), CreateTodoInput{Description: description, CreatedDate: time.Now()}); err != nil {
+			t.Fatalf("Failed to create todo: %v", err)
+		}
+	}
+
+	tests := []struct {
+		term     string
+		expected []int
+	}{
+		{"1", []int{1, 10, 11, 12, 3}},
+		{"CALL", []int{3}},
+		{"%", []int{4}},
+		{"missing", nil},
+	}
+
+	for _, tt := range tests {
+		todos, _, err := db.SearchTodosAsync(t.Context(), tt.term, 0)
+		if err != nil {
+			t.Fatalf("Failed to search todos: %v", err)
+		}
+		var ids []int
+		for _, todo := range todos {
+			ids = append(ids, todo.ID)
+		}
+		if fmt.Sprint(ids) != fmt.Sprint(tt.expected) {
+			t.Errorf("Search %q: expected %v, got %v", tt.term, tt.expected, ids)
+		}
+	}
+}
+
+func TestListTodosAsync_Pages(t *testing.T) {
+	db, err := NewInMemoryDatabaseContext()
+	if err != nil {
+		t.Fatalf("Failed to create in-memory database: %v", err)
+	}
+	defer db.Close()
+
+	for i := 1; i <= 5; i++ {
+		if _, err := db.CreateTodoAsync(t.Context(), CreateTodoInput{Description: fmt.Sprintf("Todo %d", i), CreatedDate: time.Now()}); err != nil {
+			t.Fatalf("Failed to create todo: %v", err)
+		}
+	}
+	// A gap in the ids must not shift later pages
+	if _, err := db.DeleteTodoAsync(t.Context(), 3); err != nil {
+		t.Fatalf("Failed to delete todo: %v", err)
+	}
+
+	tests := []struct {
+		query    TodoQuery
+		expected []int
+		more     bool
+	}{
+		{TodoQuery{Limit: 2}, []int{1, 2}, true},
+		{TodoQuery{AfterID: 2, Limit: 2}, []int{4, 5}, false},
+		{TodoQuery{AfterID: 5, Limit: 2}, nil, false},
+		{TodoQuery{AfterID: 1}, []int{2, 4, 5}, false},
+	}
+
+	for _, tt := range tests {
+		todos, more, err := db.ListTodosAsync(t.Context(), tt.query)
+		if err != nil {
+			t.Fatalf("Failed to list todos: %v", err)
+		}
+		var ids []int
+		for _, todo := range todos {
+			ids = append(ids, todo.ID)
+		}
+		if fmt.Sprint(ids) != fmt.Sprint(tt.expected) || more != tt.more {
+			t.Errorf("Query %+v: expected %v (more %v), got %v (more %v)", tt.query, tt.expected, tt.more, ids, more)
+		}
+	}
+}
+
+func TestCompleteAndReopenTodoAsync(t *testing.T) {
+	db, err := NewInMemoryDatabaseContext()
+	if err != nil {
+		t.Fatalf("Failed to create in-memory database: %v", err)
+	}
+	defer db.Close()
+
+	created, err := db.CreateTodoAsync(t.Context(), CreateTodoInput{Description: "Finish report", CreatedDate: time.Now()})
+	if err != nil {
+		t.Fatalf("Failed to create todo: %v", err)
+	}
+	if created.Status != StatusOpen || created.CompletedAt != nil {
+		t.Errorf("Expected new todo to be open, got %s completed at %v", created.Status, created.CompletedAt)
+	}
+
+	completedAt := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	if ok, err := db.CompleteTodoAsync(t.Context(), created.ID, completedAt); err != nil || !ok {
+		t.Fatalf("Failed to complete todo: %v, %v", ok, err)
+	}
+	// Completing again must keep the original completion time
+	if ok, err := db.CompleteTodoAsync(t.Context(), created.ID, completedAt.Add(time.Hour)); err != nil || !ok {
+		t.Fatalf("Failed to complete todo again: %v, %v", ok, err)
+	}
+
+	todos, err := db.ReadTodosAsync(t.Context(), created.ID)
+	if err != nil {
+		t.Fatalf("Failed to read todo: %v", err)
+	}
+	if todos[0].Status != StatusDone || todos[0].CompletedAt == nil || !todos[0].CompletedAt.Equal(completedAt) {
+		t.Errorf("Expected todo done at %v, got %s at %v", completedAt, todos[0].Status, todos[0].CompletedAt)
+	}
+
+	if ok, err := db.ReopenTodoAsync(t.Context(), created.ID); err != nil || !ok {
+		t.Fatalf("Failed to reopen todo: %v, %v", ok, err)
+	}
+	todos, err = db.ReadTodosAsync(t.Context(), created.ID)
+	if err != nil {
+		t.Fatalf("Failed to read todo: %v", err)
+	}
+	if todos[0].Status != StatusOpen || todos[0].CompletedAt != nil {
+		t.Errorf("Expected reopened todo to be open, got %s completed at %v", todos[0].Status, todos[0].CompletedAt)
+	}
+
+	if ok, err := db.CompleteTodoAsync(t.Context(), 999, completedAt); err != nil || ok {
+		t.Errorf("Expected completing a missing todo to report false, got %v, %v", ok, err)
+	}
+	if ok, err := db.ReopenTodoAsync(t.Context(), 999); err != nil || ok {
+		t.Errorf("Expected reopening a missing todo to report false, got %v, %v", ok, err)
+	}
+}
+
+func TestListTodosAsync_Status(t *testing.T) {
+	db, err := NewInMemoryDatabaseContext()
+	if err != nil {
+		t.Fatalf("Failed to create in-memory database: %v", err)
+	}
+	defer db.Close()
+
+	for i := 1; i <= 4; i++ {
+		if _, err := db.CreateTodoAsync(t.Context(), CreateTodoInput{Description: fmt.Sprintf("Todo %d", i), CreatedDate: time.Now()}); err != nil {
+			t.Fatalf("Failed to create todo: %v", err)
+		}
+	}
+	for _, id := range []int{2, 3} {
+		if _, err := db.CompleteTodoAsync(t.Context(), id, time.Now()); err != nil {
+			t.Fatalf("Failed to complete todo: %v", err)
+		}
+	}
+
+	tests := []struct {
+		query    TodoQuery
+		expected []int
+		more     bool
+	}{
+		{TodoQuery{Status: StatusOpen}, []int{1, 4}, false},
+		{TodoQuery{Status: StatusDone, Limit: 1}, []int{2}, true},
+		{TodoQuery{Status: StatusDone, AfterID: 2, Limit: 1}, []int{3}, false},
+		{TodoQuery{}, []int{1, 2, 3, 4}, false},
+	}
+
+	for _, tt := range tests {
+		todos, more, err := db.ListTodosAsync(t.Context(), tt.query)
+		if err != nil {
+			t.Fatalf("Failed to list todos: %v", err)
+		}
+		var ids []int
+		for _, todo := range todos {
+			ids = append(ids, todo.ID)
+		}
+		if fmt.Sprint(ids) != fmt.Sprint(tt.expected) || more != tt.more {
+			t.Errorf("Query %+v: expected %v (more %v), got %v (more %v)", tt.query, tt.expected, tt.more, ids, more)
+		}
+	}
+}
+
+func TestDueDate_CreateUpdateAndFilter(t *testing.T) {
+	db, err := NewInMemoryDatabaseContext()
+	if err != nil {
+		t.Fatalf("Failed to create in-memory database: %v", err)
+	}
+	defer db.Close()
+
+	date, clock, zone := "2024-03-01", "09:30", "Europe/London"
+	created, err := db.CreateTodoAsync(t.Context(), CreateTodoInput{Description: "Dentist", CreatedDate: time.Now(), DueDate: &date, DueTime: &clock, DueTimeZone: &zone})
+	if err != nil {
+		t.Fatalf("Failed to create todo: %v", err)
+	}
+	// A time without a date is dropped
+	undated, err := db.CreateTodoAsync(t.Context(), CreateTodoInput{Description: "Someday", CreatedDate: time.Now(), DueTime: &clock})
+	if err != nil {
+		t.Fatalf("Failed to create todo: %v", err)
+	}
+	if undated.DueTime != nil {
+		t.Errorf("Expected due time without a date to be dropped, got %v", *undated.DueTime)
+	}
+
+	todos, err := db.ReadTodosAsync(t.Context(), created.ID)
+	if err != nil {
+		t.Fatalf("Failed to read todo: %v", err)
+	}
+	if todos[0].DueDate == nil || *todos[0].DueDate != date || *todos[0].DueTime != clock || *todos[0].DueTimeZone != zone {
+		t.Errorf("Expected due %s %s %s, got %+v", date, clock, zone, todos[0])
+	}
+
+	// Setting a new date replaces the time and time zone
+	later := "2024-03-05"
+	if _, err := db.UpdateTodoAsync(t.Context(), created.ID, UpdateTodoInput{DueDate: &later}); err != nil {
+		t.Fatalf("Failed to update todo: %v", err)
+	}
+	todos, _ = db.ReadTodosAsync(t.Context(), created.ID)
+	if *todos[0].DueDate != later || todos[0].DueTime != nil || todos[0].DueTimeZone != nil {
+		t.Errorf("Expected due date %s with no time or zone, got %+v", later, todos[0])
+	}
+
+	for _, tt := range []struct {
+		before   string
+		expected []int
+	}{
+		{"2024-03-04", nil},
+		{"2024-03-05", []int{created.ID}},
+	} {
+		listed, _, err := db.ListTodosAsync(t.Context(), TodoQuery{DueOnOrBefore: tt.before})
+		if err != nil {
+			t.Fatalf("Failed to list todos: %v", err)
+		}
+		var ids []int
+		for _, todo := range listed {
+			ids = append(ids, todo.ID)
+		}
+		if fmt.Sprint(ids) != fmt.Sprint(tt.expected) {
+			t.Errorf("Due on or before %s: expected %v, got %v", tt.before, tt.expected, ids)
+		}
+	}
+
+	if _, err := db.UpdateTodoAsync(t.Context(), created.ID, UpdateTodoInput{ClearDue: true}); err != nil {
+		t.Fatalf("Failed to update todo: %v", err)
+	}
+	todos, _ = db.ReadTodosAsync(t.Context(), created.ID)
+	if todos[0].DueDate != nil {
+		t.Errorf("Expected due date to be cleared, got %v", *todos[0].DueDate)
+	}
+}
+
+func TestNewDatabaseContext_UpgradesExistingSchema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todos.db")
+
+	// A database created before todos had a status
+	legacy, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	_, err = legacy.Exec(`
+		CREATE TABLE todos (id INTEGER PRIMARY KEY AUTOINCREMENT, description TEXT, created_date DATETIME NOT NULL);
+		INSERT INTO todos (description, created_date) VALUES ('Old todo', '2024-01-01 10:00:00+00:00');`)
+	legacy.Close()
+	if err != nil {
+		t.Fatalf("Failed to create legacy schema: %v", err)
+	}
+
+	db, err := NewDatabaseContext(path)
+	if err != nil {
+		t.Fatalf("Failed to open legacy database: %v", err)
+	}
+	defer db.Close()
+
+	todos, err := db.ReadTodosAsync(t.Context())
+	if err != nil {
+		t.Fatalf("Failed to read todos: %v", err)
+	}
+	if len(todos) != 1 || todos[0].Status != StatusOpen || todos[0].CompletedAt != nil {
+		t.Errorf("Expected the existing todo to be open, got %+v", todos)
+	}
+}
+
+func TestReadTodosAsync_CancelledContext(t *testing.T) {
+	db, err := NewInMemoryDatabaseContext()
+	if err != nil {
+		t.Fatalf("Failed to create in-memory database: %v", err)
+	}
+	defer db.Close()
+
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	if _, err := db.ReadTodosAsync(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,453 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// errStoreClosed is returned by a MemoryStore after Close
+var errStoreClosed = errors.New("store is closed")
+
+// MemoryStore is a TodoStore that keeps todos in process memory. It needs no
+// SQLite driver, so it suits tests and short-lived servers; its todos are lost
+// when the process exits.
+type MemoryStore struct {
+	changeListeners
+
+	mu            sync.RWMutex
+	todos         map[int]Todo
+	lastID        int
+	projects      map[int]Project
+	lastProjectID int
+	closed        bool
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{todos: make(map[int]Todo), projects: make(map[int]Project)}
+}
+
+// Close discards the stored todos; later calls fail
+func (m *MemoryStore) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.closed = true
+	m.todos = nil
+	m.projects = nil
+	return nil
+}
+
+// Ping reports whether the store is still open
+func (m *MemoryStore) Ping(ctx context.Context) error {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.check(ctx)
+}
+
+// check returns the error a call should fail with, if any. Callers hold mu.
+func (m *MemoryStore) check(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if m.closed {
+		return errStoreClosed
+	}
+	return nil
+}
+
+// CreateTodoAsync creates a new todo and returns it
+func (m *MemoryStore) CreateTodoAsync(ctx context.Context, input CreateTodoInput) (*Todo, error) {
+	m.mu.Lock()
+	if err := m.check(ctx); err != nil {
+		m.mu.Unlock()
+		return nil, err
+	}
+
+	todo := Todo{
+		Description: &input.Description,
+		CreatedDate: input.CreatedDate,
+		Status:      StatusOpen,
+		Tags:        tagSet(input.Tags),
+		ProjectID:   input.ProjectID,
+	}
+	// A time or time zone without a date has nothing to qualify
+	if input.DueDate != nil {
+		todo.DueDate, todo.DueTime, todo.DueTimeZone = input.DueDate, input.DueTime, input.DueTimeZone
+	}
+	m.lastID++
+	todo.ID = m.lastID
+	m.todos[todo.ID] = cloneTodo(todo)
+	m.mu.Unlock()
+
+	m.notifyChange(TodoChange{Kind: TodoCreated, ID: todo.ID, ProjectID: clonePointer(todo.ProjectID)})
+	created := cloneTodo(todo)
+	return &created, nil
+}
+
+// ReadTodosAsync retrieves all todos or a specific todo by ID
+func (m *MemoryStore) ReadTodosAsync(ctx context.Context, id ...int) ([]Todo, error) {
+	if len(id) > 0 && id[0] > 0 {
+		return m.selectTodos(ctx, func(todo Todo) bool { return todo.ID == id[0] })
+	}
+	return m.selectTodos(ctx, func(Todo) bool { return true })
+}
+
+// ListTodosAsync retrieves a page of todos selected by query, ordered by id,
+// and reports whether more todos follow the page
+func (m *MemoryStore) ListTodosAsync(ctx context.Context, query TodoQuery) ([]Todo, bool, error) {
+	todos, err := m.selectTodos(ctx, func(todo Todo) bool {
+		if todo.ID <= query.AfterID {
+			return false
+		}
+		if query.Status != "" && todo.Status != query.Status {
+			return false
+		}
+		if query.DueOnOrBefore != "" && (todo.DueDate == nil || *todo.DueDate > query.DueOnOrBefore) {
+			return false
+		}
+		if query.ProjectID != nil && (todo.ProjectID == nil || *todo.ProjectID != *query.ProjectID) {
+			return false
+		}
+		if tags := tagSet(query.Tags); len(tags) > 0 {
+			matched := 0
+			for _, tag := range tags {
+				if slices.Contains(todo.Tags, tag) {
+					matched++
+				}
+			}
+			if matched == 0 || (query.MatchAllTags && matched < len(tags)) {
+				return false
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	if query.Limit > 0 && len(todos) > query.Limit {
+		return todos[:query.Limit], true, nil
+	}
+	return todos, false, nil
+}
+
+// SearchTodosAsync finds todos whose id starts with term or whose description
+// contains it, ignoring ASCII case, with id matches first and each group in id
+// order. It returns at most limit todos, or every match when limit is zero or
+// less, along with the number of todos that match.
+func (m *MemoryStore) SearchTodosAsync(ctx context.Context, term string, limit int) ([]Todo, int, error) {
+	idMatch := func(todo Todo) bool { return strings.HasPrefix(strconv.Itoa(todo.ID), term) }
+	folded := foldASCII(term)
+
+	todos, err := m.selectTodos(ctx, func(todo Todo) bool {
+		return idMatch(todo) || (todo.Description != nil && strings.Contains(foldASCII(*todo.Description), folded))
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	sort.SliceStable(todos, func(i, j int) bool { return idMatch(todos[i]) && !idMatch(todos[j]) })
+	return truncate(todos, limit), len(todos), nil
+}
+
+// foldASCII lowercases the ASCII letters in s and leaves every other character
+// alone, matching how SQLite's LIKE and NOCASE collation ignore case
+func foldASCII(s string) string {
+	return strings.Map(func(r rune) rune {
+		if 'A' <= r && r <= 'Z' {
+			return r + 'a' - 'A'
+		}
+		return r
+	}, s)
+}
+
+// truncate returns the first limit items, or every item when limit is zero or less
+func truncate[T any](items []T, limit int) []T {
+	if limit > 0 && len(items) > limit {
+		return items[:limit]
+	}
+	return items
+}
+
+// selectTodos returns copies of the todos matching keep, ordered by id
+func (m *MemoryStore) selectTodos(ctx context.Context, keep func(Todo) bool) ([]Todo, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if err := m.check(ctx); err != nil {
+		return nil, err
+	}
+
+	var todos []Todo
+	for _, todo := range m.todos {
+		if keep(todo) {
+			todos = append(todos, cloneTodo(todo))
+		}
+	}
+	sort.Slice(todos, func(i, j int) bool { return todos[i].ID < todos[j].ID })
+	return todos, nil
+}
+
+// UpdateTodoAsync updates a todo by ID
+func (m *MemoryStore) UpdateTodoAsync(ctx context.Context, id int, input UpdateTodoInput) (bool, error) {
+	return m.modifyTodo(ctx, id, func(todo *Todo) bool {
+		changed := false
+		if input.Description != nil && strings.TrimSpace(*input.Description) != "" {
+			todo.Description = input.Description
+			changed = true
+		}
+		if input.CreatedDate != nil {
+			todo.CreatedDate = *input.CreatedDate
+			changed = true
+		}
+		if input.ClearDue {
+			todo.DueDate, todo.DueTime, todo.DueTimeZone = nil, nil, nil
+			changed = true
+		} else if input.DueDate != nil {
+			todo.DueDate, todo.DueTime, todo.DueTimeZone = input.DueDate, input.DueTime, input.DueTimeZone
+			changed = true
+		}
+		if input.Tags != nil {
+			todo.Tags = tagSet(input.Tags)
+			changed = true
+		}
+		return changed
+	})
+}
+
+// CompleteTodoAsync marks a todo as done at completedAt. Completing a todo
+// that is already done keeps its original completion time. It reports false
+// when no todo has the ID.
+func (m *MemoryStore) CompleteTodoAsync(ctx context.Context, id int, completedAt time.Time) (bool, error) {
+	return m.modifyTodo(ctx, id, func(todo *Todo) bool {
+		if todo.Status == StatusDone {
+			return false
+		}
+		todo.Status, todo.CompletedAt = StatusDone, &completedAt
+		return true
+	})
+}
+
+// ReopenTodoAsync marks a todo as open again and clears its completion time.
+// It reports false when no todo has the ID.
+func (m *MemoryStore) ReopenTodoAsync(ctx context.Context, id int) (bool, error) {
+	return m.modifyTodo(ctx, id, func(todo *Todo) bool {
+		if todo.Status == StatusOpen {
+			return false
+		}
+		todo.Status, todo.CompletedAt = StatusOpen, nil
+		return true
+	})
+}
+
+// modifyTodo applies change to the todo with the given ID, notifying listeners
+// when change reports that it altered the todo. It reports false when no todo
+// has the ID.
+func (m *MemoryStore) modifyTodo(ctx context.Context, id int, change func(todo *Todo) bool) (bool, error) {
+	m.mu.Lock()
+	if err := m.check(ctx); err != nil {
+		m.mu.Unlock()
+		return false, err
+	}
+	stored, ok := m.todos[id]
+	if !ok {
+		m.mu.Unlock()
+		return false, nil
+	}
+	todo := cloneTodo(stored)
+	changed := change(&todo)
+	m.todos[id] = cloneTodo(todo)
+	m.mu.Unlock()
+
+	if changed {
+		m.notifyChange(TodoChange{Kind: TodoUpdated, ID: id, PreviousProjectID: stored.ProjectID, ProjectID: todo.ProjectID})
+	}
+	return true, nil
+}
+
+// MoveTodoAsync moves a todo into a project, or out of every project when
+// projectID is nil. It reports false when no todo has the ID.
+func (m *MemoryStore) MoveTodoAsync(ctx context.Context, id int, projectID *int) (bool, error) {
+	return m.modifyTodo(ctx, id, func(todo *Todo) bool {
+		if (todo.ProjectID == nil && projectID == nil) || (todo.ProjectID != nil && projectID != nil && *todo.ProjectID == *projectID) {
+			return false
+		}
+		todo.ProjectID = projectID
+		return true
+	})
+}
+
+// AddTagsAsync adds tags to a todo. Tags the todo already has are left alone.
+// It reports false when no todo has the ID.
+func (m *MemoryStore) AddTagsAsync(ctx context.Context, id int, tags []string) (bool, error) {
+	return m.modifyTodo(ctx, id, func(todo *Todo) bool {
+		before := len(todo.Tags)
+		todo.Tags = tagSet(append(todo.Tags, tags...))
+		return len(todo.Tags) > before
+	})
+}
+
+// RemoveTagsAsync removes tags from a todo, ignoring tags it does not have. It
+// reports false when no todo has the ID.
+func (m *MemoryStore) RemoveTagsAsync(ctx context.Context, id int, tags []string) (bool, error) {
+	return m.modifyTodo(ctx, id, func(todo *Todo) bool {
+		before := len(todo.Tags)
+		todo.Tags = slices.DeleteFunc(todo.Tags, func(tag string) bool { return slices.Contains(tags, tag) })
+		return len(todo.Tags) < before
+	})
+}
+
+// ListTagsAsync returns every tag in use with the number of todos that carry
+// it, in alphabetical order
+func (m *MemoryStore) ListTagsAsync(ctx context.Context) ([]TagCount, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if err := m.check(ctx); err != nil {
+		return nil, err
+	}
+
+	counts := map[string]int{}
+	for _, todo := range m.todos {
+		for _, tag := range todo.Tags {
+			counts[tag]++
+		}
+	}
+
+	var tags []TagCount
+	for name, count := range counts {
+		tags = append(tags, TagCount{Name: name, Count: count})
+	}
+	slices.SortFunc(tags, func(a, b TagCount) int { return strings.Compare(a.Name, b.Name) })
+	return tags, nil
+}
+
+// CreateProjectAsync creates a new project and returns it. It returns
+// ErrProjectNameTaken when another project has the name, ignoring ASCII case.
+func (m *MemoryStore) CreateProjectAsync(ctx context.Context, input CreateProjectInput) (*Project, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.check(ctx); err != nil {
+		return nil, err
+	}
+
+	for _, project := range m.projects {
+		if foldASCII(project.Name) == foldASCII(input.Name) {
+			return nil, ErrProjectNameTaken
+		}
+	}
+
+	m.lastProjectID++
+	project := Project{ID: m.lastProjectID, Name: input.Name, CreatedDate: input.CreatedDate}
+	m.projects[project.ID] = project
+	return &project, nil
+}
+
+// ReadProjectsAsync retrieves all projects, archived or not, or a specific
+// project by ID, ordered by id
+func (m *MemoryStore) ReadProjectsAsync(ctx context.Context, id ...int) ([]Project, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	if err := m.check(ctx); err != nil {
+		return nil, err
+	}
+
+	var projects []Project
+	for _, project := range m.projects {
+		if len(id) == 0 || id[0] <= 0 || project.ID == id[0] {
+			project.ArchivedAt = clonePointer(project.ArchivedAt)
+			projects = append(projects, project)
+		}
+	}
+	sort.Slice(projects, func(i, j int) bool { return projects[i].ID < projects[j].ID })
+	return projects, nil
+}
+
+// SearchProjectsAsync finds projects that are not archived whose id starts
+// with term or whose name contains it, ignoring ASCII case, with id matches
+// first and each group in id order. It returns at most limit projects, or
+// every match when limit is zero or less, along with the number that match.
+func (m *MemoryStore) SearchProjectsAsync(ctx context.Context, term string, limit int) ([]Project, int, error) {
+	projects, err := m.ReadProjectsAsync(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	idMatch := func(project Project) bool { return strings.HasPrefix(strconv.Itoa(project.ID), term) }
+	folded := foldASCII(term)
+	var matches []Project
+	for _, project := range projects {
+		if project.ArchivedAt == nil && (idMatch(project) || strings.Contains(foldASCII(project.Name), folded)) {
+			matches = append(matches, project)
+		}
+	}
+
+	sort.SliceStable(matches, func(i, j int) bool { return idMatch(matches[i]) && !idMatch(matches[j]) })
+	return truncate(matches, limit), len(matches), nil
+}
+
+// ArchiveProjectAsync archives a project at archivedAt. Archiving a project
+// that is already archived keeps its original archive time. It reports false
+// when no project has the ID.
+func (m *MemoryStore) ArchiveProjectAsync(ctx context.Context, id int, archivedAt time.Time) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err := m.check(ctx); err != nil {
+		return false, err
+	}
+
+	project, ok := m.projects[id]
+	if !ok {
+		return false, nil
+	}
+	if project.ArchivedAt == nil {
+		project.ArchivedAt = &archivedAt
+		m.projects[id] = project
+	}
+	return true, nil
+}
+
+// DeleteTodoAsync deletes a todo by ID
+func (m *MemoryStore) DeleteTodoAsync(ctx context.Context, id int) (bool, error) {
+	m.mu.Lock()
+	if err := m.check(ctx); err != nil {
+		m.mu.Unlock()
+		return false, err
+	}
+	stored, ok := m.todos[id]
+	if !ok {
+		m.mu.Unlock()
+		return false, nil
+	}
+	delete(m.todos, id)
+	m.mu.Unlock()
+
+	m.notifyChange(TodoChange{Kind: TodoDeleted, ID: id, PreviousProjectID: stored.ProjectID})
+	return true, nil
+}
+
+// cloneTodo copies a todo, including the values its pointer fields refer to,
+// so callers cannot change stored todos through the copies they are given
+func cloneTodo(todo Todo) Todo {
+	todo.Description = clonePointer(todo.Description)
+	todo.CompletedAt = clonePointer(todo.CompletedAt)
+	todo.DueDate = clonePointer(todo.DueDate)
+	todo.DueTime = clonePointer(todo.DueTime)
+	todo.DueTimeZone = clonePointer(todo.DueTimeZone)
+	todo.Tags = slices.Clone(todo.Tags)
+	todo.ProjectID = clonePointer(todo.ProjectID)
+	return todo
+}
+
+// clonePointer returns a pointer to a copy of *p, or nil when p is nil
+func clonePointer[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
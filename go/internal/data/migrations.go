@@ -0,0 +1,242 @@
+package data
+
+import (
+	"context"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"regexp"
+	"sort"
+	"strconv"
+	"time"
+)
+
+// migrationFiles holds the schema migrations, named NNNN_description.sql and
+// applied in version order
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// migrationName matches a migration file name and captures its version and description
+var migrationName = regexp.MustCompile(`^(\d{4})_([a-z0-9_]+)\.sql$`)
+
+// Migration is a single schema change
+type Migration struct {
+	Version int
+	Name    string
+	SQL     string
+}
+
+// MigrationStatus describes how a database's schema compares with the migrations built into the binary
+type MigrationStatus struct {
+	// Current is the schema version of the database; 0 when it has no schema yet
+	Current int
+	// Latest is the newest schema version the binary knows
+	Latest int
+	// Inferred is true when the database predates schema_migrations and
+	// Current was worked out from its tables
+	Inferred bool
+	// Pending are the migrations startup would apply, in order
+	Pending []Migration
+}
+
+// SchemaTooNewError is returned when a database was migrated by a newer binary
+type SchemaTooNewError struct {
+	Database int
+	Binary   int
+}
+
+func (e *SchemaTooNewError) Error() string {
+	return fmt.Sprintf("database schema version %d is newer than this binary supports (%d); upgrade the server", e.Database, e.Binary)
+}
+
+// Migrations returns the migrations built into the binary, in version order
+func Migrations() ([]Migration, error) {
+	sub, err := fs.Sub(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	return loadMigrations(sub)
+}
+
+// loadMigrations reads the migrations in fsys, requiring versions to start at
+// 1 and have no gaps
+func loadMigrations(fsys fs.FS) ([]Migration, error) {
+	entries, err := fs.ReadDir(fsys, ".")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	var migrations []Migration
+	for _, entry := range entries {
+		match := migrationName.FindStringSubmatch(entry.Name())
+		if entry.IsDir() || match == nil {
+			return nil, fmt.Errorf("unexpected migration file %s", entry.Name())
+		}
+		version, _ := strconv.Atoi(match[1])
+		content, err := fs.ReadFile(fsys, entry.Name())
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", entry.Name(), err)
+		}
+		migrations = append(migrations, Migration{Version: version, Name: match[2], SQL: string(content)})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	for i, migration := range migrations {
+		if migration.Version != i+1 {
+			return nil, fmt.Errorf("migration versions must run from 1 without gaps, found %04d at position %d", migration.Version, i+1)
+		}
+	}
+	return migrations, nil
+}
+
+// migrate brings the schema up to date, applying each pending migration in its
+// own transaction. It refuses to touch a database newer than the binary.
+func (dc *DatabaseContext) migrate(ctx context.Context) error {
+	migrations, err := Migrations()
+	if err != nil {
+		return err
+	}
+	return dc.applyMigrations(ctx, migrations)
+}
+
+// applyMigrations applies the migrations the database has not yet seen
+func (dc *DatabaseContext) applyMigrations(ctx context.Context, migrations []Migration) error {
+	_, err := dc.db.ExecContext(ctx, `
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			applied_at DATETIME NOT NULL
+		);`)
+	if err != nil {
+		return fmt.Errorf("failed to create schema_migrations: %w", err)
+	}
+
+	current, inferred, err := schemaVersion(ctx, dc.db)
+	if err != nil {
+		return err
+	}
+	if current > len(migrations) {
+		return &SchemaTooNewError{Database: current, Binary: len(migrations)}
+	}
+
+	if inferred {
+		// Record the migrations the existing tables already reflect, so they are not run again
+		for _, migration := range migrations[:current] {
+			if err := recordMigration(ctx, dc.db, migration); err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, migration := range migrations[current:] {
+		if err := dc.applyMigration(ctx, migration); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// applyMigration runs one migration and records it, atomically
+func (dc *DatabaseContext) applyMigration(ctx context.Context, migration Migration) error {
+	tx, err := dc.db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin migration %04d: %w", migration.Version, err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.ExecContext(ctx, migration.SQL); err != nil {
+		return fmt.Errorf("failed to apply migration %04d_%s: %w", migration.Version, migration.Name, err)
+	}
+	if err := recordMigration(ctx, tx, migration); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration %04d: %w", migration.Version, err)
+	}
+	return nil
+}
+
+// execer is the part of *sql.DB and *sql.Tx used to record migrations
+type execer interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+}
+
+// recordMigration notes in schema_migrations that a migration has been applied
+func recordMigration(ctx context.Context, db execer, migration Migration) error {
+	_, err := db.ExecContext(ctx, `INSERT INTO schema_migrations (version, name, applied_at) VALUES (?, ?, ?)`,
+		migration.Version, migration.Name, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("failed to record migration %04d: %w", migration.Version, err)
+	}
+	return nil
+}
+
+// queryer is the part of *sql.DB used to read the schema version
+type queryer interface {
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// schemaVersion returns the version recorded in schema_migrations. Databases
+// created before migrations existed have no recorded versions but do have the
+// baseline todos table, so their version is inferred as 1 and inferred is true.
+func schemaVersion(ctx context.Context, db queryer) (int, bool, error) {
+	var hasTable int
+	err := db.QueryRowContext(ctx, `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations'`).Scan(&hasTable)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to inspect schema: %w", err)
+	}
+	if hasTable > 0 {
+		var recorded sql.NullInt64
+		if err := db.QueryRowContext(ctx, `SELECT MAX(version) FROM schema_migrations`).Scan(&recorded); err != nil {
+			return 0, false, fmt.Errorf("failed to read schema version: %w", err)
+		}
+		if recorded.Valid {
+			return int(recorded.Int64), false, nil
+		}
+	}
+
+	var hasTodos int
+	err = db.QueryRowContext(ctx, `SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'todos'`).Scan(&hasTodos)
+	if err != nil {
+		return 0, false, fmt.Errorf("failed to inspect schema: %w", err)
+	}
+	if hasTodos > 0 {
+		return 1, true, nil
+	}
+	return 0, false, nil
+}
+
+// InspectMigrations reports the schema version of the database at dbPath and
+// the migrations startup would apply, without changing or creating the file
+func InspectMigrations(ctx context.Context, dbPath string) (*MigrationStatus, error) {
+	migrations, err := Migrations()
+	if err != nil {
+		return nil, err
+	}
+	status := &MigrationStatus{Latest: len(migrations)}
+
+	if _, err := os.Stat(dbPath); errors.Is(err, fs.ErrNotExist) {
+		status.Pending = migrations
+		return status, nil
+	}
+
+	db, err := sql.Open("sqlite3", "file:"+dbPath+"?mode=ro")
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	defer db.Close()
+
+	status.Current, status.Inferred, err = schemaVersion(ctx, db)
+	if err != nil {
+		return nil, err
+	}
+	if status.Current > status.Latest {
+		return status, &SchemaTooNewError{Database: status.Current, Binary: status.Latest}
+	}
+	status.Pending = migrations[status.Current:]
+	return status, nil
+}
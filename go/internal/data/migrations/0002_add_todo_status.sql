@@ -0,0 +1,2 @@
+ALTER TABLE todos ADD COLUMN status TEXT NOT NULL DEFAULT 'open';
+ALTER TABLE todos ADD COLUMN completed_at DATETIME;
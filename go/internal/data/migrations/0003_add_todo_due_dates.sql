@@ -0,0 +1,3 @@
+ALTER TABLE todos ADD COLUMN due_date TEXT;
+ALTER TABLE todos ADD COLUMN due_time TEXT;
+ALTER TABLE todos ADD COLUMN due_time_zone TEXT;
@@ -0,0 +1,2 @@
+-- get_agenda reads open todos by due date
+CREATE INDEX idx_todos_status_due_date ON todos (status, due_date);
@@ -0,0 +1,14 @@
+-- Tags are shared by name between todos. Tags no todo uses are removed by the
+-- store, and links are removed with their todo.
+CREATE TABLE tags (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE
+);
+
+CREATE TABLE todo_tags (
+    todo_id INTEGER NOT NULL,
+    tag_id INTEGER NOT NULL,
+    PRIMARY KEY (todo_id, tag_id)
+);
+
+CREATE INDEX idx_todo_tags_tag_id ON todo_tags (tag_id);
@@ -0,0 +1,11 @@
+-- Projects group todos; a todo without a project_id is in no project
+CREATE TABLE projects (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    name TEXT NOT NULL UNIQUE COLLATE NOCASE,
+    created_date DATETIME NOT NULL,
+    archived_at DATETIME
+);
+
+ALTER TABLE todos ADD COLUMN project_id INTEGER;
+
+CREATE INDEX idx_todos_project_id ON todos (project_id);
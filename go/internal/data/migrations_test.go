@@ -0,0 +1,238 @@
+//go:build cgo
+
+package data
+
+import (
+	"database/sql"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+)
+
+// appliedVersions returns the versions recorded in schema_migrations, in order
+func appliedVersions(t *testing.T, db *sql.DB) []int {
+	t.Helper()
+	rows, err := db.Query(`SELECT version FROM schema_migrations ORDER BY version`)
+	if err != nil {
+		t.Fatalf("Failed to read schema_migrations: %v", err)
+	}
+	defer rows.Close()
+
+	var versions []int
+	for rows.Next() {
+		var version int
+		if err := rows.Scan(&version); err != nil {
+			t.Fatalf("Failed to scan version: %v", err)
+		}
+		versions = append(versions, version)
+	}
+	return versions
+}
+
+// createLegacyDatabase writes a database in the original three-column schema,
+// as created before migrations were recorded
+func createLegacyDatabase(t *testing.T, path string) {
+	t.Helper()
+	legacy, err := sql.Open("sqlite3", path)
+	if err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+	defer legacy.Close()
+	_, err = legacy.Exec(`
+		CREATE TABLE todos (id INTEGER PRIMARY KEY AUTOINCREMENT, description TEXT, created_date DATETIME NOT NULL);
+		INSERT INTO todos (description, created_date) VALUES ('Old todo', '2024-01-01 10:00:00+00:00');`)
+	if err != nil {
+		t.Fatalf("Failed to create legacy schema: %v", err)
+	}
+}
+
+func TestMigrations_Embedded(t *testing.T) {
+	migrations, err := Migrations()
+	if err != nil {
+		t.Fatalf("Failed to load migrations: %v", err)
+	}
+	if len(migrations) == 0 {
+		t.Fatal("Expected embedded migrations")
+	}
+	for i, migration := range migrations {
+		if migration.Version != i+1 || migration.Name == "" || strings.TrimSpace(migration.SQL) == "" {
+			t.Errorf("Unexpected migration at position %d: %+v", i+1, migration)
+		}
+	}
+}
+
+func TestMigrate_FreshDatabase(t *testing.T) {
+	db, err := NewInMemoryDatabaseContext()
+	if err != nil {
+		t.Fatalf("Failed to create in-memory database: %v", err)
+	}
+	defer db.Close()
+
+	migrations, _ := Migrations()
+	versions := appliedVersions(t, db.db)
+	if len(versions) != len(migrations) || versions[len(versions)-1] != len(migrations) {
+		t.Errorf("Expected versions 1 to %d to be recorded, got %v", len(migrations), versions)
+	}
+
+	// Migrating an up-to-date database changes nothing
+	if err := db.migrate(t.Context()); err != nil {
+		t.Fatalf("Failed to migrate again: %v", err)
+	}
+	if again := appliedVersions(t, db.db); len(again) != len(versions) {
+		t.Errorf("Expected %d recorded versions after migrating again, got %v", len(versions), again)
+	}
+}
+
+func TestMigrate_LegacyDatabase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todos.db")
+	createLegacyDatabase(t, path)
+
+	db, err := NewDatabaseContext(path)
+	if err != nil {
+		t.Fatalf("Failed to open legacy database: %v", err)
+	}
+	defer db.Close()
+
+	migrations, _ := Migrations()
+	versions := appliedVersions(t, db.db)
+	if len(versions) != len(migrations) || versions[0] != 1 {
+		t.Errorf("Expected versions 1 to %d to be recorded, got %v", len(migrations), versions)
+	}
+
+	todos, err := db.ReadTodosAsync(t.Context())
+	if err != nil {
+		t.Fatalf("Failed to read todos: %v", err)
+	}
+	if len(todos) != 1 || *todos[0].Description != "Old todo" {
+		t.Errorf("Expected the existing todo to survive migration, got %+v", todos)
+	}
+}
+
+func TestMigrate_RefusesNewerDatabase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "todos.db")
+	db, err := NewDatabaseContext(path)
+	if err != nil {
+		t.Fatalf("Failed to create database: %v", err)
+	}
+	if _, err := db.db.Exec(`INSERT INTO schema_migrations (version, name, applied_at) VALUES (99, 'from_the_future', CURRENT_TIMESTAMP)`); err != nil {
+		t.Fatalf("Failed to record future migration: %v", err)
+	}
+	db.Close()
+
+	_, err = NewDatabaseContext(path)
+	var tooNew *SchemaTooNewError
+	if !errors.As(err, &tooNew) {
+		t.Fatalf("Expected a SchemaTooNewError, got %v", err)
+	}
+	if tooNew.Database != 99 {
+		t.Errorf("Expected database version 99, got %d", tooNew.Database)
+	}
+}
+
+func TestApplyMigrations_RollsBackFailedMigration(t *testing.T) {
+	db, err := NewInMemoryDatabaseContext()
+	if err != nil {
+		t.Fatalf("Failed to create in-memory database: %v", err)
+	}
+	defer db.Close()
+
+	migrations, _ := Migrations()
+	broken := append(migrations, Migration{
+		Version: len(migrations) + 1,
+		Name:    "broken",
+		SQL:     `CREATE TABLE half_done (id INTEGER); NOT VALID SQL;`,
+	})
+
+	if err := db.applyMigrations(t.Context(), broken); err == nil {
+		t.Fatal("Expected the broken migration to fail")
+	}
+
+	var tables int
+	if err := db.db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE name = 'half_done'`).Scan(&tables); err != nil {
+		t.Fatalf("Failed to inspect schema: %v", err)
+	}
+	if tables != 0 {
+		t.Error("Expected the failed migration's table to be rolled back")
+	}
+	if versions := appliedVersions(t, db.db); len(versions) != len(migrations) {
+		t.Errorf("Expected the failed migration not to be recorded, got %v", versions)
+	}
+}
+
+func TestLoadMigrations_Invalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		files fstest.MapFS
+	}{
+		{"gap", fstest.MapFS{
+			"0001_first.sql": {Data: []byte("SELECT 1;")},
+			"0003_third.sql": {Data: []byte("SELECT 1;")},
+		}},
+		{"bad name", fstest.MapFS{
+			"0001_first.sql": {Data: []byte("SELECT 1;")},
+			"second.sql":     {Data: []byte("SELECT 1;")},
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := loadMigrations(tt.files); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
+
+func TestInspectMigrations(t *testing.T) {
+	migrations, _ := Migrations()
+	dir := t.TempDir()
+
+	t.Run("missing file", func(t *testing.T) {
+		path := filepath.Join(dir, "missing.db")
+		status, err := InspectMigrations(t.Context(), path)
+		if err != nil {
+			t.Fatalf("Failed to inspect migrations: %v", err)
+		}
+		if status.Current != 0 || len(status.Pending) != len(migrations) {
+			t.Errorf("Expected every migration to be pending, got %+v", status)
+		}
+		if _, err := os.Stat(path); !errors.Is(err, os.ErrNotExist) {
+			t.Error("Expected the database file not to be created")
+		}
+	})
+
+	t.Run("legacy", func(t *testing.T) {
+		path := filepath.Join(dir, "legacy.db")
+		createLegacyDatabase(t, path)
+		status, err := InspectMigrations(t.Context(), path)
+		if err != nil {
+			t.Fatalf("Failed to inspect migrations: %v", err)
+		}
+		if status.Current != 1 || !status.Inferred || len(status.Pending) != len(migrations)-1 {
+			t.Errorf("Expected an inferred version 1 with the rest pending, got %+v", status)
+		}
+		if _, err := NewDatabaseContext(path); err != nil {
+			t.Fatalf("Failed to open legacy database: %v", err)
+		}
+	})
+
+	t.Run("current", func(t *testing.T) {
+		path := filepath.Join(dir, "current.db")
+		db, err := NewDatabaseContext(path)
+		if err != nil {
+			t.Fatalf("Failed to create database: %v", err)
+		}
+		db.Close()
+
+		status, err := InspectMigrations(t.Context(), path)
+		if err != nil {
+			t.Fatalf("Failed to inspect migrations: %v", err)
+		}
+		if status.Current != status.Latest || status.Inferred || len(status.Pending) != 0 {
+			t.Errorf("Expected nothing pending, got %+v", status)
+		}
+	})
+}
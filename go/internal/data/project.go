@@ -0,0 +1,25 @@
+package data
+
+import (
+	"errors"
+	"time"
+)
+
+// ErrProjectNameTaken is returned when creating a project whose name, ignoring
+// case, is already used by another project
+var ErrProjectNameTaken = errors.New("project name is already taken")
+
+// Project groups related todos. An archived project keeps its todos but
+// accepts no new ones.
+type Project struct {
+	ID          int        `json:"id" db:"id" description:"Id of the project" required:"true"`
+	Name        string     `json:"name" db:"name" description:"Name of the project" required:"true"`
+	CreatedDate time.Time  `json:"createdDate" db:"created_date" description:"Creation date of the project" required:"true"`
+	ArchivedAt  *time.Time `json:"archivedAt" db:"archived_at" description:"When the project was archived, or null while it is active" required:"true"`
+}
+
+// CreateProjectInput represents input for creating a new project
+type CreateProjectInput struct {
+	Name        string    `json:"name"`
+	CreatedDate time.Time `json:"createdDate"`
+}
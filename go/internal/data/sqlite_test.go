@@ -0,0 +1,15 @@
+//go:build cgo
+
+package data
+
+import "testing"
+
+func init() {
+	storeBackends["sqlite"] = func(t *testing.T) TodoStore {
+		db, err := NewInMemoryDatabaseContext()
+		if err != nil {
+			t.Fatalf("Failed to create in-memory database: %v", err)
+		}
+		return db
+	}
+}
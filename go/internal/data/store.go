@@ -0,0 +1,71 @@
+package data
+
+import (
+	"context"
+	"time"
+)
+
+// TodoStore persists todos. Every backend must pass the conformance suite in
+// store_test.go, so callers can rely on the behaviour documented here whichever
+// backend they are given.
+type TodoStore interface {
+	// CreateTodoAsync creates a todo and returns it. Ids increase and are never reused.
+	CreateTodoAsync(ctx context.Context, input CreateTodoInput) (*Todo, error)
+	// ReadTodosAsync returns every todo in id order, or only the todo with the
+	// given id when one is passed
+	ReadTodosAsync(ctx context.Context, id ...int) ([]Todo, error)
+	// ListTodosAsync returns a page of todos selected by query and reports
+	// whether more follow
+	ListTodosAsync(ctx context.Context, query TodoQuery) ([]Todo, bool, error)
+	// SearchTodosAsync finds todos whose id starts with term or whose
+	// description contains it, ignoring ASCII case, with id matches first. It returns
+	// at most limit todos, or all when limit is zero or less, and the number of matches.
+	SearchTodosAsync(ctx context.Context, term string, limit int) ([]Todo, int, error)
+	// UpdateTodoAsync applies input to a todo, reporting false when no todo has the id
+	UpdateTodoAsync(ctx context.Context, id int, input UpdateTodoInput) (bool, error)
+	// CompleteTodoAsync marks a todo as done, keeping the completion time of a
+	// todo that is already done, and reports false when no todo has the id
+	CompleteTodoAsync(ctx context.Context, id int, completedAt time.Time) (bool, error)
+	// ReopenTodoAsync marks a todo as open, reporting false when no todo has the id
+	ReopenTodoAsync(ctx context.Context, id int) (bool, error)
+	// DeleteTodoAsync deletes a todo, reporting false when no todo has the id
+	DeleteTodoAsync(ctx context.Context, id int) (bool, error)
+	// AddTagsAsync adds tags to a todo, reporting false when no todo has the id
+	AddTagsAsync(ctx context.Context, id int, tags []string) (bool, error)
+	// RemoveTagsAsync removes tags from a todo, reporting false when no todo has the id
+	RemoveTagsAsync(ctx context.Context, id int, tags []string) (bool, error)
+	// ListTagsAsync returns the tags carried by at least one todo with their
+	// counts, in alphabetical order
+	ListTagsAsync(ctx context.Context) ([]TagCount, error)
+	// MoveTodoAsync moves a todo into a project, or out of every project when
+	// projectID is nil, reporting false when no todo has the id. It does not
+	// check that the project exists.
+	MoveTodoAsync(ctx context.Context, id int, projectID *int) (bool, error)
+
+	// CreateProjectAsync creates a project, returning ErrProjectNameTaken when
+	// another project has the name, ignoring ASCII case
+	CreateProjectAsync(ctx context.Context, input CreateProjectInput) (*Project, error)
+	// ReadProjectsAsync returns every project in id order, archived or not, or
+	// only the project with the given id when one is passed
+	ReadProjectsAsync(ctx context.Context, id ...int) ([]Project, error)
+	// SearchProjectsAsync finds projects that are not archived whose id starts
+	// with term or whose name contains it, ignoring ASCII case, with id matches first.
+	// It returns at most limit projects, or all when limit is zero or less, and
+	// the number of matches.
+	SearchProjectsAsync(ctx context.Context, term string, limit int) ([]Project, int, error)
+	// ArchiveProjectAsync archives a project, keeping the archive time of a
+	// project that is already archived, and reports false when no project has the id
+	ArchiveProjectAsync(ctx context.Context, id int, archivedAt time.Time) (bool, error)
+
+	// OnChange registers a listener called after each mutation that changed a todo
+	OnChange(listener ChangeListener)
+	// Ping reports whether the store can serve requests
+	Ping(ctx context.Context) error
+	// Close releases the store; later calls fail
+	Close() error
+}
+
+var (
+	_ TodoStore = (*DatabaseContext)(nil)
+	_ TodoStore = (*MemoryStore)(nil)
+)
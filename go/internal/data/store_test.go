@@ -0,0 +1,677 @@
+package data
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// storeBackends lists every TodoStore implementation; each must pass the
+// conformance suite below. SQLite needs cgo, so it adds itself in sqlite_test.go.
+var storeBackends = map[string]func(t *testing.T) TodoStore{
+	"memory": func(t *testing.T) TodoStore {
+		return NewMemoryStore()
+	},
+}
+
+// storeConformance are the behaviours every TodoStore must share
+var storeConformance = map[string]func(t *testing.T, store TodoStore){
+	"CreateAndRead":         testStoreCreateAndRead,
+	"IDsAreNotReused":       testStoreIDsAreNotReused,
+	"ReturnsCopies":         testStoreReturnsCopies,
+	"List":                  testStoreList,
+	"Search":                testStoreSearch,
+	"Update":                testStoreUpdate,
+	"CompleteAndReopen":     testStoreCompleteAndReopen,
+	"Delete":                testStoreDelete,
+	"MissingTodos":          testStoreMissingTodos,
+	"Changes":               testStoreChanges,
+	"CancelledContext":      testStoreCancelledContext,
+	"FailsAfterClose":       testStoreFailsAfterClose,
+	"DueWithoutDateIgnored": testStoreDueWithoutDateIgnored,
+	"Tags":                  testStoreTags,
+	"TagFilters":            testStoreTagFilters,
+	"TagChanges":            testStoreTagChanges,
+	"Projects":              testStoreProjects,
+	"MoveTodos":             testStoreMoveTodos,
+}
+
+func TestTodoStore_Conformance(t *testing.T) {
+	for backend, newStore := range storeBackends {
+		t.Run(backend, func(t *testing.T) {
+			for name, test := range storeConformance {
+				t.Run(name, func(t *testing.T) {
+					store := newStore(t)
+					defer store.Close()
+					test(t, store)
+				})
+			}
+		})
+	}
+}
+
+// mustCreate creates a todo or fails the test
+func mustCreate(t *testing.T, store TodoStore, input CreateTodoInput) *Todo {
+	t.Helper()
+	if input.CreatedDate.IsZero() {
+		input.CreatedDate = time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	}
+	todo, err := store.CreateTodoAsync(t.Context(), input)
+	if err != nil {
+		t.Fatalf("Failed to create todo: %v", err)
+	}
+	return todo
+}
+
+// mustRead reads a single todo or fails the test
+func mustRead(t *testing.T, store TodoStore, id int) Todo {
+	t.Helper()
+	todos, err := store.ReadTodosAsync(t.Context(), id)
+	if err != nil {
+		t.Fatalf("Failed to read todo %d: %v", id, err)
+	}
+	if len(todos) != 1 {
+		t.Fatalf("Expected todo %d, got %v", id, todos)
+	}
+	return todos[0]
+}
+
+// todoIDs returns the ids of todos, in order
+func todoIDs(todos []Todo) []int {
+	ids := []int{}
+	for _, todo := range todos {
+		ids = append(ids, todo.ID)
+	}
+	return ids
+}
+
+// sameIDs reports whether todos have exactly the expected ids, in order
+func sameIDs(todos []Todo, expected ...int) bool {
+	ids := todoIDs(todos)
+	if len(ids) != len(expected) {
+		return false
+	}
+	for i := range ids {
+		if ids[i] != expected[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func testStoreCreateAndRead(t *testing.T, store TodoStore) {
+	created := time.Date(2024, 3, 1, 9, 30, 0, 0, time.UTC)
+	date, clock, zone := "2024-03-05", "17:00", "Europe/London"
+	first := mustCreate(t, store, CreateTodoInput{Description: "First", CreatedDate: created, DueDate: &date, DueTime: &clock, DueTimeZone: &zone})
+	second := mustCreate(t, store, CreateTodoInput{Description: "Second"})
+
+	if first.ID <= 0 || second.ID <= first.ID {
+		t.Errorf("Expected increasing positive ids, got %d and %d", first.ID, second.ID)
+	}
+
+	todo := mustRead(t, store, first.ID)
+	if *todo.Description != "First" || !todo.CreatedDate.Equal(created) || todo.Status != StatusOpen || todo.CompletedAt != nil {
+		t.Errorf("Unexpected todo: %+v", todo)
+	}
+	if todo.DueDate == nil || *todo.DueDate != date || *todo.DueTime != clock || *todo.DueTimeZone != zone {
+		t.Errorf("Expected the due date to be stored, got %+v", todo)
+	}
+
+	all, err := store.ReadTodosAsync(t.Context())
+	if err != nil {
+		t.Fatalf("Failed to read todos: %v", err)
+	}
+	if !sameIDs(all, first.ID, second.ID) {
+		t.Errorf("Expected todos %d and %d, got %v", first.ID, second.ID, todoIDs(all))
+	}
+}
+
+func testStoreIDsAreNotReused(t *testing.T, store TodoStore) {
+	first := mustCreate(t, store, CreateTodoInput{Description: "First"})
+	if _, err := store.DeleteTodoAsync(t.Context(), first.ID); err != nil {
+		t.Fatalf("Failed to delete todo: %v", err)
+	}
+	second := mustCreate(t, store, CreateTodoInput{Description: "Second"})
+	if second.ID <= first.ID {
+		t.Errorf("Expected id after %d, got %d", first.ID, second.ID)
+	}
+}
+
+func testStoreReturnsCopies(t *testing.T, store TodoStore) {
+	todo := mustCreate(t, store, CreateTodoInput{Description: "Original"})
+	*todo.Description = "Changed by caller"
+
+	read := mustRead(t, store, todo.ID)
+	*read.Description = "Changed again"
+
+	if got := mustRead(t, store, todo.ID); *got.Description != "Original" {
+		t.Errorf("Expected the stored todo to be unchanged, got %q", *got.Description)
+	}
+}
+
+func testStoreList(t *testing.T, store TodoStore) {
+	early, late := "2024-03-01", "2024-03-31"
+	a := mustCreate(t, store, CreateTodoInput{Description: "a", DueDate: &early})
+	b := mustCreate(t, store, CreateTodoInput{Description: "b", DueDate: &late})
+	c := mustCreate(t, store, CreateTodoInput{Description: "c"})
+	if _, err := store.CompleteTodoAsync(t.Context(), b.ID, time.Now()); err != nil {
+		t.Fatalf("Failed to complete todo: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		query    TodoQuery
+		expected []int
+		more     bool
+	}{
+		{"everything", TodoQuery{}, []int{a.ID, b.ID, c.ID}, false},
+		{"first page", TodoQuery{Limit: 2}, []int{a.ID, b.ID}, true},
+		{"last page", TodoQuery{AfterID: b.ID, Limit: 2}, []int{c.ID}, false},
+		{"exact page", TodoQuery{Limit: 3}, []int{a.ID, b.ID, c.ID}, false},
+		{"open", TodoQuery{Status: StatusOpen}, []int{a.ID, c.ID}, false},
+		{"done", TodoQuery{Status: StatusDone}, []int{b.ID}, false},
+		{"due by", TodoQuery{DueOnOrBefore: "2024-03-15"}, []int{a.ID}, false},
+		{"open and due", TodoQuery{Status: StatusOpen, DueOnOrBefore: "2024-12-31"}, []int{a.ID}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			todos, more, err := store.ListTodosAsync(t.Context(), tt.query)
+			if err != nil {
+				t.Fatalf("Failed to list todos: %v", err)
+			}
+			if !sameIDs(todos, tt.expected...) || more != tt.more {
+				t.Errorf("Expected %v (more %v), got %v (more %v)", tt.expected, tt.more, todoIDs(todos), more)
+			}
+		})
+	}
+}
+
+func testStoreSearch(t *testing.T, store TodoStore) {
+	var ids []int
+	for i := 0; i < 12; i++ {
+		ids = append(ids, mustCreate(t, store, CreateTodoInput{Description: "Filler"}).ID)
+	}
+	milk := mustCreate(t, store, CreateTodoInput{Description: "Buy MILK"})
+	literal := mustCreate(t, store, CreateTodoInput{Description: "100% done_ish"})
+
+	todos, _, err := store.SearchTodosAsync(t.Context(), "milk", 0)
+	if err != nil {
+		t.Fatalf("Failed to search todos: %v", err)
+	}
+	if !sameIDs(todos, milk.ID) {
+		t.Errorf("Expected a case-insensitive description match, got %v", todoIDs(todos))
+	}
+
+	// Only ASCII letters are matched regardless of case
+	accented := mustCreate(t, store, CreateTodoInput{Description: "Café ÉCLAIR"})
+	for term, expected := range map[string][]int{"CAFÉ": nil, "café": {accented.ID}, "éclair": nil, "Éclair": {accented.ID}} {
+		todos, _, err = store.SearchTodosAsync(t.Context(), term, 0)
+		if err != nil {
+			t.Fatalf("Failed to search todos: %v", err)
+		}
+		if !sameIDs(todos, expected...) {
+			t.Errorf("Search %q: expected %v, got %v", term, expected, todoIDs(todos))
+		}
+	}
+
+	// Wildcards in the term match literally
+	todos, _, err = store.SearchTodosAsync(t.Context(), "0% d", 0)
+	if err != nil {
+		t.Fatalf("Failed to search todos: %v", err)
+	}
+	if !sameIDs(todos, literal.ID) {
+		t.Errorf("Expected only the literal match, got %v", todoIDs(todos))
+	}
+
+	// Id prefix matches come before description matches, each in id order
+	term := strconv.Itoa(ids[1])
+	described := mustCreate(t, store, CreateTodoInput{Description: "Mentions " + term})
+	var expected []int
+	for _, id := range append(ids, milk.ID, literal.ID, accented.ID, described.ID) {
+		if strings.HasPrefix(strconv.Itoa(id), term) {
+			expected = append(expected, id)
+		}
+	}
+	if !strings.HasPrefix(strconv.Itoa(described.ID), term) {
+		expected = append(expected, described.ID)
+	}
+	todos, total, err := store.SearchTodosAsync(t.Context(), term, 0)
+	if err != nil {
+		t.Fatalf("Failed to search todos: %v", err)
+	}
+	if !sameIDs(todos, expected...) || total != len(expected) {
+		t.Errorf("Expected %v, got %v of %d", expected, todoIDs(todos), total)
+	}
+
+	// A limit keeps the best ranked matches and still counts them all
+	todos, total, err = store.SearchTodosAsync(t.Context(), term, 1)
+	if err != nil {
+		t.Fatalf("Failed to search todos: %v", err)
+	}
+	if !sameIDs(todos, expected[0]) || total != len(expected) {
+		t.Errorf("Expected [%d] of %d, got %v of %d", expected[0], len(expected), todoIDs(todos), total)
+	}
+}
+
+func testStoreUpdate(t *testing.T, store TodoStore) {
+	date, clock := "2024-04-01", "09:00"
+	todo := mustCreate(t, store, CreateTodoInput{Description: "Before", DueDate: &date, DueTime: &clock})
+
+	description := "After"
+	created := time.Date(2023, 6, 1, 0, 0, 0, 0, time.UTC)
+	newDate := "2024-05-01"
+	updated, err := store.UpdateTodoAsync(t.Context(), todo.ID, UpdateTodoInput{Description: &description, CreatedDate: &created, DueDate: &newDate})
+	if err != nil || !updated {
+		t.Fatalf("Failed to update todo: %v", err)
+	}
+	got := mustRead(t, store, todo.ID)
+	if *got.Description != description || !got.CreatedDate.Equal(created) {
+		t.Errorf("Expected the description and created date to change, got %+v", got)
+	}
+	if got.DueDate == nil || *got.DueDate != newDate || got.DueTime != nil {
+		t.Errorf("Expected the whole due date to be replaced, got %+v", got)
+	}
+
+	blank := "  "
+	if _, err := store.UpdateTodoAsync(t.Context(), todo.ID, UpdateTodoInput{Description: &blank, ClearDue: true}); err != nil {
+		t.Fatalf("Failed to update todo: %v", err)
+	}
+	got = mustRead(t, store, todo.ID)
+	if *got.Description != description {
+		t.Errorf("Expected a blank description to be ignored, got %q", *got.Description)
+	}
+	if got.DueDate != nil || got.DueTime != nil || got.DueTimeZone != nil {
+		t.Errorf("Expected the due date to be cleared, got %+v", got)
+	}
+}
+
+func testStoreCompleteAndReopen(t *testing.T, store TodoStore) {
+	todo := mustCreate(t, store, CreateTodoInput{Description: "Finish"})
+	first := time.Date(2024, 2, 1, 12, 0, 0, 0, time.UTC)
+
+	for _, at := range []time.Time{first, first.Add(time.Hour)} {
+		if ok, err := store.CompleteTodoAsync(t.Context(), todo.ID, at); err != nil || !ok {
+			t.Fatalf("Failed to complete todo: %v", err)
+		}
+	}
+	got := mustRead(t, store, todo.ID)
+	if got.Status != StatusDone || got.CompletedAt == nil || !got.CompletedAt.Equal(first) {
+		t.Errorf("Expected the first completion time to be kept, got %+v", got)
+	}
+
+	if ok, err := store.ReopenTodoAsync(t.Context(), todo.ID); err != nil || !ok {
+		t.Fatalf("Failed to reopen todo: %v", err)
+	}
+	got = mustRead(t, store, todo.ID)
+	if got.Status != StatusOpen || got.CompletedAt != nil {
+		t.Errorf("Expected the todo to be open, got %+v", got)
+	}
+}
+
+func testStoreDelete(t *testing.T, store TodoStore) {
+	keep := mustCreate(t, store, CreateTodoInput{Description: "Keep"})
+	drop := mustCreate(t, store, CreateTodoInput{Description: "Drop"})
+
+	if ok, err := store.DeleteTodoAsync(t.Context(), drop.ID); err != nil || !ok {
+		t.Fatalf("Failed to delete todo: %v", err)
+	}
+	all, err := store.ReadTodosAsync(t.Context())
+	if err != nil {
+		t.Fatalf("Failed to read todos: %v", err)
+	}
+	if !sameIDs(all, keep.ID) {
+		t.Errorf("Expected only todo %d, got %v", keep.ID, todoIDs(all))
+	}
+}
+
+func testStoreMissingTodos(t *testing.T, store TodoStore) {
+	ctx := t.Context()
+	description := "x"
+
+	if todos, err := store.ReadTodosAsync(ctx, 999); err != nil || len(todos) != 0 {
+		t.Errorf("Expected no todos, got %v (%v)", todos, err)
+	}
+	if ok, err := store.UpdateTodoAsync(ctx, 999, UpdateTodoInput{Description: &description}); err != nil || ok {
+		t.Errorf("Expected update to report a missing todo, got %v (%v)", ok, err)
+	}
+	if ok, err := store.CompleteTodoAsync(ctx, 999, time.Now()); err != nil || ok {
+		t.Errorf("Expected complete to report a missing todo, got %v (%v)", ok, err)
+	}
+	if ok, err := store.ReopenTodoAsync(ctx, 999); err != nil || ok {
+		t.Errorf("Expected reopen to report a missing todo, got %v (%v)", ok, err)
+	}
+	if ok, err := store.DeleteTodoAsync(ctx, 999); err != nil || ok {
+		t.Errorf("Expected delete to report a missing todo, got %v (%v)", ok, err)
+	}
+}
+
+func testStoreChanges(t *testing.T, store TodoStore) {
+	var changes []TodoChange
+	store.OnChange(func(change TodoChange) {
+		changes = append(changes, change)
+	})
+
+	ctx := t.Context()
+	todo := mustCreate(t, store, CreateTodoInput{Description: "Watched"})
+	description := "Changed"
+	store.UpdateTodoAsync(ctx, todo.ID, UpdateTodoInput{Description: &description})
+	store.UpdateTodoAsync(ctx, todo.ID, UpdateTodoInput{})
+	store.CompleteTodoAsync(ctx, todo.ID, time.Now())
+	store.CompleteTodoAsync(ctx, todo.ID, time.Now())
+	store.ReopenTodoAsync(ctx, todo.ID)
+	store.ReopenTodoAsync(ctx, todo.ID)
+	store.DeleteTodoAsync(ctx, todo.ID)
+	store.DeleteTodoAsync(ctx, todo.ID)
+
+	// Calls that change nothing are not reported
+	expected := []TodoChange{
+		{Kind: TodoCreated, ID: todo.ID},
+		{Kind: TodoUpdated, ID: todo.ID},
+		{Kind: TodoUpdated, ID: todo.ID},
+		{Kind: TodoUpdated, ID: todo.ID},
+		{Kind: TodoDeleted, ID: todo.ID},
+	}
+	if len(changes) != len(expected) {
+		t.Fatalf("Expected %d changes, got %v", len(expected), changes)
+	}
+	for i := range expected {
+		if changes[i] != expected[i] {
+			t.Errorf("Change %d: expected %v, got %v", i, expected[i], changes[i])
+		}
+	}
+}
+
+func testStoreCancelledContext(t *testing.T, store TodoStore) {
+	ctx, cancel := context.WithCancel(t.Context())
+	cancel()
+
+	if _, err := store.ReadTodosAsync(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func testStoreFailsAfterClose(t *testing.T, store TodoStore) {
+	if err := store.Ping(t.Context()); err != nil {
+		t.Fatalf("Expected an open store to answer ping: %v", err)
+	}
+	store.Close()
+
+	if err := store.Ping(t.Context()); err == nil {
+		t.Error("Expected ping to fail after close")
+	}
+	if _, err := store.CreateTodoAsync(t.Context(), CreateTodoInput{Description: "Late", CreatedDate: time.Now()}); err == nil {
+		t.Error("Expected create to fail after close")
+	}
+}
+
+func testStoreDueWithoutDateIgnored(t *testing.T, store TodoStore) {
+	clock, zone := "09:00", "UTC"
+	todo := mustCreate(t, store, CreateTodoInput{Description: "No date", DueTime: &clock, DueTimeZone: &zone})
+	if todo.DueTime != nil || todo.DueTimeZone != nil {
+		t.Errorf("Expected a time without a date to be dropped, got %+v", todo)
+	}
+	if got := mustRead(t, store, todo.ID); got.DueDate != nil || got.DueTime != nil || got.DueTimeZone != nil {
+		t.Errorf("Expected no due date to be stored, got %+v", got)
+	}
+}
+
+func testStoreTags(t *testing.T, store TodoStore) {
+	ctx := t.Context()
+	todo := mustCreate(t, store, CreateTodoInput{Description: "Tagged", Tags: []string{"work", "home", "work"}})
+	if !slices.Equal(todo.Tags, []string{"home", "work"}) {
+		t.Errorf("Expected sorted, distinct tags, got %v", todo.Tags)
+	}
+	untagged := mustCreate(t, store, CreateTodoInput{Description: "Untagged"})
+	if got := mustRead(t, store, untagged.ID); got.Tags == nil || len(got.Tags) != 0 {
+		t.Errorf("Expected an empty tag list, got %#v", got.Tags)
+	}
+
+	if ok, err := store.AddTagsAsync(ctx, todo.ID, []string{"urgent", "home"}); err != nil || !ok {
+		t.Fatalf("Failed to add tags: %v", err)
+	}
+	if got := mustRead(t, store, todo.ID); !slices.Equal(got.Tags, []string{"home", "urgent", "work"}) {
+		t.Errorf("Expected tags to be added, got %v", got.Tags)
+	}
+
+	if ok, err := store.RemoveTagsAsync(ctx, todo.ID, []string{"home", "missing"}); err != nil || !ok {
+		t.Fatalf("Failed to remove tags: %v", err)
+	}
+	if got := mustRead(t, store, todo.ID); !slices.Equal(got.Tags, []string{"urgent", "work"}) {
+		t.Errorf("Expected home to be removed, got %v", got.Tags)
+	}
+
+	if _, err := store.UpdateTodoAsync(ctx, untagged.ID, UpdateTodoInput{Tags: []string{"work"}}); err != nil {
+		t.Fatalf("Failed to update tags: %v", err)
+	}
+	// A nil tag list leaves the tags alone, and an empty one clears them
+	description := "Renamed"
+	if _, err := store.UpdateTodoAsync(ctx, untagged.ID, UpdateTodoInput{Description: &description}); err != nil {
+		t.Fatalf("Failed to update todo: %v", err)
+	}
+	if got := mustRead(t, store, untagged.ID); !slices.Equal(got.Tags, []string{"work"}) {
+		t.Errorf("Expected the tags to be replaced, got %v", got.Tags)
+	}
+
+	counts, err := store.ListTagsAsync(ctx)
+	if err != nil {
+		t.Fatalf("Failed to list tags: %v", err)
+	}
+	if expected := []TagCount{{"urgent", 1}, {"work", 2}}; !slices.Equal(counts, expected) {
+		t.Errorf("Expected %v, got %v", expected, counts)
+	}
+
+	// Tags no todo carries any more are not listed
+	if _, err := store.UpdateTodoAsync(ctx, todo.ID, UpdateTodoInput{Tags: []string{}}); err != nil {
+		t.Fatalf("Failed to clear tags: %v", err)
+	}
+	if _, err := store.DeleteTodoAsync(ctx, untagged.ID); err != nil {
+		t.Fatalf("Failed to delete todo: %v", err)
+	}
+	if counts, err := store.ListTagsAsync(ctx); err != nil || len(counts) != 0 {
+		t.Errorf("Expected no tags, got %v (%v)", counts, err)
+	}
+
+	if ok, err := store.AddTagsAsync(ctx, 999, []string{"x"}); err != nil || ok {
+		t.Errorf("Expected adding tags to report a missing todo, got %v (%v)", ok, err)
+	}
+	if ok, err := store.RemoveTagsAsync(ctx, 999, []string{"x"}); err != nil || ok {
+		t.Errorf("Expected removing tags to report a missing todo, got %v (%v)", ok, err)
+	}
+}
+
+func testStoreTagFilters(t *testing.T, store TodoStore) {
+	both := mustCreate(t, store, CreateTodoInput{Description: "both", Tags: []string{"home", "work"}})
+	work := mustCreate(t, store, CreateTodoInput{Description: "work", Tags: []string{"work"}})
+	home := mustCreate(t, store, CreateTodoInput{Description: "home", Tags: []string{"home"}})
+	mustCreate(t, store, CreateTodoInput{Description: "none"})
+
+	tests := []struct {
+		name     string
+		query    TodoQuery
+		expected []int
+	}{
+		{"any", TodoQuery{Tags: []string{"work", "home"}}, []int{both.ID, work.ID, home.ID}},
+		{"all", TodoQuery{Tags: []string{"work", "home"}, MatchAllTags: true}, []int{both.ID}},
+		{"all with duplicates", TodoQuery{Tags: []string{"work", "work"}, MatchAllTags: true}, []int{both.ID, work.ID}},
+		{"unknown", TodoQuery{Tags: []string{"garden"}}, []int{}},
+		{"paged", TodoQuery{Tags: []string{"home"}, AfterID: both.ID}, []int{home.ID}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			todos, _, err := store.ListTodosAsync(t.Context(), tt.query)
+			if err != nil {
+				t.Fatalf("Failed to list todos: %v", err)
+			}
+			if !sameIDs(todos, tt.expected...) {
+				t.Errorf("Expected %v, got %v", tt.expected, todoIDs(todos))
+			}
+		})
+	}
+}
+
+func testStoreTagChanges(t *testing.T, store TodoStore) {
+	ctx := t.Context()
+	todo := mustCreate(t, store, CreateTodoInput{Description: "Watched", Tags: []string{"a"}})
+
+	var changes int
+	store.OnChange(func(TodoChange) { changes++ })
+
+	store.AddTagsAsync(ctx, todo.ID, []string{"a"})
+	store.RemoveTagsAsync(ctx, todo.ID, []string{"b"})
+	if changes != 0 {
+		t.Errorf("Expected tag calls that change nothing not to be reported, got %d changes", changes)
+	}
+
+	store.AddTagsAsync(ctx, todo.ID, []string{"b"})
+	store.RemoveTagsAsync(ctx, todo.ID, []string{"a"})
+	if changes != 2 {
+		t.Errorf("Expected 2 changes, got %d", changes)
+	}
+}
+
+func testStoreProjects(t *testing.T, store TodoStore) {
+	ctx := t.Context()
+	created := time.Date(2024, 5, 1, 9, 0, 0, 0, time.UTC)
+	work, err := store.CreateProjectAsync(ctx, CreateProjectInput{Name: "Work", CreatedDate: created})
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	home, err := store.CreateProjectAsync(ctx, CreateProjectInput{Name: "Home", CreatedDate: created})
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	if work.ID <= 0 || home.ID <= work.ID || work.Name != "Work" || work.ArchivedAt != nil {
+		t.Errorf("Unexpected projects: %+v, %+v", work, home)
+	}
+
+	if _, err := store.CreateProjectAsync(ctx, CreateProjectInput{Name: "WORK", CreatedDate: created}); !errors.Is(err, ErrProjectNameTaken) {
+		t.Errorf("Expected ErrProjectNameTaken, got %v", err)
+	}
+
+	archived := time.Date(2024, 6, 1, 9, 0, 0, 0, time.UTC)
+	for _, at := range []time.Time{archived, archived.Add(time.Hour)} {
+		if ok, err := store.ArchiveProjectAsync(ctx, home.ID, at); err != nil || !ok {
+			t.Fatalf("Failed to archive project: %v", err)
+		}
+	}
+	if ok, err := store.ArchiveProjectAsync(ctx, 999, archived); err != nil || ok {
+		t.Errorf("Expected archiving to report a missing project, got %v (%v)", ok, err)
+	}
+
+	projects, err := store.ReadProjectsAsync(ctx)
+	if err != nil {
+		t.Fatalf("Failed to read projects: %v", err)
+	}
+	if len(projects) != 2 || projects[0].ID != work.ID || projects[1].ID != home.ID {
+		t.Fatalf("Expected both projects in id order, got %+v", projects)
+	}
+	if !projects[0].CreatedDate.Equal(created) || projects[0].ArchivedAt != nil {
+		t.Errorf("Unexpected active project: %+v", projects[0])
+	}
+	if projects[1].ArchivedAt == nil || !projects[1].ArchivedAt.Equal(archived) {
+		t.Errorf("Expected the first archive time to be kept, got %+v", projects[1])
+	}
+
+	if one, err := store.ReadProjectsAsync(ctx, home.ID); err != nil || len(one) != 1 || one[0].Name != "Home" {
+		t.Errorf("Expected only Home, got %+v (%v)", one, err)
+	}
+	if none, err := store.ReadProjectsAsync(ctx, 999); err != nil || len(none) != 0 {
+		t.Errorf("Expected no projects, got %+v (%v)", none, err)
+	}
+	// Search skips archived projects and puts id prefix matches first
+	named, err := store.CreateProjectAsync(ctx, CreateProjectInput{Name: "Room " + strconv.Itoa(work.ID) + "0", CreatedDate: created})
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	found, total, err := store.SearchProjectsAsync(ctx, strconv.Itoa(work.ID), 0)
+	if err != nil {
+		t.Fatalf("Failed to search projects: %v", err)
+	}
+	if len(found) != 2 || found[0].ID != work.ID || found[1].ID != named.ID || total != 2 {
+		t.Errorf("Expected Work then %s, got %+v of %d", named.Name, found, total)
+	}
+	if found, total, err = store.SearchProjectsAsync(ctx, "o", 1); err != nil || len(found) != 1 || found[0].ID != work.ID || total != 2 {
+		t.Errorf("Expected Work of 2 open matches, got %+v of %d (%v)", found, total, err)
+	}
+
+	// Only ASCII letters are compared regardless of case
+	team, err := store.CreateProjectAsync(ctx, CreateProjectInput{Name: "Équipe", CreatedDate: created})
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	if _, err := store.CreateProjectAsync(ctx, CreateProjectInput{Name: "ÉQUIPE", CreatedDate: created}); !errors.Is(err, ErrProjectNameTaken) {
+		t.Errorf("Expected ErrProjectNameTaken, got %v", err)
+	}
+	if _, err := store.CreateProjectAsync(ctx, CreateProjectInput{Name: "équipe", CreatedDate: created}); err != nil {
+		t.Errorf("Expected a name differing in a non-ASCII letter to be accepted, got %v", err)
+	}
+	if found, _, err := store.SearchProjectsAsync(ctx, "ÉQUIPE", 0); err != nil || len(found) != 1 || found[0].ID != team.ID {
+		t.Errorf("Expected only %q, got %+v (%v)", team.Name, found, err)
+	}
+}
+
+func testStoreMoveTodos(t *testing.T, store TodoStore) {
+	ctx := t.Context()
+	project, err := store.CreateProjectAsync(ctx, CreateProjectInput{Name: "Garden", CreatedDate: time.Now()})
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	inProject := mustCreate(t, store, CreateTodoInput{Description: "Mow", ProjectID: &project.ID})
+	loose := mustCreate(t, store, CreateTodoInput{Description: "Loose"})
+	if inProject.ProjectID == nil || *inProject.ProjectID != project.ID || loose.ProjectID != nil {
+		t.Errorf("Unexpected projects: %v and %v", inProject.ProjectID, loose.ProjectID)
+	}
+
+	var changes []TodoChange
+	store.OnChange(func(change TodoChange) { changes = append(changes, change) })
+
+	if ok, err := store.MoveTodoAsync(ctx, loose.ID, &project.ID); err != nil || !ok {
+		t.Fatalf("Failed to move todo: %v", err)
+	}
+	store.MoveTodoAsync(ctx, loose.ID, &project.ID)
+	if len(changes) != 1 {
+		t.Fatalf("Expected a move that changes nothing not to be reported, got %v", changes)
+	}
+	if changes[0].PreviousProjectID != nil || changes[0].ProjectID == nil || *changes[0].ProjectID != project.ID {
+		t.Errorf("Expected a move into project %d, got %+v", project.ID, changes[0])
+	}
+
+	todos, _, err := store.ListTodosAsync(ctx, TodoQuery{ProjectID: &project.ID})
+	if err != nil {
+		t.Fatalf("Failed to list todos: %v", err)
+	}
+	if !sameIDs(todos, inProject.ID, loose.ID) {
+		t.Errorf("Expected both todos in the project, got %v", todoIDs(todos))
+	}
+
+	if ok, err := store.MoveTodoAsync(ctx, inProject.ID, nil); err != nil || !ok {
+		t.Fatalf("Failed to move todo out of its project: %v", err)
+	}
+	if got := mustRead(t, store, inProject.ID); got.ProjectID != nil {
+		t.Errorf("Expected no project, got %v", *got.ProjectID)
+	}
+	if change := changes[len(changes)-1]; change.PreviousProjectID == nil || *change.PreviousProjectID != project.ID || change.ProjectID != nil {
+		t.Errorf("Expected a move out of project %d, got %+v", project.ID, change)
+	}
+
+	// Changes that do not move the todo name its project before and after
+	store.CompleteTodoAsync(ctx, loose.ID, time.Now())
+	store.DeleteTodoAsync(ctx, loose.ID)
+	completed, deleted := changes[len(changes)-2], changes[len(changes)-1]
+	if completed.PreviousProjectID == nil || *completed.PreviousProjectID != project.ID || completed.ProjectID == nil || *completed.ProjectID != project.ID {
+		t.Errorf("Expected an update within project %d, got %+v", project.ID, completed)
+	}
+	if deleted.Kind != TodoDeleted || deleted.PreviousProjectID == nil || *deleted.PreviousProjectID != project.ID || deleted.ProjectID != nil {
+		t.Errorf("Expected a deletion from project %d, got %+v", project.ID, deleted)
+	}
+	if ok, err := store.MoveTodoAsync(ctx, 999, nil); err != nil || ok {
+		t.Errorf("Expected moving to report a missing todo, got %v (%v)", ok, err)
+	}
+}
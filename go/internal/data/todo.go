@@ -1,24 +1,93 @@
 package data
 
 import (
+	"slices"
 	"time"
 )
 
-// Todo represents a todo item entity
+// TodoStatus records whether a todo is still to be done
+type TodoStatus string
+
+// Todo statuses
+const (
+	StatusOpen TodoStatus = "open"
+	StatusDone TodoStatus = "done"
+)
+
+// Todo represents a todo item entity. The description and required tags
+// document the JSON shape in tool output schemas.
 type Todo struct {
-	ID          int       `json:"id" db:"id"`
-	Description *string   `json:"description" db:"description"`
-	CreatedDate time.Time `json:"createdDate" db:"created_date"`
+	ID          int        `json:"id" db:"id" description:"Id of the todo" required:"true"`
+	Description *string    `json:"description" db:"description" description:"Description of the todo" required:"true"`
+	CreatedDate time.Time  `json:"createdDate" db:"created_date" description:"Creation date of the todo" required:"true"`
+	Status      TodoStatus `json:"status" db:"status" description:"Whether the todo is open or done" enum:"open,done" required:"true"`
+	CompletedAt *time.Time `json:"completedAt" db:"completed_at" description:"When the todo was completed, or null while it is open" required:"true"`
+	DueDate     *string    `json:"dueDate" db:"due_date" description:"Date the todo is due as YYYY-MM-DD, or null when it has no due date" format:"date" required:"true"`
+	DueTime     *string    `json:"dueTime" db:"due_time" description:"Time of day the todo is due as HH:MM, or null when it is due by the end of the day" required:"true"`
+	DueTimeZone *string    `json:"dueTimeZone" db:"due_time_zone" description:"IANA time zone of the due date, or null when it follows the reader's time zone" required:"true"`
+	Tags        []string   `json:"tags" description:"Tags of the todo, in alphabetical order" required:"true"`
+	ProjectID   *int       `json:"projectId" db:"project_id" description:"Id of the project the todo belongs to, or null when it is in no project" required:"true"`
 }
 
-// CreateTodoInput represents input for creating a new todo
+// CreateTodoInput represents input for creating a new todo. The due fields,
+// tags and project are optional; DueTime and DueTimeZone only apply alongside
+// DueDate.
 type CreateTodoInput struct {
 	Description string    `json:"description"`
 	CreatedDate time.Time `json:"createdDate"`
+	DueDate     *string   `json:"dueDate,omitempty"`
+	DueTime     *string   `json:"dueTime,omitempty"`
+	DueTimeZone *string   `json:"dueTimeZone,omitempty"`
+	Tags        []string  `json:"tags,omitempty"`
+	ProjectID   *int      `json:"projectId,omitempty"`
 }
 
-// UpdateTodoInput represents input for updating an existing todo
+// UpdateTodoInput represents input for updating an existing todo. Setting
+// DueDate replaces the whole due date, including its time and time zone;
+// ClearDue removes it. A non-nil Tags replaces every tag of the todo, so an
+// empty slice removes them all, while nil leaves the tags unchanged.
 type UpdateTodoInput struct {
 	Description *string    `json:"description,omitempty"`
 	CreatedDate *time.Time `json:"createdDate,omitempty"`
-}
\ No newline at end of file
+	DueDate     *string    `json:"dueDate,omitempty"`
+	DueTime     *string    `json:"dueTime,omitempty"`
+	DueTimeZone *string    `json:"dueTimeZone,omitempty"`
+	ClearDue    bool       `json:"clearDue,omitempty"`
+	Tags        []string   `json:"tags,omitempty"`
+}
+
+// TodoQuery selects a page of todos in id order. Only todos with an id greater
+// than AfterID are returned, and at most Limit of them; a Limit of zero or
+// less returns every remaining todo. An empty Status matches todos of any
+// status, and a non-empty DueOnOrBefore (YYYY-MM-DD) keeps only todos with a
+// due date no later than it. A non-empty Tags keeps only todos with any of
+// the tags, or with all of them when MatchAllTags is set. A non-nil ProjectID
+// keeps only the todos in that project.
+type TodoQuery struct {
+	AfterID       int
+	Limit         int
+	Status        TodoStatus
+	DueOnOrBefore string
+	Tags          []string
+	MatchAllTags  bool
+	ProjectID     *int
+}
+
+// TagCount is a tag with the number of todos that carry it
+type TagCount struct {
+	Name  string `json:"name" description:"Name of the tag" required:"true"`
+	Count int    `json:"count" description:"Number of todos with the tag" required:"true"`
+}
+
+// tagSet returns tags without duplicates, in alphabetical order. The result
+// is never nil, so todos without tags encode them as an empty list.
+func tagSet(tags []string) []string {
+	set := make([]string, 0, len(tags))
+	for _, tag := range tags {
+		if !slices.Contains(set, tag) {
+			set = append(set, tag)
+		}
+	}
+	slices.Sort(set)
+	return set
+}
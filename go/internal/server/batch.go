@@ -0,0 +1,105 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+)
+
+// maxBatchConcurrency bounds how many messages of a single batch are dispatched at once
+const maxBatchConcurrency = 8
+
+// Payload errors. JSON-RPC answers malformed JSON with -32700 Parse error and
+// well-formed JSON that is not a request with -32600 Invalid Request.
+var (
+	errMalformedPayload = errors.New("payload is not valid JSON")
+	errInvalidPayload   = errors.New("payload is neither a request object nor a batch")
+)
+
+// decodePayload parses a JSON-RPC payload, which is either a single message or
+// a batch array of messages. It returns errMalformedPayload or
+// errInvalidPayload when the payload is neither. Batch entries that are not
+// valid request objects are reported through invalid so they can be answered
+// with -32600.
+func decodePayload(payload []byte) (requests []MCPRequest, invalid int, isBatch bool, err error) {
+	trimmed := bytes.TrimSpace(payload)
+	if !json.Valid(trimmed) {
+		return nil, 0, false, errMalformedPayload
+	}
+	if trimmed[0] != '[' {
+		var req MCPRequest
+		if trimmed[0] != '{' || json.Unmarshal(trimmed, &req) != nil {
+			return nil, 0, false, errInvalidPayload
+		}
+		return []MCPRequest{req}, 0, false, nil
+	}
+
+	var entries []json.RawMessage
+	if err := json.Unmarshal(trimmed, &entries); err != nil {
+		return nil, 0, true, err
+	}
+
+	for _, entry := range entries {
+		var req MCPRequest
+		entry = bytes.TrimSpace(entry)
+		if len(entry) == 0 || entry[0] != '{' || json.Unmarshal(entry, &req) != nil {
+			invalid++
+			continue
+		}
+		requests = append(requests, req)
+	}
+	return requests, invalid, true, nil
+}
+
+// payloadErrorResponse answers a payload that decodePayload rejected
+func payloadErrorResponse(err error) *MCPResponse {
+	if errors.Is(err, errInvalidPayload) {
+		return newErrorResponse(nil, -32600, "Invalid Request", nil)
+	}
+	return newErrorResponse(nil, -32700, "Parse error", nil)
+}
+
+// handleBatch dispatches every message of a batch concurrently and returns the
+// responses in completion order. Notifications contribute no response.
+func (s *MCPServer) handleBatch(ctx context.Context, sess *session, requests []MCPRequest, invalid int) []*MCPResponse {
+	var (
+		mu        sync.Mutex
+		wg        sync.WaitGroup
+		responses []*MCPResponse
+	)
+
+	for i := 0; i < invalid; i++ {
+		responses = append(responses, newErrorResponse(nil, -32600, "Invalid Request", nil))
+	}
+
+	slots := make(chan struct{}, maxBatchConcurrency)
+	for _, req := range requests {
+		if req.Method == "initialize" {
+			// Initialization must be the first exchange of a session and cannot be batched
+			mu.Lock()
+			responses = append(responses, newErrorResponse(req.ID, -32600, "Invalid Request", "initialize cannot be part of a batch"))
+			mu.Unlock()
+			continue
+		}
+
+		wg.Add(1)
+		slots <- struct{}{}
+		go func(req MCPRequest) {
+			defer wg.Done()
+			defer func() { <-slots }()
+
+			response := s.handleMessage(ctx, sess, req)
+			if response == nil {
+				return
+			}
+			mu.Lock()
+			responses = append(responses, response)
+			mu.Unlock()
+		}(req)
+	}
+	wg.Wait()
+
+	return responses
+}
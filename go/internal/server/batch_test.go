@@ -0,0 +1,117 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+)
+
+func TestBatch_MixedRequestsAndNotifications(t *testing.T) {
+	s := createTestServer(t)
+
+	var entries []string
+	for i := 1; i <= 5; i++ {
+		entries = append(entries, fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":"tools/call","params":{"name":"create_todo","arguments":{"description":"Batch todo %d","createdDate":"2024-01-01T10:00:00Z"}}}`, i, i))
+	}
+	entries = append(entries, `{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+
+	rec := postMCP(t, s, "["+strings.Join(entries, ",")+"]")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+
+	var responses []MCPResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &responses); err != nil {
+		t.Fatalf("Expected array response, got %q: %v", rec.Body.String(), err)
+	}
+	if len(responses) != 5 {
+		t.Fatalf("Expected 5 responses, got %d", len(responses))
+	}
+
+	seen := map[float64]bool{}
+	for _, resp := range responses {
+		if resp.Error != nil {
+			t.Errorf("Unexpected error for id %v: %+v", resp.ID, resp.Error)
+		}
+		seen[resp.ID.(float64)] = true
+	}
+	for i := 1; i <= 5; i++ {
+		if !seen[float64(i)] {
+			t.Errorf("Missing response for id %d", i)
+		}
+	}
+
+	todos, err := s.todosTool.ReadTodosAsync(t.Context(), nil)
+	if err != nil {
+		t.Fatalf("ReadTodosAsync failed: %v", err)
+	}
+	if len(todos) != 5 {
+		t.Errorf("Expected 5 todos after batch, got %d", len(todos))
+	}
+}
+
+func TestBatch_OnlyNotifications(t *testing.T) {
+	s := createTestServer(t)
+
+	rec := postMCP(t, s, `[{"jsonrpc":"2.0","method":"notifications/initialized"}]`)
+	if rec.Code != http.StatusAccepted {
+		t.Errorf("Expected status 202, got %d", rec.Code)
+	}
+	if rec.Body.Len() != 0 {
+		t.Errorf("Expected empty body, got %q", rec.Body.String())
+	}
+}
+
+func TestBatch_EmptyArray(t *testing.T) {
+	s := createTestServer(t)
+
+	rec := postMCP(t, s, `[]`)
+	resp := decodeResponse(t, rec)
+	if resp.Error == nil || resp.Error.Code != -32600 {
+		t.Errorf("Expected -32600 for empty batch, got %+v", resp.Error)
+	}
+}
+
+func TestBatch_InvalidEntries(t *testing.T) {
+	s := createTestServer(t)
+
+	rec := postMCP(t, s, `[1, {"jsonrpc":"2.0","id":2,"method":"ping"}, {"jsonrpc":"2.0","id":3,"method":"initialize","params":{}}]`)
+
+	var responses []MCPResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &responses); err != nil {
+		t.Fatalf("Expected array response, got %q: %v", rec.Body.String(), err)
+	}
+	if len(responses) != 3 {
+		t.Fatalf("Expected 3 responses, got %d", len(responses))
+	}
+
+	invalid := 0
+	for _, resp := range responses {
+		if resp.Error != nil && resp.Error.Code == -32600 {
+			invalid++
+		}
+	}
+	if invalid != 2 {
+		t.Errorf("Expected 2 invalid request errors, got %d", invalid)
+	}
+}
+
+func TestBatch_Stdio(t *testing.T) {
+	s := createTestServer(t)
+
+	var out strings.Builder
+	input := `[{"jsonrpc":"2.0","id":1,"method":"ping"},{"jsonrpc":"2.0","id":2,"method":"ping"}]` + "\n"
+	if err := s.ServeStdio(t.Context(), strings.NewReader(input), &out); err != nil {
+		t.Fatalf("ServeStdio failed: %v", err)
+	}
+
+	var responses []MCPResponse
+	if err := json.Unmarshal([]byte(out.String()), &responses); err != nil {
+		t.Fatalf("Expected array response line, got %q: %v", out.String(), err)
+	}
+	if len(responses) != 2 {
+		t.Errorf("Expected 2 responses, got %d", len(responses))
+	}
+}
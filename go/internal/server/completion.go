@@ -0,0 +1,136 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/matpadley/MCPServer_Demo/go/internal/tools"
+)
+
+// Completion reference types. ref/tool is an extension of the MCP
+// specification, which only defines completions for prompts and resources.
+const (
+	refPrompt   = "ref/prompt"
+	refResource = "ref/resource"
+	refTool     = "ref/tool"
+)
+
+// CompletionReference identifies the prompt, resource template or tool being completed
+type CompletionReference struct {
+	Type string `json:"type"`
+	Name string `json:"name,omitempty"`
+	URI  string `json:"uri,omitempty"`
+}
+
+// CompletionArgument is the argument being completed and its partial value
+type CompletionArgument struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// CompleteParams represents the params of a completion/complete request
+type CompleteParams struct {
+	Ref      CompletionReference `json:"ref"`
+	Argument CompletionArgument  `json:"argument"`
+}
+
+// Completion is a ranked, capped list of suggested argument values
+type Completion struct {
+	Values  []string `json:"values"`
+	Total   int      `json:"total"`
+	HasMore bool     `json:"hasMore"`
+}
+
+// CompletionsCapability declares support for completion/complete
+type CompletionsCapability struct{}
+
+// handleComplete suggests values for a prompt argument, a resource template
+// variable or a tool argument
+func (s *MCPServer) handleComplete(ctx context.Context, sess *session, req MCPRequest) (interface{}, *MCPError) {
+	var params CompleteParams
+	if err := json.Unmarshal(req.Params, &params); err != nil || params.Ref.Type == "" || params.Argument.Name == "" {
+		return nil, newMCPError(-32602, "Invalid params", nil)
+	}
+
+	values, total, mcpErr := s.completionValues(ctx, sess, params.Ref, params.Argument)
+	if mcpErr != nil {
+		return nil, mcpErr
+	}
+
+	return map[string]interface{}{
+		"completion": newCompletion(values, total),
+	}, nil
+}
+
+// completionValues resolves the reference and returns the ranked suggestions
+// for the argument along with the number of values that match
+func (s *MCPServer) completionValues(ctx context.Context, sess *session, ref CompletionReference, argument CompletionArgument) ([]string, int, *MCPError) {
+	var (
+		values []string
+		total  int
+		err    error
+	)
+
+	switch ref.Type {
+	case refPrompt:
+		prompt, ok := lookupPrompt(ref.Name)
+		if !ok {
+			return nil, 0, newMCPError(-32602, fmt.Sprintf("Unknown prompt: %s", ref.Name), nil)
+		}
+		if complete, ok := prompt.completions[argument.Name]; ok {
+			values, total, err = complete(ctx, s, argument.Value)
+		}
+	case refResource:
+		switch {
+		case ref.URI == todoURITemplate && argument.Name == "id":
+			values, total, err = completeTodoIDs(ctx, s, argument.Value)
+		case ref.URI == projectTemplate && argument.Name == "id":
+			values, total, err = completeProjectIDs(ctx, s, argument.Value)
+		case ref.URI != todoURITemplate && ref.URI != projectTemplate:
+			return nil, 0, newMCPError(-32602, fmt.Sprintf("Unknown resource template: %s", ref.URI), nil)
+		}
+	case refTool:
+		tool, ok := s.registry.Lookup(ref.Name)
+		if !ok {
+			return nil, 0, newMCPError(-32602, fmt.Sprintf("Unknown tool: %s", ref.Name), nil)
+		}
+		if completer, ok := tool.(tools.Completer); ok {
+			values, total, err = completer.Complete(ctx, argument.Name, argument.Value)
+		}
+	default:
+		return nil, 0, newMCPError(-32602, fmt.Sprintf("Unsupported reference type: %s", ref.Type), nil)
+	}
+
+	if err != nil {
+		s.logEvent(sess, LevelError, "completion", "Failed to complete argument", "ref", ref.Type, "argument", argument.Name, "error", err)
+		return nil, 0, newMCPError(-32603, "Internal error", nil)
+	}
+	return values, total, nil
+}
+
+// completeTodoIDs suggests todo ids drawn from the database
+func completeTodoIDs(ctx context.Context, s *MCPServer, value string) ([]string, int, error) {
+	return s.todosTool.CompleteTodoIDs(ctx, value)
+}
+
+// completeProjectIDs suggests ids of projects that accept new todos
+func completeProjectIDs(ctx context.Context, s *MCPServer, value string) ([]string, int, error) {
+	return s.todosTool.CompleteProjectIDs(ctx, value)
+}
+
+// newCompletion caps ranked values at tools.MaxCompletionValues, reporting
+// the number of values that match
+func newCompletion(values []string, total int) Completion {
+	if values == nil {
+		values = []string{}
+	}
+	if len(values) > tools.MaxCompletionValues {
+		values = values[:tools.MaxCompletionValues]
+	}
+	return Completion{
+		Values:  values,
+		Total:   total,
+		HasMore: total > len(values),
+	}
+}
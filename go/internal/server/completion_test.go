@@ -0,0 +1,119 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/matpadley/MCPServer_Demo/go/internal/tools"
+)
+
+func complete(t *testing.T, s *MCPServer, ref, argument string) MCPResponse {
+	return decodeResponse(t, postMCP(t, s, `{"jsonrpc":"2.0","id":1,"method":"completion/complete","params":{"ref":`+ref+`,"argument":`+argument+`}}`))
+}
+
+func completionOf(t *testing.T, resp MCPResponse) (values []string, total int, hasMore bool) {
+	if resp.Error != nil {
+		t.Fatalf("Unexpected error: %+v", resp.Error)
+	}
+	completion := resp.Result.(map[string]interface{})["completion"].(map[string]interface{})
+	for _, value := range completion["values"].([]interface{}) {
+		values = append(values, value.(string))
+	}
+	return values, int(completion["total"].(float64)), completion["hasMore"].(bool)
+}
+
+func TestComplete_TodoIDs(t *testing.T) {
+	s := createTestServer(t)
+	seedTodos(t, s, "Buy milk", "Pay rent", "Water plants", "Walk dog", "Wash car", "Call plumber at 1pm",
+		"Book flights", "Renew passport", "Clean oven", "Mow lawn", "File taxes", "Fix 1 leak")
+
+	refs := []string{
+		`{"type":"ref/tool","name":"update_todo"}`,
+		`{"type":"ref/tool","name":"delete_todo"}`,
+		`{"type":"ref/prompt","name":"break_down_todo"}`,
+		`{"type":"ref/resource","uri":"todo://{id}"}`,
+	}
+	for _, ref := range refs {
+		values, total, hasMore := completionOf(t, complete(t, s, ref, `{"name":"id","value":"1"}`))
+		// Id prefix matches first, then the description match
+		expected := "1,10,11,12,6"
+		if strings.Join(values, ",") != expected || total != 5 || hasMore {
+			t.Errorf("%s: expected %s, got %v (total %d, hasMore %v)", ref, expected, values, total, hasMore)
+		}
+	}
+
+	values, _, _ := completionOf(t, complete(t, s, refs[0], `{"name":"id","value":"plant"}`))
+	if strings.Join(values, ",") != "3" {
+		t.Errorf("Expected description match 3, got %v", values)
+	}
+}
+
+func TestComplete_ProjectIDs(t *testing.T) {
+	s := createTestServer(t)
+	for _, name := range []string{"Garden", "Work", "House"} {
+		if _, err := s.todosTool.CreateProject(t.Context(), name); err != nil {
+			t.Fatalf("Failed to create project: %v", err)
+		}
+	}
+	if _, err := s.todosTool.ArchiveProject(t.Context(), "2"); err != nil {
+		t.Fatalf("Failed to archive project: %v", err)
+	}
+
+	refs := []string{
+		`{"type":"ref/tool","name":"move_todo"}`,
+		`{"type":"ref/resource","uri":"project://{id}/todos"}`,
+	}
+	for _, ref := range refs {
+		argument := `{"name":"projectId","value":"o"}`
+		if strings.Contains(ref, "resource") {
+			argument = `{"name":"id","value":"o"}`
+		}
+		// Archived projects are not suggested
+		values, _, _ := completionOf(t, complete(t, s, ref, argument))
+		if strings.Join(values, ",") != "3" {
+			t.Errorf("%s: expected 3, got %v", ref, values)
+		}
+	}
+}
+
+func TestComplete_Capped(t *testing.T) {
+	s := createTestServer(t)
+	var descriptions []string
+	for i := 0; i < tools.MaxCompletionValues+5; i++ {
+		descriptions = append(descriptions, fmt.Sprintf("Todo %d", i))
+	}
+	seedTodos(t, s, descriptions...)
+
+	values, total, hasMore := completionOf(t, complete(t, s, `{"type":"ref/tool","name":"read_todos"}`, `{"name":"id","value":""}`))
+	if len(values) != tools.MaxCompletionValues || total != tools.MaxCompletionValues+5 || !hasMore {
+		t.Errorf("Expected %d values of %d with more, got %d of %d (hasMore %v)", tools.MaxCompletionValues, tools.MaxCompletionValues+5, len(values), total, hasMore)
+	}
+}
+
+func TestComplete_NoSuggestions(t *testing.T) {
+	s := createTestServer(t)
+	seedTodos(t, s, "Only")
+
+	values, total, hasMore := completionOf(t, complete(t, s, `{"type":"ref/prompt","name":"plan_my_day"}`, `{"name":"focus","value":"w"}`))
+	if len(values) != 0 || total != 0 || hasMore {
+		t.Errorf("Expected no suggestions, got %v", values)
+	}
+}
+
+func TestComplete_UnknownReference(t *testing.T) {
+	s := createTestServer(t)
+
+	refs := []string{
+		`{"type":"ref/tool","name":"missing"}`,
+		`{"type":"ref/prompt","name":"missing"}`,
+		`{"type":"ref/resource","uri":"file:///{path}"}`,
+		`{"type":"ref/unknown"}`,
+	}
+	for _, ref := range refs {
+		resp := complete(t, s, ref, `{"name":"id","value":""}`)
+		if resp.Error == nil || resp.Error.Code != -32602 {
+			t.Errorf("%s: expected -32602, got %+v", ref, resp.Error)
+		}
+	}
+}
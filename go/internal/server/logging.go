@@ -0,0 +1,122 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"time"
+)
+
+// defaultSlowCallThreshold is how long a request may take before it is reported as slow
+const defaultSlowCallThreshold = 500 * time.Millisecond
+
+// LoggingLevel is an RFC 5424 syslog severity, as used by MCP logging
+type LoggingLevel string
+
+// Logging levels, from least to most severe
+const (
+	LevelDebug     LoggingLevel = "debug"
+	LevelInfo      LoggingLevel = "info"
+	LevelNotice    LoggingLevel = "notice"
+	LevelWarning   LoggingLevel = "warning"
+	LevelError     LoggingLevel = "error"
+	LevelCritical  LoggingLevel = "critical"
+	LevelAlert     LoggingLevel = "alert"
+	LevelEmergency LoggingLevel = "emergency"
+)
+
+// loggingLevelSeverity orders the logging levels; higher is more severe
+var loggingLevelSeverity = map[LoggingLevel]int{
+	LevelDebug:     0,
+	LevelInfo:      1,
+	LevelNotice:    2,
+	LevelWarning:   3,
+	LevelError:     4,
+	LevelCritical:  5,
+	LevelAlert:     6,
+	LevelEmergency: 7,
+}
+
+// valid reports whether the level is one MCP defines
+func (l LoggingLevel) valid() bool {
+	_, ok := loggingLevelSeverity[l]
+	return ok
+}
+
+// atLeast reports whether l is as severe as min
+func (l LoggingLevel) atLeast(min LoggingLevel) bool {
+	return loggingLevelSeverity[l] >= loggingLevelSeverity[min]
+}
+
+// slogLevel maps the level onto the nearest slog level
+func (l LoggingLevel) slogLevel() slog.Level {
+	switch {
+	case l.atLeast(LevelError):
+		return slog.LevelError
+	case l.atLeast(LevelWarning):
+		return slog.LevelWarn
+	case l.atLeast(LevelInfo):
+		return slog.LevelInfo
+	default:
+		return slog.LevelDebug
+	}
+}
+
+// SetLevelParams represents the params of a logging/setLevel request
+type SetLevelParams struct {
+	Level LoggingLevel `json:"level"`
+}
+
+// LoggingMessageParams represents the params of a notifications/message notification
+type LoggingMessageParams struct {
+	Level  LoggingLevel `json:"level"`
+	Logger string       `json:"logger,omitempty"`
+	Data   interface{}  `json:"data"`
+}
+
+// LoggingCapability declares support for logging/setLevel
+type LoggingCapability struct{}
+
+// handleSetLevel sets the minimum level of log messages sent to the session
+func (s *MCPServer) handleSetLevel(sess *session, req MCPRequest) (interface{}, *MCPError) {
+	var params SetLevelParams
+	if err := json.Unmarshal(req.Params, &params); err != nil || !params.Level.valid() {
+		return nil, newMCPError(-32602, "Invalid params", "level must be one of debug, info, notice, warning, error, critical, alert or emergency")
+	}
+
+	sess.setLogLevel(params.Level)
+	return map[string]interface{}{}, nil
+}
+
+// logEvent records a server event in the process log and, when the session's
+// level admits it, forwards it to the client as notifications/message. attrs
+// are alternating keys and values, as for slog.
+func (s *MCPServer) logEvent(sess *session, level LoggingLevel, logger, message string, attrs ...interface{}) {
+	s.logger.Log(context.Background(), level.slogLevel(), message, append([]interface{}{"logger", logger}, attrs...)...)
+
+	if sess == nil || !sess.logEnabled(level) {
+		return
+	}
+
+	data := map[string]interface{}{"message": message}
+	for i := 0; i+1 < len(attrs); i += 2 {
+		key, ok := attrs[i].(string)
+		if !ok {
+			continue
+		}
+		value := attrs[i+1]
+		switch v := value.(type) {
+		case error:
+			value = v.Error()
+		case time.Duration:
+			value = v.String()
+		}
+		data[key] = value
+	}
+
+	sess.send(newNotification("notifications/message", LoggingMessageParams{
+		Level:  level,
+		Logger: logger,
+		Data:   data,
+	}))
+}
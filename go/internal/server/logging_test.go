@@ -0,0 +1,97 @@
+package server
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func loggingSession(t *testing.T, s *MCPServer, level string) (*session, <-chan interface{}) {
+	sess, err := s.createSession()
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+	messages, _ := sess.attachStream()
+
+	if level != "" {
+		resp := s.handleMessage(t.Context(), sess, MCPRequest{JSONRPC: "2.0", ID: json.RawMessage(`1`), Method: "logging/setLevel", Params: json.RawMessage(`{"level":"` + level + `"}`)})
+		if resp.Error != nil {
+			t.Fatalf("Failed to set level %s: %+v", level, resp.Error)
+		}
+	}
+	return sess, messages
+}
+
+func logMessages(messages <-chan interface{}) []LoggingMessageParams {
+	var logs []LoggingMessageParams
+	for _, notification := range drainNotifications(messages) {
+		if notification.Method == "notifications/message" {
+			logs = append(logs, notification.Params.(LoggingMessageParams))
+		}
+	}
+	return logs
+}
+
+func callInvalidTool(t *testing.T, s *MCPServer, sess *session) {
+	s.handleMessage(t.Context(), sess, MCPRequest{JSONRPC: "2.0", ID: json.RawMessage(`2`), Method: "tools/call", Params: json.RawMessage(`{"name":"delete_todo","arguments":{}}`)})
+}
+
+func TestLogging_ValidationFailure(t *testing.T) {
+	s := createTestServer(t)
+	sess, messages := loggingSession(t, s, "warning")
+
+	callInvalidTool(t, s, sess)
+
+	logs := logMessages(messages)
+	if len(logs) != 1 {
+		t.Fatalf("Expected 1 log message, got %v", logs)
+	}
+	if logs[0].Level != LevelWarning || logs[0].Logger != "tools" {
+		t.Errorf("Expected warning from tools, got %+v", logs[0])
+	}
+	data := logs[0].Data.(map[string]interface{})
+	if data["tool"] != "delete_todo" || data["message"] != "Invalid tool arguments" {
+		t.Errorf("Unexpected log data: %v", data)
+	}
+}
+
+func TestLogging_LevelFilters(t *testing.T) {
+	s := createTestServer(t)
+
+	sess, messages := loggingSession(t, s, "error")
+	callInvalidTool(t, s, sess)
+	if logs := logMessages(messages); len(logs) != 0 {
+		t.Errorf("Expected warnings to be filtered at level error, got %v", logs)
+	}
+
+	sess, messages = loggingSession(t, s, "")
+	callInvalidTool(t, s, sess)
+	if logs := logMessages(messages); len(logs) != 0 {
+		t.Errorf("Expected no log messages before logging/setLevel, got %v", logs)
+	}
+}
+
+func TestLogging_SlowRequest(t *testing.T) {
+	s := createTestServer(t)
+	s.slowCallThreshold = -1
+	sess, messages := loggingSession(t, s, "warning")
+
+	s.handleMessage(t.Context(), sess, MCPRequest{JSONRPC: "2.0", ID: json.RawMessage(`2`), Method: "ping"})
+
+	logs := logMessages(messages)
+	if len(logs) == 0 {
+		t.Fatal("Expected a slow request warning")
+	}
+	data := logs[len(logs)-1].Data.(map[string]interface{})
+	if data["message"] != "Slow request" || data["method"] != "ping" {
+		t.Errorf("Unexpected log data: %v", data)
+	}
+}
+
+func TestLogging_SetLevelInvalid(t *testing.T) {
+	s := createTestServer(t)
+
+	resp := decodeResponse(t, postMCP(t, s, `{"jsonrpc":"2.0","id":1,"method":"logging/setLevel","params":{"level":"verbose"}}`))
+	if resp.Error == nil || resp.Error.Code != -32602 {
+		t.Errorf("Expected -32602 for unknown level, got %+v", resp.Error)
+	}
+}
@@ -1,33 +1,161 @@
 package server
 
 import (
+	"context"
 	"encoding/json"
-	"log"
-	"net/http"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
 	"time"
 
 	"github.com/matpadley/MCPServer_Demo/go/internal/data"
 	"github.com/matpadley/MCPServer_Demo/go/internal/tools"
 )
 
+// LatestProtocolVersion is the newest MCP protocol revision this server speaks
+const LatestProtocolVersion = "2025-06-18"
+
+// supportedProtocolVersions lists every MCP revision the server can negotiate, newest first
+var supportedProtocolVersions = []string{
+	LatestProtocolVersion,
+	"2025-03-26",
+	"2024-11-05",
+}
+
+// DefaultCallTimeout is how long a single request may run unless changed with SetCallTimeout
+const DefaultCallTimeout = 30 * time.Second
+
+// Server identity reported to clients during initialization
+const (
+	serverName    = "mcpserver-go"
+	serverVersion = "1.0.0"
+)
+
 // MCPServer provides MCP protocol endpoints
 type MCPServer struct {
+	db        data.TodoStore
 	todosTool *tools.TodosMcpTool
+	registry  *tools.Registry
+	logger    *slog.Logger
+
+	// slowCallThreshold is how long a request may take before a warning is logged
+	slowCallThreshold time.Duration
+	// callTimeout bounds how long a single request may run
+	callTimeout time.Duration
+	// pageSize is the number of items in each page of a list method
+	pageSize int
+
+	// sessionIdleTimeout is how long a session may go unused before it is ended
+	sessionIdleTimeout time.Duration
+	// maxSessions is how many sessions may be live at once
+	maxSessions int
+
+	sessionsMu sync.Mutex
+	sessions   map[string]*session
+
+	// changesMu guards batches and heldChanges
+	changesMu sync.Mutex
+	// batches counts the tool calls holding back change notifications
+	batches int
+	// heldChanges collects the notifications held back while batches is above zero
+	heldChanges heldChanges
+
+	reaperOnce sync.Once
+	closed     chan struct{}
+	closeOnce  sync.Once
 }
 
 // NewMCPServer creates a new MCP server instance
-func NewMCPServer(db *data.DatabaseContext) *MCPServer {
-	return &MCPServer{
+func NewMCPServer(db data.TodoStore) *MCPServer {
+	s := &MCPServer{
+		db:        db,
 		todosTool: tools.NewTodosMcpTool(db),
+		registry:  tools.NewRegistry(),
+		logger:    slog.Default(),
+		sessions:  make(map[string]*session),
+		closed:    make(chan struct{}),
+
+		slowCallThreshold: defaultSlowCallThreshold,
+		callTimeout:       DefaultCallTimeout,
+		pageSize:          DefaultPageSize,
+
+		sessionIdleTimeout: DefaultSessionIdleTimeout,
+		maxSessions:        DefaultMaxSessions,
 	}
+
+	db.OnChange(s.handleTodoChange)
+
+	for _, tool := range s.todosTool.Tools() {
+		if err := s.RegisterTool(tool); err != nil {
+			// Built-in tool names are unique, so this only fires on a programming error
+			panic(err)
+		}
+	}
+
+	return s
 }
 
-// MCPRequest represents an MCP JSON-RPC request
+// SetCallTimeout sets how long a single request may run before it is abandoned
+// and answered with a timeout error
+func (s *MCPServer) SetCallTimeout(timeout time.Duration) {
+	s.callTimeout = timeout
+}
+
+// SetSessionLimits sets how long a session may go unused before it is ended
+// and how many sessions may be live at once. Both must be positive, and they
+// must be set before the first session is created.
+func (s *MCPServer) SetSessionLimits(idleTimeout time.Duration, maxSessions int) {
+	s.sessionIdleTimeout = idleTimeout
+	s.maxSessions = maxSessions
+}
+
+// SetPageSize sets how many items tools/list, resources/list,
+// resources/templates/list and prompts/list return per page. size must be at least 1.
+func (s *MCPServer) SetPageSize(size int) {
+	s.pageSize = size
+}
+
+// MCPRequest represents an MCP JSON-RPC request or notification
 type MCPRequest struct {
-	JSONRPC string      `json:"jsonrpc"`
-	ID      interface{} `json:"id"`
-	Method  string      `json:"method"`
-	Params  interface{} `json:"params,omitempty"`
+	JSONRPC string          `json:"jsonrpc"`
+	ID      json.RawMessage `json:"id,omitempty"`
+	Method  string          `json:"method"`
+	Params  json.RawMessage `json:"params,omitempty"`
+}
+
+// isNotification reports whether the message omits an id and so expects no response
+func (req MCPRequest) isNotification() bool {
+	return len(req.ID) == 0
+}
+
+// validate checks the message against the JSON-RPC 2.0 envelope rules, which
+// MCP tightens by requiring request ids to be non-null strings or numbers
+func (req MCPRequest) validate() *MCPError {
+	if req.JSONRPC != "2.0" {
+		return newMCPError(-32600, "Invalid Request", `jsonrpc must be exactly "2.0"`)
+	}
+	if req.Method == "" {
+		return newMCPError(-32600, "Invalid Request", "method is required")
+	}
+	if !req.isNotification() && !isValidID(req.ID) {
+		return newMCPError(-32600, "Invalid Request", "id must be a string or number")
+	}
+	return nil
+}
+
+// isValidID reports whether a raw JSON-RPC id is a string or number
+func isValidID(id json.RawMessage) bool {
+	var value interface{}
+	if err := json.Unmarshal(id, &value); err != nil {
+		return false
+	}
+	switch value.(type) {
+	case string, float64:
+		return true
+	default:
+		return false
+	}
 }
 
 // MCPResponse represents an MCP JSON-RPC response
@@ -38,320 +166,309 @@ type MCPResponse struct {
 	Error   *MCPError   `json:"error,omitempty"`
 }
 
+// MCPNotification represents a server-to-client JSON-RPC notification
+type MCPNotification struct {
+	JSONRPC string      `json:"jsonrpc"`
+	Method  string      `json:"method"`
+	Params  interface{} `json:"params,omitempty"`
+}
+
 // MCPError represents an MCP JSON-RPC error
 type MCPError struct {
-	Code    int    `json:"code"`
-	Message string `json:"message"`
+	Code    int         `json:"code"`
+	Message string      `json:"message"`
 	Data    interface{} `json:"data,omitempty"`
 }
 
+// CancelledParams represents the params of a notifications/cancelled notification
+type CancelledParams struct {
+	RequestID json.RawMessage `json:"requestId"`
+	Reason    string          `json:"reason,omitempty"`
+}
+
 // ToolRequest represents a tool invocation request
 type ToolRequest struct {
 	Name      string                 `json:"name"`
 	Arguments map[string]interface{} `json:"arguments,omitempty"`
+	Meta      *RequestMeta           `json:"_meta,omitempty"`
 }
 
-// HandleMCP handles MCP protocol requests
-func (s *MCPServer) HandleMCP(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
-	w.Header().Set("Access-Control-Allow-Origin", "*")
-	w.Header().Set("Access-Control-Allow-Methods", "POST, OPTIONS")
-	w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+// Implementation identifies an MCP client or server
+type Implementation struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
 
-	if r.Method == "OPTIONS" {
-		w.WriteHeader(http.StatusOK)
-		return
-	}
+// InitializeParams represents the params of an initialize request
+type InitializeParams struct {
+	ProtocolVersion string                 `json:"protocolVersion"`
+	Capabilities    map[string]interface{} `json:"capabilities"`
+	ClientInfo      Implementation         `json:"clientInfo"`
+}
+
+// InitializeResult represents the result of an initialize request
+type InitializeResult struct {
+	ProtocolVersion string             `json:"protocolVersion"`
+	Capabilities    ServerCapabilities `json:"capabilities"`
+	ServerInfo      Implementation     `json:"serverInfo"`
+	Instructions    string             `json:"instructions,omitempty"`
+}
 
-	if r.Method != "POST" {
-		w.WriteHeader(http.StatusMethodNotAllowed)
-		return
+// ServerCapabilities declares the optional MCP features the server supports
+type ServerCapabilities struct {
+	Tools       *ToolsCapability       `json:"tools,omitempty"`
+	Resources   *ResourcesCapability   `json:"resources,omitempty"`
+	Prompts     *PromptsCapability     `json:"prompts,omitempty"`
+	Completions *CompletionsCapability `json:"completions,omitempty"`
+	Logging     *LoggingCapability     `json:"logging,omitempty"`
+}
+
+// ToolsCapability describes the server's tools support
+type ToolsCapability struct {
+	ListChanged bool `json:"listChanged"`
+}
+
+// handleMessage dispatches a single JSON-RPC message independently of the
+// transport it arrived on. ctx ends when the transport gives up on the request,
+// for example when an HTTP client disconnects. It returns nil when the message
+// needs no response, including requests cancelled before they complete.
+func (s *MCPServer) handleMessage(ctx context.Context, sess *session, req MCPRequest) *MCPResponse {
+	if mcpErr := req.validate(); mcpErr != nil {
+		var id interface{}
+		if isValidID(req.ID) {
+			id = req.ID
+		}
+		return &MCPResponse{JSONRPC: "2.0", ID: id, Error: mcpErr}
 	}
 
-	var req MCPRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		s.sendError(w, req.ID, -32700, "Parse error", nil)
-		return
+	if req.isNotification() {
+		s.handleNotification(sess, req)
+		return nil
 	}
 
+	ctx, cancel := context.WithTimeout(ctx, s.callTimeout)
+	defer cancel()
+	sess.trackRequest(req.ID, cancel)
+	defer sess.untrackRequest(req.ID)
+
+	var result interface{}
+	var mcpErr *MCPError
+
+	start := time.Now()
+	defer func() {
+		if elapsed := time.Since(start); elapsed > s.slowCallThreshold {
+			s.logEvent(sess, LevelWarning, "server", "Slow request", "method", req.Method, "duration", elapsed)
+		}
+	}()
+
 	switch req.Method {
+	case "initialize":
+		result, mcpErr = s.handleInitialize(sess, req)
+	case "ping":
+		result = map[string]interface{}{}
 	case "tools/list":
-		s.handleToolsList(w, req)
+		result, mcpErr = s.handleToolsList(req)
 	case "tools/call":
-		s.handleToolsCall(w, req)
+		result, mcpErr = s.handleToolsCall(ctx, sess, req)
+	case "resources/list":
+		result, mcpErr = s.handleResourcesList(ctx, sess, req)
+	case "resources/templates/list":
+		result, mcpErr = s.handleResourceTemplatesList(req)
+	case "resources/read":
+		result, mcpErr = s.handleResourcesRead(ctx, sess, req)
+	case "resources/subscribe":
+		result, mcpErr = s.handleResourcesSubscribe(ctx, sess, req)
+	case "resources/unsubscribe":
+		result, mcpErr = s.handleResourcesUnsubscribe(sess, req)
+	case "prompts/list":
+		result, mcpErr = s.handlePromptsList(req)
+	case "prompts/get":
+		result, mcpErr = s.handlePromptsGet(ctx, sess, req)
+	case "completion/complete":
+		result, mcpErr = s.handleComplete(ctx, sess, req)
+	case "logging/setLevel":
+		result, mcpErr = s.handleSetLevel(sess, req)
 	default:
-		s.sendError(w, req.ID, -32601, "Method not found", nil)
+		mcpErr = newMCPError(-32601, "Method not found", nil)
 	}
-}
 
-// handleToolsList returns the list of available MCP tools
-func (s *MCPServer) handleToolsList(w http.ResponseWriter, req MCPRequest) {
-	tools := []map[string]interface{}{
-		{
-			"name":        "create_todo",
-			"description": "Creates a new todo with a description and creation date.",
-			"inputSchema": map[string]interface{}{
-				"type": "object",
-				"properties": map[string]interface{}{
-					"description": map[string]interface{}{
-						"type":        "string",
-						"description": "Description of the todo",
-					},
-					"createdDate": map[string]interface{}{
-						"type":        "string",
-						"format":      "date-time",
-						"description": "Creation date of the todo",
-					},
-				},
-				"required": []string{"description", "createdDate"},
-			},
-		},
-		{
-			"name":        "read_todos",
-			"description": "Reads all todos, or a single todo if an id is provided.",
-			"inputSchema": map[string]interface{}{
-				"type": "object",
-				"properties": map[string]interface{}{
-					"id": map[string]interface{}{
-						"type":        "string",
-						"description": "Id of the todo to read (optional)",
-					},
-				},
-			},
-		},
-		{
-			"name":        "update_todo",
-			"description": "Updates the specified todo fields by id.",
-			"inputSchema": map[string]interface{}{
-				"type": "object",
-				"properties": map[string]interface{}{
-					"id": map[string]interface{}{
-						"type":        "string",
-						"description": "Id of the todo to update",
-					},
-					"description": map[string]interface{}{
-						"type":        "string",
-						"description": "New description (optional)",
-					},
-					"createdDate": map[string]interface{}{
-						"type":        "string",
-						"format":      "date-time",
-						"description": "New creation date (optional)",
-					},
-				},
-				"required": []string{"id"},
-			},
-		},
-		{
-			"name":        "delete_todo",
-			"description": "Deletes a todo by id.",
-			"inputSchema": map[string]interface{}{
-				"type": "object",
-				"properties": map[string]interface{}{
-					"id": map[string]interface{}{
-						"type":        "string",
-						"description": "Id of the todo to delete",
-					},
-				},
-				"required": []string{"id"},
-			},
-		},
+	switch ctx.Err() {
+	case context.Canceled:
+		// The client cancelled the request or went away, so it expects no response
+		s.logEvent(sess, LevelDebug, "server", "Request cancelled", "method", req.Method)
+		return nil
+	case context.DeadlineExceeded:
+		s.logEvent(sess, LevelWarning, "server", "Request timed out", "method", req.Method, "timeout", s.callTimeout)
+		return newErrorResponse(req.ID, -32603, "Request timed out", map[string]string{"timeout": s.callTimeout.String()})
 	}
 
-	s.sendResult(w, req.ID, map[string]interface{}{
-		"tools": tools,
-	})
+	if mcpErr != nil {
+		return &MCPResponse{JSONRPC: "2.0", ID: req.ID, Error: mcpErr}
+	}
+	return &MCPResponse{JSONRPC: "2.0", ID: req.ID, Result: result}
 }
 
-// handleToolsCall executes a tool call
-func (s *MCPServer) handleToolsCall(w http.ResponseWriter, req MCPRequest) {
-	paramsMap, ok := req.Params.(map[string]interface{})
-	if !ok {
-		s.sendError(w, req.ID, -32602, "Invalid params", nil)
-		return
+// handleNotification processes a client notification. Unknown notifications
+// are ignored, as JSON-RPC forbids answering them.
+func (s *MCPServer) handleNotification(sess *session, req MCPRequest) {
+	switch req.Method {
+	case "notifications/initialized":
+		sess.markInitialized()
+	case "notifications/cancelled":
+		var params CancelledParams
+		if err := json.Unmarshal(req.Params, &params); err == nil {
+			sess.cancelRequest(params.RequestID)
+		}
 	}
+}
 
-	toolName, ok := paramsMap["name"].(string)
-	if !ok {
-		s.sendError(w, req.ID, -32602, "Missing tool name", nil)
-		return
+// handleInitialize negotiates the protocol version and advertises server capabilities
+func (s *MCPServer) handleInitialize(sess *session, req MCPRequest) (interface{}, *MCPError) {
+	var params InitializeParams
+	if err := json.Unmarshal(req.Params, &params); err != nil || params.ProtocolVersion == "" {
+		return nil, newMCPError(-32602, "Invalid params", nil)
 	}
 
-	args, _ := paramsMap["arguments"].(map[string]interface{})
-
-	switch toolName {
-	case "create_todo":
-		s.handleCreateTodo(w, req, args)
-	case "read_todos":
-		s.handleReadTodos(w, req, args)
-	case "update_todo":
-		s.handleUpdateTodo(w, req, args)
-	case "delete_todo":
-		s.handleDeleteTodo(w, req, args)
-	default:
-		s.sendError(w, req.ID, -32601, "Unknown tool", nil)
-	}
+	version := negotiateProtocolVersion(params.ProtocolVersion)
+	sess.setProtocolVersion(version)
+
+	return InitializeResult{
+		ProtocolVersion: version,
+		Capabilities: ServerCapabilities{
+			Tools:       &ToolsCapability{ListChanged: false},
+			Resources:   &ResourcesCapability{Subscribe: true, ListChanged: true},
+			Prompts:     &PromptsCapability{ListChanged: false},
+			Completions: &CompletionsCapability{},
+			Logging:     &LoggingCapability{},
+		},
+		ServerInfo: Implementation{
+			Name:    serverName,
+			Version: serverVersion,
+		},
+		Instructions: "Use the todo tools to create, read, update and delete todo items. The todo list is also available as the todo://all resource, and prompts offer ready-made planning workflows.",
+	}, nil
 }
 
-// handleCreateTodo handles create_todo tool calls
-func (s *MCPServer) handleCreateTodo(w http.ResponseWriter, req MCPRequest, args map[string]interface{}) {
-	description, ok := args["description"].(string)
-	if !ok {
-		s.sendError(w, req.ID, -32602, "Missing or invalid description", nil)
-		return
-	}
-
-	createdDateStr, ok := args["createdDate"].(string)
-	if !ok {
-		s.sendError(w, req.ID, -32602, "Missing or invalid createdDate", nil)
-		return
+// negotiateProtocolVersion returns the requested version when supported,
+// otherwise the latest version the server speaks
+func negotiateProtocolVersion(requested string) string {
+	if isSupportedProtocolVersion(requested) {
+		return requested
 	}
+	return LatestProtocolVersion
+}
 
-	createdDate, err := time.Parse(time.RFC3339, createdDateStr)
-	if err != nil {
-		s.sendError(w, req.ID, -32602, "Invalid date format", nil)
-		return
+// isSupportedProtocolVersion reports whether the server can speak the given protocol version
+func isSupportedProtocolVersion(version string) bool {
+	for _, supported := range supportedProtocolVersions {
+		if supported == version {
+			return true
+		}
 	}
+	return false
+}
 
-	result, err := s.todosTool.CreateTodoAsync(description, createdDate)
-	if err != nil {
-		log.Printf("Error creating todo: %v", err)
-		s.sendError(w, req.ID, -32603, "Internal error", nil)
-		return
-	}
+// ToolDescriptor describes a tool in a tools/list response
+type ToolDescriptor struct {
+	Name         string                 `json:"name"`
+	Title        string                 `json:"title"`
+	Description  string                 `json:"description"`
+	InputSchema  map[string]interface{} `json:"inputSchema"`
+	OutputSchema map[string]interface{} `json:"outputSchema,omitempty"`
+	Annotations  tools.Annotations      `json:"annotations"`
+}
 
-	s.sendResult(w, req.ID, map[string]interface{}{
-		"content": []map[string]interface{}{
-			{
-				"type": "text",
-				"text": result,
-			},
-		},
-	})
+// RegisterTool adds a tool to the server, making it available to tools/list and tools/call
+func (s *MCPServer) RegisterTool(tool tools.Tool) error {
+	return s.registry.Register(tool)
 }
 
-// handleReadTodos handles read_todos tool calls
-func (s *MCPServer) handleReadTodos(w http.ResponseWriter, req MCPRequest, args map[string]interface{}) {
-	var id *string
-	if idValue, exists := args["id"]; exists && idValue != nil {
-		if idStr, ok := idValue.(string); ok {
-			id = &idStr
-		}
+// handleToolsList returns a page of the available MCP tools
+func (s *MCPServer) handleToolsList(req MCPRequest) (interface{}, *MCPError) {
+	offset, mcpErr := listCursor(req)
+	if mcpErr != nil {
+		return nil, mcpErr
 	}
 
-	todos, err := s.todosTool.ReadTodosAsync(id)
-	if err != nil {
-		log.Printf("Error reading todos: %v", err)
-		s.sendError(w, req.ID, -32603, "Internal error", nil)
-		return
+	page, nextCursor := paginate(req.Method, s.registry.List(), offset, s.pageSize)
+	descriptors := []ToolDescriptor{}
+	for _, tool := range page {
+		descriptors = append(descriptors, ToolDescriptor{
+			Name:         tool.Name(),
+			Title:        tool.Title(),
+			Description:  tool.Description(),
+			InputSchema:  tool.InputSchema(),
+			OutputSchema: tool.OutputSchema(),
+			Annotations:  tool.Annotations(),
+		})
 	}
 
-	s.sendResult(w, req.ID, map[string]interface{}{
-		"content": []map[string]interface{}{
-			{
-				"type": "text",
-				"text": s.formatTodosAsJSON(todos),
-			},
-		},
-	})
+	return listResult("tools", descriptors, nextCursor), nil
 }
 
-// handleUpdateTodo handles update_todo tool calls
-func (s *MCPServer) handleUpdateTodo(w http.ResponseWriter, req MCPRequest, args map[string]interface{}) {
-	id, ok := args["id"].(string)
-	if !ok {
-		s.sendError(w, req.ID, -32602, "Missing or invalid id", nil)
-		return
-	}
-
-	var description *string
-	if desc, exists := args["description"]; exists && desc != nil {
-		if descStr, ok := desc.(string); ok {
-			description = &descStr
-		}
+// handleToolsCall executes a tool call
+func (s *MCPServer) handleToolsCall(ctx context.Context, sess *session, req MCPRequest) (interface{}, *MCPError) {
+	var params ToolRequest
+	if err := json.Unmarshal(req.Params, &params); err != nil {
+		return nil, newMCPError(-32602, "Invalid params", nil)
 	}
 
-	var createdDate *time.Time
-	if dateStr, exists := args["createdDate"]; exists && dateStr != nil {
-		if dateString, ok := dateStr.(string); ok {
-			if parsedDate, err := time.Parse(time.RFC3339, dateString); err == nil {
-				createdDate = &parsedDate
-			}
-		}
+	if params.Name == "" {
+		return nil, newMCPError(-32602, "Missing tool name", nil)
 	}
 
-	result, err := s.todosTool.UpdateTodoAsync(id, description, createdDate)
-	if err != nil {
-		log.Printf("Error updating todo: %v", err)
-		s.sendError(w, req.ID, -32603, "Internal error", nil)
-		return
+	tool, ok := s.registry.Lookup(params.Name)
+	if !ok {
+		return nil, newMCPError(-32602, fmt.Sprintf("Unknown tool: %s", params.Name), nil)
 	}
 
-	s.sendResult(w, req.ID, map[string]interface{}{
-		"content": []map[string]interface{}{
-			{
-				"type": "text",
-				"text": result,
-			},
-		},
-	})
-}
-
-// handleDeleteTodo handles delete_todo tool calls
-func (s *MCPServer) handleDeleteTodo(w http.ResponseWriter, req MCPRequest, args map[string]interface{}) {
-	id, ok := args["id"].(string)
-	if !ok {
-		s.sendError(w, req.ID, -32602, "Missing or invalid id", nil)
-		return
+	args := params.Arguments
+	if args == nil {
+		args = map[string]interface{}{}
 	}
 
-	result, err := s.todosTool.DeleteTodoAsync(id)
+	ctx = tools.WithBatch(withProgressToken(ctx, sess, params.Meta), s.beginBatch)
+	result, err := tool.Call(ctx, args)
 	if err != nil {
-		log.Printf("Error deleting todo: %v", err)
-		s.sendError(w, req.ID, -32603, "Internal error", nil)
-		return
+		var validationErr *tools.ValidationError
+		if errors.As(err, &validationErr) {
+			s.logEvent(sess, LevelWarning, "tools", "Invalid tool arguments", "tool", params.Name, "errors", validationErr.Fields)
+			return nil, newMCPError(-32602, "Invalid params", map[string]interface{}{
+				"errors": validationErr.Fields,
+			})
+		}
+		if !tools.IsExpectedError(err) {
+			s.logEvent(sess, LevelError, "tools", "Tool call failed", "tool", params.Name, "error", err)
+		}
+		return tools.ErrorResult(err), nil
 	}
 
-	s.sendResult(w, req.ID, map[string]interface{}{
-		"content": []map[string]interface{}{
-			{
-				"type": "text",
-				"text": result,
-			},
-		},
-	})
+	return result, nil
 }
 
-// formatTodosAsJSON formats todos as JSON string for response
-func (s *MCPServer) formatTodosAsJSON(todos []data.Todo) string {
-	jsonBytes, err := json.Marshal(todos)
-	if err != nil {
-		return "[]"
+// newMCPError creates a JSON-RPC error object
+func newMCPError(code int, message string, data interface{}) *MCPError {
+	return &MCPError{
+		Code:    code,
+		Message: message,
+		Data:    data,
 	}
-	return string(jsonBytes)
 }
 
-// sendResult sends a successful MCP response
-func (s *MCPServer) sendResult(w http.ResponseWriter, id interface{}, result interface{}) {
-	response := MCPResponse{
+// newNotification creates a server-to-client notification
+func newNotification(method string, params interface{}) *MCPNotification {
+	return &MCPNotification{
 		JSONRPC: "2.0",
-		ID:      id,
-		Result:  result,
+		Method:  method,
+		Params:  params,
 	}
-	json.NewEncoder(w).Encode(response)
 }
 
-// sendError sends an error MCP response
-func (s *MCPServer) sendError(w http.ResponseWriter, id interface{}, code int, message string, data interface{}) {
-	response := MCPResponse{
+// newErrorResponse creates an error MCP response
+func newErrorResponse(id interface{}, code int, message string, data interface{}) *MCPResponse {
+	return &MCPResponse{
 		JSONRPC: "2.0",
 		ID:      id,
-		Error: &MCPError{
-			Code:    code,
-			Message: message,
-			Data:    data,
-		},
+		Error:   newMCPError(code, message, data),
 	}
-	w.WriteHeader(http.StatusBadRequest)
-	json.NewEncoder(w).Encode(response)
-}
\ No newline at end of file
+}
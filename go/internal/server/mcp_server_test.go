@@ -0,0 +1,221 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matpadley/MCPServer_Demo/go/internal/data"
+)
+
+func createTestServer(t *testing.T) *MCPServer {
+	db := data.NewMemoryStore()
+	t.Cleanup(func() { db.Close() })
+	s := NewMCPServer(db)
+	t.Cleanup(s.Close)
+	return s
+}
+
+func postMCP(t *testing.T, s *MCPServer, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	rec := httptest.NewRecorder()
+	s.HandleMCP(rec, req)
+	return rec
+}
+
+func decodeResponse(t *testing.T, rec *httptest.ResponseRecorder) MCPResponse {
+	var resp MCPResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("Failed to decode response %q: %v", rec.Body.String(), err)
+	}
+	return resp
+}
+
+func TestInitialize_SupportedVersion(t *testing.T) {
+	s := createTestServer(t)
+
+	rec := postMCP(t, s, `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-03-26","capabilities":{},"clientInfo":{"name":"test","version":"1.0"}}}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+
+	resp := decodeResponse(t, rec)
+	if resp.Error != nil {
+		t.Fatalf("Expected no error, got %+v", resp.Error)
+	}
+
+	result := resp.Result.(map[string]interface{})
+	if result["protocolVersion"] != "2025-03-26" {
+		t.Errorf("Expected negotiated version 2025-03-26, got %v", result["protocolVersion"])
+	}
+	serverInfo := result["serverInfo"].(map[string]interface{})
+	if serverInfo["name"] != serverName {
+		t.Errorf("Expected server name %s, got %v", serverName, serverInfo["name"])
+	}
+	capabilities := result["capabilities"].(map[string]interface{})
+	if _, ok := capabilities["tools"]; !ok {
+		t.Errorf("Expected tools capability, got %v", capabilities)
+	}
+}
+
+func TestInitialize_UnsupportedVersion(t *testing.T) {
+	s := createTestServer(t)
+
+	rec := postMCP(t, s, `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"1999-01-01","capabilities":{},"clientInfo":{"name":"test","version":"1.0"}}}`)
+	resp := decodeResponse(t, rec)
+	if resp.Error != nil {
+		t.Fatalf("Expected no error, got %+v", resp.Error)
+	}
+
+	result := resp.Result.(map[string]interface{})
+	if result["protocolVersion"] != LatestProtocolVersion {
+		t.Errorf("Expected fallback to %s, got %v", LatestProtocolVersion, result["protocolVersion"])
+	}
+}
+
+func TestInitialize_MissingProtocolVersion(t *testing.T) {
+	s := createTestServer(t)
+
+	rec := postMCP(t, s, `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{}}`)
+	resp := decodeResponse(t, rec)
+	if resp.Error == nil || resp.Error.Code != -32602 {
+		t.Errorf("Expected -32602 error, got %+v", resp.Error)
+	}
+}
+
+func TestInitializedNotification(t *testing.T) {
+	s := createTestServer(t)
+
+	rec := postMCP(t, s, `{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+	if rec.Code != http.StatusAccepted {
+		t.Errorf("Expected status 202, got %d", rec.Code)
+	}
+	if rec.Body.Len() != 0 {
+		t.Errorf("Expected empty body, got %q", rec.Body.String())
+	}
+}
+
+func TestParseError(t *testing.T) {
+	s := createTestServer(t)
+
+	rec := postMCP(t, s, `{not json`)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", rec.Code)
+	}
+	resp := decodeResponse(t, rec)
+	if resp.Error == nil || resp.Error.Code != -32700 {
+		t.Errorf("Expected -32700 error, got %+v", resp.Error)
+	}
+	if resp.ID != nil {
+		t.Errorf("Expected null id, got %v", resp.ID)
+	}
+}
+
+func TestParseError_ValidNonRequestJSON(t *testing.T) {
+	s := createTestServer(t)
+
+	tests := []struct {
+		payload string
+		code    int
+	}{
+		{`"abc"`, -32600},
+		{`42`, -32600},
+		{`null`, -32600},
+		{`{"jsonrpc":"2.0","id":1,"method":5}`, -32600},
+		{`"abc`, -32700},
+		{``, -32700},
+	}
+
+	for _, tt := range tests {
+		rec := postMCP(t, s, tt.payload)
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected status 400, got %d", tt.payload, rec.Code)
+		}
+		if resp := decodeResponse(t, rec); resp.Error == nil || resp.Error.Code != tt.code || resp.ID != nil {
+			t.Errorf("%s: expected %d with a null id, got %+v", tt.payload, tt.code, resp)
+		}
+	}
+}
+
+func TestInvalidJSONRPCVersion(t *testing.T) {
+	s := createTestServer(t)
+
+	rec := postMCP(t, s, `{"jsonrpc":"1.0","id":4,"method":"ping"}`)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", rec.Code)
+	}
+	resp := decodeResponse(t, rec)
+	if resp.Error == nil || resp.Error.Code != -32600 {
+		t.Errorf("Expected -32600 error, got %+v", resp.Error)
+	}
+	if resp.ID != float64(4) {
+		t.Errorf("Expected id 4 to be echoed, got %v", resp.ID)
+	}
+}
+
+func TestInvalidRequestID(t *testing.T) {
+	s := createTestServer(t)
+
+	for _, id := range []string{"null", "true", `{"a":1}`} {
+		rec := postMCP(t, s, `{"jsonrpc":"2.0","id":`+id+`,"method":"ping"}`)
+		resp := decodeResponse(t, rec)
+		if resp.Error == nil || resp.Error.Code != -32600 {
+			t.Errorf("id %s: expected -32600 error, got %+v", id, resp.Error)
+		}
+	}
+}
+
+func TestMethodNotFound(t *testing.T) {
+	s := createTestServer(t)
+
+	rec := postMCP(t, s, `{"jsonrpc":"2.0","id":"abc","method":"does/not/exist"}`)
+	if rec.Code != http.StatusOK {
+		t.Errorf("Expected status 200 for JSON-RPC level error, got %d", rec.Code)
+	}
+	resp := decodeResponse(t, rec)
+	if resp.Error == nil || resp.Error.Code != -32601 {
+		t.Errorf("Expected -32601 error, got %+v", resp.Error)
+	}
+	if resp.ID != "abc" {
+		t.Errorf("Expected id abc, got %v", resp.ID)
+	}
+}
+
+func TestUnknownNotification(t *testing.T) {
+	s := createTestServer(t)
+
+	rec := postMCP(t, s, `{"jsonrpc":"2.0","method":"notifications/unknown"}`)
+	if rec.Code != http.StatusAccepted {
+		t.Errorf("Expected status 202, got %d", rec.Code)
+	}
+	if rec.Body.Len() != 0 {
+		t.Errorf("Expected empty body, got %q", rec.Body.String())
+	}
+}
+
+func TestRequestWithoutIDIsNotAnswered(t *testing.T) {
+	s := createTestServer(t)
+
+	rec := postMCP(t, s, `{"jsonrpc":"2.0","method":"tools/list"}`)
+	if rec.Code != http.StatusAccepted {
+		t.Errorf("Expected status 202, got %d", rec.Code)
+	}
+	if rec.Body.Len() != 0 {
+		t.Errorf("Expected empty body, got %q", rec.Body.String())
+	}
+}
+
+func TestHandleMCP_CallTimeout(t *testing.T) {
+	s := createTestServer(t)
+	registerBlockingTool(t, s, make(chan struct{}))
+	s.SetCallTimeout(10 * time.Millisecond)
+
+	resp := decodeResponse(t, postMCP(t, s, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"block","arguments":{}}}`))
+	if resp.Error == nil || resp.Error.Code != -32603 || resp.Error.Message != "Request timed out" {
+		t.Errorf("Expected timeout error, got %+v", resp.Error)
+	}
+}
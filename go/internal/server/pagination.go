@@ -0,0 +1,54 @@
+package server
+
+import (
+	"encoding/json"
+
+	"github.com/matpadley/MCPServer_Demo/go/internal/tools"
+)
+
+// DefaultPageSize is how many items a list method returns per page unless changed with SetPageSize
+const DefaultPageSize = 100
+
+// PaginatedParams represents the params of a paginated list request
+type PaginatedParams struct {
+	Cursor string `json:"cursor,omitempty"`
+}
+
+// listCursor returns the position held by the cursor of a paginated list
+// request; a request without a cursor starts at position zero. Cursors are
+// tied to the method that issued them, so one list cannot page another.
+func listCursor(req MCPRequest) (int, *MCPError) {
+	var params PaginatedParams
+	if len(req.Params) > 0 {
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return 0, newMCPError(-32602, "Invalid params", nil)
+		}
+	}
+
+	position, err := tools.DecodeCursor(req.Method, params.Cursor)
+	if err != nil {
+		return 0, newMCPError(-32602, "Invalid params", "cursor is not valid")
+	}
+	return position, nil
+}
+
+// paginate returns the page of the fixed list of method starting at offset,
+// and the cursor of the following page or an empty string on the last page
+func paginate[T any](method string, items []T, offset, size int) ([]T, string) {
+	offset = min(offset, len(items))
+	end := min(offset+size, len(items))
+	if end == len(items) {
+		return items[offset:end], ""
+	}
+	return items[offset:end], tools.EncodeCursor(method, end)
+}
+
+// listResult builds a list response holding items under key, with nextCursor
+// when another page follows
+func listResult(key string, items interface{}, nextCursor string) map[string]interface{} {
+	result := map[string]interface{}{key: items}
+	if nextCursor != "" {
+		result["nextCursor"] = nextCursor
+	}
+	return result
+}
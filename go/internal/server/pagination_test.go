@@ -0,0 +1,146 @@
+package server
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+// listAll follows nextCursor through every page of a list method and returns
+// the value of field for each item, and the number of pages read
+func listAll(t *testing.T, s *MCPServer, method, key, field string) ([]string, int) {
+	var values []string
+	params := `{}`
+	for pages := 1; ; pages++ {
+		if pages > 20 {
+			t.Fatalf("%s pagination did not terminate", method)
+		}
+		resp := decodeResponse(t, postMCP(t, s, fmt.Sprintf(`{"jsonrpc":"2.0","id":%d,"method":%q,"params":%s}`, pages, method, params)))
+		if resp.Error != nil {
+			t.Fatalf("%s: unexpected error: %+v", method, resp.Error)
+		}
+		result := resp.Result.(map[string]interface{})
+		for _, item := range result[key].([]interface{}) {
+			values = append(values, item.(map[string]interface{})[field].(string))
+		}
+		cursor, ok := result["nextCursor"].(string)
+		if !ok {
+			return values, pages
+		}
+		params = fmt.Sprintf(`{"cursor":%q}`, cursor)
+	}
+}
+
+func TestListMethods_Pagination(t *testing.T) {
+	s := createTestServer(t)
+	s.SetPageSize(2)
+	seedTodos(t, s, "First", "Second", "Third")
+
+	tests := []struct {
+		method, key, field string
+		expected           []string
+		pages              int
+	}{
+		{"tools/list", "tools", "name", []string{"create_todo", "read_todos", "update_todo", "delete_todo", "complete_todo", "reopen_todo", "get_agenda", "add_tags", "remove_tags", "list_tags", "create_project", "list_projects", "archive_project", "move_todo", "import_todos"}, 8},
+		{"prompts/list", "prompts", "name", []string{"plan_my_day", "summarise_open_todos", "break_down_todo"}, 2},
+		{"resources/list", "resources", "uri", []string{"todo://all", "todo://open", "todo://1", "todo://2", "todo://3"}, 2},
+		{"resources/templates/list", "resourceTemplates", "uriTemplate", []string{"todo://{id}", "project://{id}/todos"}, 1},
+	}
+
+	for _, tt := range tests {
+		values, pages := listAll(t, s, tt.method, tt.key, tt.field)
+		if strings.Join(values, ",") != strings.Join(tt.expected, ",") {
+			t.Errorf("%s: expected %v, got %v", tt.method, tt.expected, values)
+		}
+		if pages != tt.pages {
+			t.Errorf("%s: expected %d pages, got %d", tt.method, tt.pages, pages)
+		}
+	}
+}
+
+func TestResourcesList_CursorSurvivesDeletes(t *testing.T) {
+	s := createTestServer(t)
+	s.SetPageSize(2)
+	seedTodos(t, s, "First", "Second", "Third", "Fourth")
+
+	resp := decodeResponse(t, postMCP(t, s, `{"jsonrpc":"2.0","id":1,"method":"resources/list"}`))
+	cursor := resp.Result.(map[string]interface{})["nextCursor"].(string)
+
+	// Deleting a todo already listed must not cause the next page to skip one
+	if _, err := s.todosTool.DeleteTodo(t.Context(), "1"); err != nil {
+		t.Fatalf("Failed to delete todo: %v", err)
+	}
+
+	resp = decodeResponse(t, postMCP(t, s, `{"jsonrpc":"2.0","id":2,"method":"resources/list","params":{"cursor":"`+cursor+`"}}`))
+	var uris []string
+	for _, resource := range resp.Result.(map[string]interface{})["resources"].([]interface{}) {
+		uris = append(uris, resource.(map[string]interface{})["uri"].(string))
+	}
+	if strings.Join(uris, ",") != "todo://3,todo://4" {
+		t.Errorf("Expected todo://3 and todo://4, got %v", uris)
+	}
+}
+
+func TestListMethods_InvalidCursor(t *testing.T) {
+	s := createTestServer(t)
+
+	for _, method := range []string{"tools/list", "prompts/list", "resources/list", "resources/templates/list"} {
+		resp := decodeResponse(t, postMCP(t, s, `{"jsonrpc":"2.0","id":1,"method":"`+method+`","params":{"cursor":"bogus!"}}`))
+		if resp.Error == nil || resp.Error.Code != -32602 {
+			t.Errorf("%s: expected -32602 error, got %+v", method, resp.Error)
+		}
+	}
+}
+
+func TestListMethods_CursorFromAnotherList(t *testing.T) {
+	s := createTestServer(t)
+	s.SetPageSize(2)
+	seedTodos(t, s, "First", "Second", "Third")
+
+	resp := decodeResponse(t, postMCP(t, s, `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+	cursor := resp.Result.(map[string]interface{})["nextCursor"].(string)
+
+	for _, method := range []string{"resources/list", "prompts/list", "resources/templates/list"} {
+		resp := decodeResponse(t, postMCP(t, s, `{"jsonrpc":"2.0","id":2,"method":"`+method+`","params":{"cursor":"`+cursor+`"}}`))
+		if resp.Error == nil || resp.Error.Code != -32602 {
+			t.Errorf("%s: expected -32602 for a tools/list cursor, got %+v", method, resp.Error)
+		}
+	}
+
+	first := callToolResult(t, s, `{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"read_todos","arguments":{"limit":1}}}`)
+	cursor = first["structuredContent"].(map[string]interface{})["nextCursor"].(string)
+	resp = decodeResponse(t, postMCP(t, s, `{"jsonrpc":"2.0","id":4,"method":"resources/list","params":{"cursor":"`+cursor+`"}}`))
+	if resp.Error == nil || resp.Error.Code != -32602 {
+		t.Errorf("Expected -32602 for a read_todos cursor, got %+v", resp.Error)
+	}
+}
+
+func TestReadTodos_NextCursor(t *testing.T) {
+	s := createTestServer(t)
+	seedTodos(t, s, "First", "Second", "Third")
+
+	first := callToolResult(t, s, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"read_todos","arguments":{"limit":2}}}`)
+	structured := first["structuredContent"].(map[string]interface{})
+	if todos := structured["todos"].([]interface{}); len(todos) != 2 {
+		t.Fatalf("Expected 2 todos on the first page, got %d", len(todos))
+	}
+	cursor, ok := structured["nextCursor"].(string)
+	if !ok {
+		t.Fatalf("Expected nextCursor, got %v", structured)
+	}
+
+	second := callToolResult(t, s, `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"read_todos","arguments":{"limit":2,"cursor":"`+cursor+`"}}}`)
+	structured = second["structuredContent"].(map[string]interface{})
+	todos := structured["todos"].([]interface{})
+	if len(todos) != 1 || todos[0].(map[string]interface{})["description"] != "Third" {
+		t.Errorf("Expected only the third todo on the last page, got %v", todos)
+	}
+	if _, ok := structured["nextCursor"]; ok {
+		t.Errorf("Expected no nextCursor on the last page, got %v", structured["nextCursor"])
+	}
+
+	resp := decodeResponse(t, postMCP(t, s, `{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"read_todos","arguments":{"cursor":"bogus!"}}}`))
+	if resp.Error == nil || resp.Error.Code != -32602 {
+		t.Errorf("Expected -32602 for an invalid cursor, got %+v", resp.Error)
+	}
+}
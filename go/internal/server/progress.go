@@ -0,0 +1,73 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/matpadley/MCPServer_Demo/go/internal/tools"
+)
+
+// RequestMeta is the _meta object a client may attach to request params
+type RequestMeta struct {
+	ProgressToken json.RawMessage `json:"progressToken,omitempty"`
+}
+
+// ProgressParams represents the params of a notifications/progress notification
+type ProgressParams struct {
+	ProgressToken json.RawMessage `json:"progressToken"`
+	Progress      float64         `json:"progress"`
+	Total         float64         `json:"total,omitempty"`
+	Message       string          `json:"message,omitempty"`
+}
+
+// notificationSinkKey is the context key of a request-scoped notification sink
+type notificationSinkKey struct{}
+
+// withNotificationSink returns a context whose request-scoped notifications are
+// delivered to sink instead of the session stream
+func withNotificationSink(ctx context.Context, sink func(message interface{})) context.Context {
+	return context.WithValue(ctx, notificationSinkKey{}, sink)
+}
+
+// notifyRequest sends a notification about the request ctx belongs to. It goes
+// to the request's own stream when the transport provides one, and otherwise
+// to the session stream.
+func notifyRequest(ctx context.Context, sess *session, message interface{}) {
+	if sink, ok := ctx.Value(notificationSinkKey{}).(func(message interface{})); ok {
+		sink(message)
+		return
+	}
+	sess.send(message)
+}
+
+// withProgressToken wires tools.ReportProgress to notifications/progress when
+// the client supplied a progress token. Reports that do not advance are dropped,
+// as MCP requires progress to increase.
+func withProgressToken(ctx context.Context, sess *session, meta *RequestMeta) context.Context {
+	if meta == nil || !isValidID(meta.ProgressToken) {
+		return ctx
+	}
+	token := meta.ProgressToken
+
+	var (
+		mu   sync.Mutex
+		last float64
+		sent bool
+	)
+	return tools.WithProgress(ctx, func(progress, total float64, message string) {
+		mu.Lock()
+		defer mu.Unlock()
+		if sent && progress <= last {
+			return
+		}
+		last, sent = progress, true
+
+		notifyRequest(ctx, sess, newNotification("notifications/progress", ProgressParams{
+			ProgressToken: token,
+			Progress:      progress,
+			Total:         total,
+			Message:       message,
+		}))
+	})
+}
@@ -0,0 +1,146 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/matpadley/MCPServer_Demo/go/internal/tools"
+)
+
+const importTwoTodos = `{"description":"First","createdDate":"2024-01-01T10:00:00Z"},{"description":"Second","createdDate":"2024-01-01T11:00:00Z"}`
+
+// sseMessages decodes the data of every SSE event in an HTTP response body
+func sseMessages(t *testing.T, body string) []map[string]interface{} {
+	var messages []map[string]interface{}
+	for _, line := range strings.Split(body, "\n") {
+		data, ok := strings.CutPrefix(line, "data: ")
+		if !ok {
+			continue
+		}
+		var message map[string]interface{}
+		if err := json.Unmarshal([]byte(data), &message); err != nil {
+			t.Fatalf("Failed to decode SSE data %q: %v", data, err)
+		}
+		messages = append(messages, message)
+	}
+	return messages
+}
+
+func postEventStream(s *MCPServer, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(body))
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	rec := httptest.NewRecorder()
+	s.HandleMCP(rec, req)
+	return rec
+}
+
+func TestProgress_EventStream(t *testing.T) {
+	s := createTestServer(t)
+
+	rec := postEventStream(s, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"import_todos","arguments":{"todos":[`+importTwoTodos+`]},"_meta":{"progressToken":"import-1"}}}`)
+	messages := sseMessages(t, rec.Body.String())
+	if len(messages) != 3 {
+		t.Fatalf("Expected 2 progress notifications and a response, got %v", messages)
+	}
+
+	for i, message := range messages[:2] {
+		if message["method"] != "notifications/progress" {
+			t.Fatalf("Expected progress notification, got %v", message)
+		}
+		params := message["params"].(map[string]interface{})
+		if params["progressToken"] != "import-1" || params["progress"] != float64(i+1) || params["total"] != float64(2) {
+			t.Errorf("Unexpected progress params: %v", params)
+		}
+	}
+	if messages[2]["id"] != float64(1) || messages[2]["result"] == nil {
+		t.Errorf("Expected the response last, got %v", messages[2])
+	}
+}
+
+func TestProgress_WithoutToken(t *testing.T) {
+	s := createTestServer(t)
+
+	rec := postEventStream(s, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"import_todos","arguments":{"todos":[`+importTwoTodos+`]}}}`)
+	if messages := sseMessages(t, rec.Body.String()); len(messages) != 1 {
+		t.Errorf("Expected only the response without a progress token, got %v", messages)
+	}
+}
+
+func TestProgress_Stdio(t *testing.T) {
+	s := createTestServer(t)
+
+	input := `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"import_todos","arguments":{"todos":[` + importTwoTodos + `]},"_meta":{"progressToken":7}}}` + "\n"
+	responses, notifications := serveStdio(t, s, input)
+	if len(responses) != 1 || responses[0].Error != nil {
+		t.Fatalf("Expected a successful response, got %+v", responses)
+	}
+
+	var progress int
+	for _, notification := range notifications {
+		if notification.Method == "notifications/progress" {
+			progress++
+		}
+	}
+	if progress != 2 {
+		t.Errorf("Expected 2 progress notifications, got %v", notifications)
+	}
+}
+
+func TestProgress_DropsNonIncreasingReports(t *testing.T) {
+	var sent []ProgressParams
+	ctx := withNotificationSink(t.Context(), func(message interface{}) {
+		sent = append(sent, message.(*MCPNotification).Params.(ProgressParams))
+	})
+	ctx = withProgressToken(ctx, newSession(""), &RequestMeta{ProgressToken: json.RawMessage(`"token"`)})
+
+	for _, progress := range []float64{1, 1, 3, 2, 4} {
+		tools.ReportProgress(ctx, progress, 0, "")
+	}
+
+	var values []float64
+	for _, params := range sent {
+		values = append(values, params.Progress)
+	}
+	if len(values) != 3 || values[0] != 1 || values[1] != 3 || values[2] != 4 {
+		t.Errorf("Expected [1 3 4], got %v", values)
+	}
+}
+
+func TestProgress_ImportHoldsBackChangeNotifications(t *testing.T) {
+	s := createTestServer(t)
+	sess, messages := subscribedSession(t, s, "todo://all")
+
+	// Every progress report and the two change notifications just fit the
+	// session stream, which per-todo change notifications would overflow
+	count := sessionStreamBuffer - 2
+	items := make([]string, count)
+	for i := range items {
+		items[i] = fmt.Sprintf(`{"description":"Todo %d","createdDate":"2024-01-01T10:00:00Z"}`, i+1)
+	}
+	params := `{"name":"import_todos","arguments":{"todos":[` + strings.Join(items, ",") + `]},"_meta":{"progressToken":"import-1"}}`
+	resp := s.handleMessage(t.Context(), sess, MCPRequest{JSONRPC: "2.0", ID: json.RawMessage(`2`), Method: "tools/call", Params: json.RawMessage(params)})
+	if resp.Error != nil {
+		t.Fatalf("Import failed: %+v", resp.Error)
+	}
+
+	var progress int
+	var changes []string
+	for _, notification := range drainNotifications(messages) {
+		if notification.Method == "notifications/progress" {
+			progress++
+		} else {
+			changes = append(changes, notification.Method)
+		}
+	}
+	if progress != count {
+		t.Errorf("Expected %d progress notifications, got %d", count, progress)
+	}
+	expected := "notifications/resources/updated,notifications/resources/list_changed"
+	if strings.Join(changes, ",") != expected {
+		t.Errorf("Expected %s once the import ended, got %v", expected, changes)
+	}
+}
@@ -0,0 +1,229 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/matpadley/MCPServer_Demo/go/internal/tools"
+)
+
+// PromptArgument describes a value the client supplies when getting a prompt
+type PromptArgument struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Required    bool   `json:"required,omitempty"`
+}
+
+// Prompt describes a prompt template in a prompts/list response
+type Prompt struct {
+	Name        string           `json:"name"`
+	Title       string           `json:"title,omitempty"`
+	Description string           `json:"description,omitempty"`
+	Arguments   []PromptArgument `json:"arguments,omitempty"`
+}
+
+// PromptContent is a block of a prompt message: either text or an embedded resource
+type PromptContent struct {
+	Type     string            `json:"type"`
+	Text     string            `json:"text,omitempty"`
+	Resource *ResourceContents `json:"resource,omitempty"`
+}
+
+// PromptMessage is a single message of a rendered prompt
+type PromptMessage struct {
+	Role    string        `json:"role"`
+	Content PromptContent `json:"content"`
+}
+
+// GetPromptParams represents the params of a prompts/get request
+type GetPromptParams struct {
+	Name      string            `json:"name"`
+	Arguments map[string]string `json:"arguments,omitempty"`
+}
+
+// PromptsCapability describes the server's prompts support
+type PromptsCapability struct {
+	ListChanged bool `json:"listChanged"`
+}
+
+// promptDefinition pairs a prompt with the function that renders its messages
+// and the completion functions for its arguments
+type promptDefinition struct {
+	Prompt
+	render      func(ctx context.Context, s *MCPServer, sess *session, args map[string]string) ([]PromptMessage, *MCPError)
+	completions map[string]func(ctx context.Context, s *MCPServer, value string) ([]string, int, error)
+}
+
+// prompts are the built-in todo workflows, in the order they are listed
+var prompts = []promptDefinition{
+	{
+		Prompt: Prompt{
+			Name:        "plan_my_day",
+			Title:       "Plan my day",
+			Description: "Builds a schedule for today from the current todos",
+			Arguments: []PromptArgument{
+				{Name: "focus", Description: "Area to prioritise, such as a project or theme (optional)"},
+			},
+		},
+		render: renderPlanMyDay,
+	},
+	{
+		Prompt: Prompt{
+			Name:        "summarise_open_todos",
+			Title:       "Summarise open todos",
+			Description: "Summarises the outstanding todos and highlights anything that needs attention",
+		},
+		render: renderSummariseOpenTodos,
+	},
+	{
+		Prompt: Prompt{
+			Name:        "break_down_todo",
+			Title:       "Break down a todo",
+			Description: "Breaks a single todo into concrete, actionable steps",
+			Arguments: []PromptArgument{
+				{Name: "id", Description: "Id of the todo to break down", Required: true},
+			},
+		},
+		render: renderBreakDownTodo,
+		completions: map[string]func(ctx context.Context, s *MCPServer, value string) ([]string, int, error){
+			"id": completeTodoIDs,
+		},
+	},
+}
+
+// lookupPrompt returns the built-in prompt with the given name
+func lookupPrompt(name string) (promptDefinition, bool) {
+	for _, prompt := range prompts {
+		if prompt.Name == name {
+			return prompt, true
+		}
+	}
+	return promptDefinition{}, false
+}
+
+// handlePromptsList returns a page of the built-in prompts
+func (s *MCPServer) handlePromptsList(req MCPRequest) (interface{}, *MCPError) {
+	offset, mcpErr := listCursor(req)
+	if mcpErr != nil {
+		return nil, mcpErr
+	}
+
+	page, nextCursor := paginate(req.Method, prompts, offset, s.pageSize)
+	list := make([]Prompt, 0, len(page))
+	for _, prompt := range page {
+		list = append(list, prompt.Prompt)
+	}
+
+	return listResult("prompts", list, nextCursor), nil
+}
+
+// handlePromptsGet renders a prompt with the client's arguments and the current todo data
+func (s *MCPServer) handlePromptsGet(ctx context.Context, sess *session, req MCPRequest) (interface{}, *MCPError) {
+	var params GetPromptParams
+	if err := json.Unmarshal(req.Params, &params); err != nil || params.Name == "" {
+		return nil, newMCPError(-32602, "Invalid params", nil)
+	}
+
+	prompt, ok := lookupPrompt(params.Name)
+	if !ok {
+		return nil, newMCPError(-32602, fmt.Sprintf("Unknown prompt: %s", params.Name), nil)
+	}
+
+	if fields := validatePromptArguments(prompt.Prompt, params.Arguments); len(fields) > 0 {
+		s.logEvent(sess, LevelWarning, "prompts", "Invalid prompt arguments", "prompt", params.Name, "errors", fields)
+		return nil, newMCPError(-32602, "Invalid params", map[string]interface{}{"errors": fields})
+	}
+
+	messages, mcpErr := prompt.render(ctx, s, sess, params.Arguments)
+	if mcpErr != nil {
+		return nil, mcpErr
+	}
+
+	return map[string]interface{}{
+		"description": prompt.Description,
+		"messages":    messages,
+	}, nil
+}
+
+// validatePromptArguments reports missing required arguments and arguments the prompt does not declare
+func validatePromptArguments(prompt Prompt, args map[string]string) []tools.FieldError {
+	var fields []tools.FieldError
+	declared := make(map[string]bool, len(prompt.Arguments))
+	for _, argument := range prompt.Arguments {
+		declared[argument.Name] = true
+		if argument.Required && strings.TrimSpace(args[argument.Name]) == "" {
+			fields = append(fields, tools.FieldError{Field: argument.Name, Message: "is required"})
+		}
+	}
+	var unknown []string
+	for name := range args {
+		if !declared[name] {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		fields = append(fields, tools.FieldError{Field: name, Message: "is not a known argument"})
+	}
+	return fields
+}
+
+// renderPlanMyDay asks the model to schedule the day around the current todos
+func renderPlanMyDay(ctx context.Context, s *MCPServer, sess *session, args map[string]string) ([]PromptMessage, *MCPError) {
+	instructions := "Plan my day using the open todos below. Order them into a realistic schedule, " +
+		"estimate how long each will take, and call out anything that should be deferred."
+	if focus := strings.TrimSpace(args["focus"]); focus != "" {
+		instructions += fmt.Sprintf(" Prioritise work related to %q.", focus)
+	}
+	return s.promptWithResource(ctx, sess, instructions, openTodosURI)
+}
+
+// renderSummariseOpenTodos asks the model for a summary of the outstanding todos
+func renderSummariseOpenTodos(ctx context.Context, s *MCPServer, sess *session, args map[string]string) ([]PromptMessage, *MCPError) {
+	instructions := "Summarise my open todos below in a few sentences. " +
+		"Group related items, point out the oldest ones, and suggest what to tackle first."
+	return s.promptWithResource(ctx, sess, instructions, openTodosURI)
+}
+
+// renderBreakDownTodo asks the model to split one todo into steps. An id that
+// is malformed or names no todo is reported as an invalid argument, since the
+// client supplied a prompt argument rather than a resource URI.
+func renderBreakDownTodo(ctx context.Context, s *MCPServer, sess *session, args map[string]string) ([]PromptMessage, *MCPError) {
+	id, ok := parseTodoURI(todoURIScheme + strings.TrimSpace(args["id"]))
+	if !ok {
+		return nil, invalidPromptArgument("id", "must be a todo id")
+	}
+
+	instructions := "Break the todo below into a short checklist of concrete steps. " +
+		"Each step should be small enough to finish in one sitting."
+	messages, mcpErr := s.promptWithResource(ctx, sess, instructions, todoURI(id))
+	if mcpErr != nil && mcpErr.Code == resourceNotFound {
+		return nil, invalidPromptArgument("id", fmt.Sprintf("todo %d does not exist", id))
+	}
+	return messages, mcpErr
+}
+
+// invalidPromptArgument reports a prompt argument the server cannot use, in
+// the same shape as tool argument errors
+func invalidPromptArgument(field, message string) *MCPError {
+	return newMCPError(-32602, "Invalid params", map[string]interface{}{
+		"errors": []tools.FieldError{{Field: field, Message: message}},
+	})
+}
+
+// promptWithResource builds a user prompt from instructions followed by the
+// JSON contents of a todo resource
+func (s *MCPServer) promptWithResource(ctx context.Context, sess *session, instructions, uri string) ([]PromptMessage, *MCPError) {
+	contents, mcpErr := s.readResourceContents(ctx, sess, uri)
+	if mcpErr != nil {
+		return nil, mcpErr
+	}
+
+	return []PromptMessage{
+		{Role: "user", Content: PromptContent{Type: "text", Text: instructions}},
+		{Role: "user", Content: PromptContent{Type: "resource", Resource: &contents[0]}},
+	}, nil
+}
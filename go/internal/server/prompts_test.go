@@ -0,0 +1,123 @@
+package server
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func getPrompt(t *testing.T, s *MCPServer, name, arguments string) MCPResponse {
+	return decodeResponse(t, postMCP(t, s, `{"jsonrpc":"2.0","id":1,"method":"prompts/get","params":{"name":"`+name+`","arguments":`+arguments+`}}`))
+}
+
+func TestPromptsList(t *testing.T) {
+	s := createTestServer(t)
+
+	resp := decodeResponse(t, postMCP(t, s, `{"jsonrpc":"2.0","id":1,"method":"prompts/list"}`))
+	if resp.Error != nil {
+		t.Fatalf("Unexpected error: %+v", resp.Error)
+	}
+
+	var names []string
+	for _, prompt := range resp.Result.(map[string]interface{})["prompts"].([]interface{}) {
+		names = append(names, prompt.(map[string]interface{})["name"].(string))
+	}
+	expected := "plan_my_day,summarise_open_todos,break_down_todo"
+	if strings.Join(names, ",") != expected {
+		t.Errorf("Expected %s, got %v", expected, names)
+	}
+}
+
+func TestPromptsGet_EmbedsTodos(t *testing.T) {
+	s := createTestServer(t)
+	seedTodos(t, s, "Write report", "Book dentist")
+	if _, err := s.todosTool.CompleteTodo(t.Context(), "1"); err != nil {
+		t.Fatalf("Failed to complete todo: %v", err)
+	}
+
+	resp := getPrompt(t, s, "plan_my_day", `{"focus":"work"}`)
+	if resp.Error != nil {
+		t.Fatalf("Unexpected error: %+v", resp.Error)
+	}
+
+	messages := resp.Result.(map[string]interface{})["messages"].([]interface{})
+	if len(messages) != 2 {
+		t.Fatalf("Expected 2 messages, got %d", len(messages))
+	}
+
+	text := messages[0].(map[string]interface{})["content"].(map[string]interface{})["text"].(string)
+	if !strings.Contains(text, `"work"`) {
+		t.Errorf("Expected focus in instructions, got %s", text)
+	}
+
+	content := messages[1].(map[string]interface{})["content"].(map[string]interface{})
+	if content["type"] != "resource" {
+		t.Fatalf("Expected embedded resource, got %v", content["type"])
+	}
+	resource := content["resource"].(map[string]interface{})
+	if resource["uri"] != "todo://open" || !strings.Contains(resource["text"].(string), "Book dentist") {
+		t.Errorf("Expected open todos embedded, got %v", resource)
+	}
+	if strings.Contains(resource["text"].(string), "Write report") {
+		t.Errorf("Expected the done todo to be left out, got %v", resource["text"])
+	}
+}
+
+func TestPromptsGet_BreakDownTodo(t *testing.T) {
+	s := createTestServer(t)
+	seedTodos(t, s, "Renovate kitchen")
+
+	resp := getPrompt(t, s, "break_down_todo", `{"id":"1"}`)
+	if resp.Error != nil {
+		t.Fatalf("Unexpected error: %+v", resp.Error)
+	}
+	messages := resp.Result.(map[string]interface{})["messages"].([]interface{})
+	resource := messages[1].(map[string]interface{})["content"].(map[string]interface{})["resource"].(map[string]interface{})
+	if resource["uri"] != "todo://1" {
+		t.Errorf("Expected todo://1 embedded, got %v", resource["uri"])
+	}
+
+	for _, id := range []string{"42", "abc"} {
+		resp = getPrompt(t, s, "break_down_todo", `{"id":"`+id+`"}`)
+		if resp.Error == nil || resp.Error.Code != -32602 {
+			t.Fatalf("%s: expected -32602, got %+v", id, resp.Error)
+		}
+		errs := resp.Error.Data.(map[string]interface{})["errors"].([]interface{})
+		if len(errs) != 1 || errs[0].(map[string]interface{})["field"] != "id" {
+			t.Errorf("%s: expected the id argument to be reported, got %v", id, errs)
+		}
+	}
+}
+
+func TestPromptsGet_InvalidArguments(t *testing.T) {
+	s := createTestServer(t)
+
+	tests := []struct {
+		name      string
+		prompt    string
+		arguments string
+		field     string
+	}{
+		{"missing required", "break_down_todo", `{}`, "id"},
+		{"non-numeric id", "break_down_todo", `{"id":"abc"}`, "id"},
+		{"unknown argument", "summarise_open_todos", `{"verbose":"yes"}`, "verbose"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp := getPrompt(t, s, tt.prompt, tt.arguments)
+			if resp.Error == nil || resp.Error.Code != -32602 {
+				t.Fatalf("Expected -32602, got %+v", resp.Error)
+			}
+			encoded, _ := json.Marshal(resp.Error.Data)
+			if !strings.Contains(string(encoded), `"field":"`+tt.field+`"`) {
+				t.Errorf("Expected error for %s, got %s", tt.field, encoded)
+			}
+		})
+	}
+
+	resp := getPrompt(t, s, "no_such_prompt", `{}`)
+	if resp.Error == nil || resp.Error.Code != -32602 {
+		t.Errorf("Expected -32602 for unknown prompt, got %+v", resp.Error)
+	}
+}
@@ -0,0 +1,466 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/matpadley/MCPServer_Demo/go/internal/data"
+	"github.com/matpadley/MCPServer_Demo/go/internal/tools"
+)
+
+// Todo resource URIs and representations
+const (
+	todoURIScheme    = "todo://"
+	allTodosURI      = "todo://all"
+	openTodosURI     = "todo://open"
+	todoURITemplate  = "todo://{id}"
+	projectURIScheme = "project://"
+	projectTodosPath = "/todos"
+	projectTemplate  = "project://{id}/todos"
+	mimeTypeJSON     = "application/json"
+	mimeTypeMarkdown = "text/markdown"
+)
+
+// resourceNotFound is the JSON-RPC error code MCP assigns to unknown resources
+const resourceNotFound = -32002
+
+// Resource describes a concrete resource in a resources/list response
+type Resource struct {
+	URI         string `json:"uri"`
+	Name        string `json:"name"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+// ResourceTemplate describes a parameterised resource in a resources/templates/list response
+type ResourceTemplate struct {
+	URITemplate string `json:"uriTemplate"`
+	Name        string `json:"name"`
+	Title       string `json:"title,omitempty"`
+	Description string `json:"description,omitempty"`
+	MimeType    string `json:"mimeType,omitempty"`
+}
+
+// ResourceContents is one representation of a resource returned by resources/read
+type ResourceContents struct {
+	URI      string `json:"uri"`
+	MimeType string `json:"mimeType"`
+	Text     string `json:"text"`
+}
+
+// ReadResourceParams represents the params of a resources/read request
+type ReadResourceParams struct {
+	URI string `json:"uri"`
+}
+
+// SubscribeParams represents the params of a resources/subscribe or
+// resources/unsubscribe request
+type SubscribeParams struct {
+	URI string `json:"uri"`
+}
+
+// ResourceUpdatedParams represents the params of a notifications/resources/updated notification
+type ResourceUpdatedParams struct {
+	URI string `json:"uri"`
+}
+
+// ResourcesCapability describes the server's resources support
+type ResourcesCapability struct {
+	Subscribe   bool `json:"subscribe"`
+	ListChanged bool `json:"listChanged"`
+}
+
+// todoURI returns the resource URI of a single todo
+func todoURI(id int) string {
+	return fmt.Sprintf("%s%d", todoURIScheme, id)
+}
+
+// projectTodosURI returns the resource URI of the todos in a project
+func projectTodosURI(id int) string {
+	return fmt.Sprintf("%s%d%s", projectURIScheme, id, projectTodosPath)
+}
+
+// handleResourcesList lists the todo collection followed by the individual
+// todos a page at a time. The cursor holds the id of the last todo listed, so
+// todos created or deleted between pages do not shift the pages that follow.
+func (s *MCPServer) handleResourcesList(ctx context.Context, sess *session, req MCPRequest) (interface{}, *MCPError) {
+	afterID, mcpErr := listCursor(req)
+	if mcpErr != nil {
+		return nil, mcpErr
+	}
+
+	todos, more, err := s.db.ListTodosAsync(ctx, data.TodoQuery{AfterID: afterID, Limit: s.pageSize})
+	if err != nil {
+		s.logEvent(sess, LevelError, "resources", "Failed to list resources", "error", err)
+		return nil, newMCPError(-32603, "Internal error", nil)
+	}
+
+	resources := []Resource{}
+	if afterID == 0 {
+		resources = append(resources, Resource{
+			URI:         allTodosURI,
+			Name:        "todos",
+			Title:       "All todos",
+			Description: "Every todo item, ordered by id",
+			MimeType:    mimeTypeJSON,
+		}, Resource{
+			URI:         openTodosURI,
+			Name:        "open-todos",
+			Title:       "Open todos",
+			Description: "Every todo that is not done, ordered by id",
+			MimeType:    mimeTypeJSON,
+		})
+	}
+	for _, todo := range todos {
+		resources = append(resources, Resource{
+			URI:      todoURI(todo.ID),
+			Name:     fmt.Sprintf("todo-%d", todo.ID),
+			Title:    todoTitle(todo),
+			MimeType: mimeTypeJSON,
+		})
+	}
+
+	var nextCursor string
+	if more {
+		nextCursor = tools.EncodeCursor(req.Method, todos[len(todos)-1].ID)
+	}
+	return listResult("resources", resources, nextCursor), nil
+}
+
+// resourceTemplates are the parameterised todo resources, in the order they are listed
+var resourceTemplates = []ResourceTemplate{{
+	URITemplate: todoURITemplate,
+	Name:        "todo",
+	Title:       "Todo by id",
+	Description: "A single todo item identified by its id",
+	MimeType:    mimeTypeJSON,
+}, {
+	URITemplate: projectTemplate,
+	Name:        "project-todos",
+	Title:       "Todos in a project",
+	Description: "Every todo in a project identified by its id, ordered by id",
+	MimeType:    mimeTypeJSON,
+}}
+
+// handleResourceTemplatesList returns a page of the parameterised todo resources
+func (s *MCPServer) handleResourceTemplatesList(req MCPRequest) (interface{}, *MCPError) {
+	offset, mcpErr := listCursor(req)
+	if mcpErr != nil {
+		return nil, mcpErr
+	}
+
+	page, nextCursor := paginate(req.Method, resourceTemplates, offset, s.pageSize)
+	return listResult("resourceTemplates", page, nextCursor), nil
+}
+
+// handleResourcesRead returns JSON and Markdown representations of a todo resource
+func (s *MCPServer) handleResourcesRead(ctx context.Context, sess *session, req MCPRequest) (interface{}, *MCPError) {
+	var params ReadResourceParams
+	if err := json.Unmarshal(req.Params, &params); err != nil || params.URI == "" {
+		return nil, newMCPError(-32602, "Invalid params", nil)
+	}
+
+	contents, mcpErr := s.readResourceContents(ctx, sess, params.URI)
+	if mcpErr != nil {
+		return nil, mcpErr
+	}
+
+	return map[string]interface{}{
+		"contents": contents,
+	}, nil
+}
+
+// readResourceContents renders a todo resource as JSON followed by Markdown
+func (s *MCPServer) readResourceContents(ctx context.Context, sess *session, uri string) ([]ResourceContents, *MCPError) {
+	resource, mcpErr := s.readTodoResource(ctx, sess, uri)
+	if mcpErr != nil {
+		return nil, mcpErr
+	}
+
+	encoded, err := json.Marshal(resource.value)
+	if err != nil {
+		s.logEvent(sess, LevelError, "resources", "Failed to encode resource", "uri", uri, "error", err)
+		return nil, newMCPError(-32603, "Internal error", nil)
+	}
+
+	return []ResourceContents{
+		{URI: uri, MimeType: mimeTypeJSON, Text: string(encoded)},
+		{URI: uri, MimeType: mimeTypeMarkdown, Text: formatTodosAsMarkdown(resource.title, resource.todos)},
+	}, nil
+}
+
+// handleResourcesSubscribe starts sending notifications/resources/updated for a resource
+func (s *MCPServer) handleResourcesSubscribe(ctx context.Context, sess *session, req MCPRequest) (interface{}, *MCPError) {
+	var params SubscribeParams
+	if err := json.Unmarshal(req.Params, &params); err != nil || params.URI == "" {
+		return nil, newMCPError(-32602, "Invalid params", nil)
+	}
+	if !sess.stateful() {
+		// Updates are delivered on the session stream, which a stateless request does not have
+		return nil, newMCPError(-32600, "Invalid Request", "resources/subscribe requires a session")
+	}
+
+	if _, mcpErr := s.readTodoResource(ctx, sess, params.URI); mcpErr != nil {
+		return nil, mcpErr
+	}
+
+	sess.subscribe(params.URI)
+	return map[string]interface{}{}, nil
+}
+
+// handleResourcesUnsubscribe stops sending updates for a resource
+func (s *MCPServer) handleResourcesUnsubscribe(sess *session, req MCPRequest) (interface{}, *MCPError) {
+	var params SubscribeParams
+	if err := json.Unmarshal(req.Params, &params); err != nil || params.URI == "" {
+		return nil, newMCPError(-32602, "Invalid params", nil)
+	}
+
+	sess.unsubscribe(params.URI)
+	return map[string]interface{}{}, nil
+}
+
+// handleTodoChange notifies sessions about a committed todo mutation. Sessions
+// subscribed to the todo, to the whole collection or to a project the todo
+// left or joined receive notifications/resources/updated, and additions and
+// removals also change the resource list. While a batch is open the
+// notifications are held back and sent once it ends.
+func (s *MCPServer) handleTodoChange(change data.TodoChange) {
+	uris := []string{todoURI(change.ID), allTodosURI, openTodosURI}
+	for _, projectID := range []*int{change.PreviousProjectID, change.ProjectID} {
+		if projectID != nil && !slices.Contains(uris, projectTodosURI(*projectID)) {
+			uris = append(uris, projectTodosURI(*projectID))
+		}
+	}
+	listChanged := change.Kind == data.TodoCreated || change.Kind == data.TodoDeleted
+
+	s.changesMu.Lock()
+	if s.batches > 0 {
+		s.heldChanges.add(uris, listChanged)
+		s.changesMu.Unlock()
+		return
+	}
+	s.changesMu.Unlock()
+	s.sendChanges(uris, listChanged)
+}
+
+// heldChanges collects the change notifications held back during a batch
+type heldChanges struct {
+	uris        []string
+	seen        map[string]bool
+	listChanged bool
+}
+
+// add records the URIs a change updated, each only once
+func (h *heldChanges) add(uris []string, listChanged bool) {
+	if h.seen == nil {
+		h.seen = make(map[string]bool)
+	}
+	for _, uri := range uris {
+		if !h.seen[uri] {
+			h.seen[uri] = true
+			h.uris = append(h.uris, uri)
+		}
+	}
+	h.listChanged = h.listChanged || listChanged
+}
+
+// beginBatch holds back change notifications until the returned function is
+// called. Batches may overlap; the held notifications are sent when the last
+// one ends, with each URI updated once and a single list_changed.
+func (s *MCPServer) beginBatch() (end func()) {
+	s.changesMu.Lock()
+	s.batches++
+	s.changesMu.Unlock()
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			s.changesMu.Lock()
+			s.batches--
+			var held heldChanges
+			if s.batches == 0 {
+				held, s.heldChanges = s.heldChanges, heldChanges{}
+			}
+			s.changesMu.Unlock()
+
+			if len(held.uris) > 0 {
+				s.sendChanges(held.uris, held.listChanged)
+			}
+		})
+	}
+}
+
+// sendChanges sends resources/updated for each subscribed URI and, when the
+// resource list changed, list_changed to every session. Sessions that have not
+// finished initializing are skipped, and notifications for sessions without an
+// open stream are dropped.
+func (s *MCPServer) sendChanges(uris []string, listChanged bool) {
+	for _, sess := range s.sessionList() {
+		if !sess.isInitialized() {
+			continue
+		}
+		for _, uri := range uris {
+			if sess.isSubscribed(uri) {
+				sess.send(newNotification("notifications/resources/updated", ResourceUpdatedParams{URI: uri}))
+			}
+		}
+		if listChanged {
+			sess.send(newNotification("notifications/resources/list_changed", nil))
+		}
+	}
+}
+
+// todoResource is what a todo resource URI resolves to
+type todoResource struct {
+	// title heads the Markdown representation
+	title string
+	// value is encoded as the JSON representation
+	value interface{}
+	// todos are listed in the Markdown representation
+	todos []data.Todo
+}
+
+// readTodoResource resolves a todo or project resource URI to the todos it represents
+func (s *MCPServer) readTodoResource(ctx context.Context, sess *session, uri string) (*todoResource, *MCPError) {
+	notFound := newMCPError(resourceNotFound, "Resource not found", map[string]string{"uri": uri})
+	internal := func(err error) *MCPError {
+		s.logEvent(sess, LevelError, "resources", "Failed to read resource", "uri", uri, "error", err)
+		return newMCPError(-32603, "Internal error", nil)
+	}
+
+	if uri == allTodosURI {
+		todos, err := s.db.ReadTodosAsync(ctx)
+		if err != nil {
+			return nil, internal(err)
+		}
+		if todos == nil {
+			todos = []data.Todo{}
+		}
+		return &todoResource{title: "Todos", value: todos, todos: todos}, nil
+	}
+
+	if uri == openTodosURI {
+		todos, _, err := s.db.ListTodosAsync(ctx, data.TodoQuery{Status: data.StatusOpen})
+		if err != nil {
+			return nil, internal(err)
+		}
+		if todos == nil {
+			todos = []data.Todo{}
+		}
+		return &todoResource{title: "Open todos", value: todos, todos: todos}, nil
+	}
+
+	if projectID, ok := parseProjectTodosURI(uri); ok {
+		projects, err := s.db.ReadProjectsAsync(ctx, projectID)
+		if err != nil {
+			return nil, internal(err)
+		}
+		if len(projects) == 0 {
+			return nil, notFound
+		}
+		todos, _, err := s.db.ListTodosAsync(ctx, data.TodoQuery{ProjectID: &projectID})
+		if err != nil {
+			return nil, internal(err)
+		}
+		if todos == nil {
+			todos = []data.Todo{}
+		}
+		return &todoResource{title: projects[0].Name, value: todos, todos: todos}, nil
+	}
+
+	id, ok := parseTodoURI(uri)
+	if !ok {
+		return nil, notFound
+	}
+
+	todos, err := s.db.ReadTodosAsync(ctx, id)
+	if err != nil {
+		return nil, internal(err)
+	}
+	if len(todos) == 0 {
+		return nil, notFound
+	}
+	return &todoResource{title: fmt.Sprintf("Todo %d", id), value: todos[0], todos: todos}, nil
+}
+
+// parseProjectTodosURI extracts the project id from a project://{id}/todos URI
+func parseProjectTodosURI(uri string) (int, bool) {
+	rest, ok := strings.CutPrefix(uri, projectURIScheme)
+	if !ok {
+		return 0, false
+	}
+	rest, ok = strings.CutSuffix(rest, projectTodosPath)
+	if !ok {
+		return 0, false
+	}
+	id, err := strconv.Atoi(rest)
+	if err != nil || id <= 0 {
+		return 0, false
+	}
+	return id, true
+}
+
+// parseTodoURI extracts the id from a todo://{id} URI
+func parseTodoURI(uri string) (int, bool) {
+	rest, ok := strings.CutPrefix(uri, todoURIScheme)
+	if !ok {
+		return 0, false
+	}
+	id, err := strconv.Atoi(rest)
+	if err != nil || id <= 0 {
+		return 0, false
+	}
+	return id, true
+}
+
+// todoTitle returns a human-readable label for a todo
+func todoTitle(todo data.Todo) string {
+	if todo.Description == nil || strings.TrimSpace(*todo.Description) == "" {
+		return fmt.Sprintf("Todo %d", todo.ID)
+	}
+	return *todo.Description
+}
+
+// formatTodosAsMarkdown renders todos as a Markdown list
+func formatTodosAsMarkdown(title string, todos []data.Todo) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", title)
+	if len(todos) == 0 {
+		b.WriteString("_No todos._\n")
+		return b.String()
+	}
+	for _, todo := range todos {
+		details := "created " + todo.CreatedDate.Format(time.RFC3339)
+		if todo.DueDate != nil {
+			details += ", due " + formatDue(todo)
+		}
+		if len(todo.Tags) > 0 {
+			details += ", tags " + strings.Join(todo.Tags, ", ")
+		}
+		if todo.Status == data.StatusDone && todo.CompletedAt != nil {
+			fmt.Fprintf(&b, "- [x] **#%d** %s (%s, completed %s)\n", todo.ID, todoTitle(todo), details, todo.CompletedAt.Format(time.RFC3339))
+			continue
+		}
+		fmt.Fprintf(&b, "- [ ] **#%d** %s (%s)\n", todo.ID, todoTitle(todo), details)
+	}
+	return b.String()
+}
+
+// formatDue renders a todo's due date with its time and time zone when set
+func formatDue(todo data.Todo) string {
+	due := *todo.DueDate
+	if todo.DueTime != nil {
+		due += " " + *todo.DueTime
+	}
+	if todo.DueTimeZone != nil {
+		due += " " + *todo.DueTimeZone
+	}
+	return due
+}
@@ -0,0 +1,288 @@
+package server
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matpadley/MCPServer_Demo/go/internal/data"
+)
+
+func seedTodos(t *testing.T, s *MCPServer, descriptions ...string) {
+	for _, description := range descriptions {
+		if _, err := s.todosTool.CreateTodoAsync(t.Context(), description, time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)); err != nil {
+			t.Fatalf("Failed to seed todo: %v", err)
+		}
+	}
+}
+
+func TestResourcesList(t *testing.T) {
+	s := createTestServer(t)
+	seedTodos(t, s, "First", "Second")
+
+	resp := decodeResponse(t, postMCP(t, s, `{"jsonrpc":"2.0","id":1,"method":"resources/list"}`))
+	if resp.Error != nil {
+		t.Fatalf("Unexpected error: %+v", resp.Error)
+	}
+
+	resources := resp.Result.(map[string]interface{})["resources"].([]interface{})
+	var uris []string
+	for _, resource := range resources {
+		uris = append(uris, resource.(map[string]interface{})["uri"].(string))
+	}
+	expected := []string{"todo://all", "todo://open", "todo://1", "todo://2"}
+	if strings.Join(uris, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected %v, got %v", expected, uris)
+	}
+}
+
+func TestResourceTemplatesList(t *testing.T) {
+	s := createTestServer(t)
+
+	resp := decodeResponse(t, postMCP(t, s, `{"jsonrpc":"2.0","id":1,"method":"resources/templates/list"}`))
+	templates := resp.Result.(map[string]interface{})["resourceTemplates"].([]interface{})
+	var uriTemplates []string
+	for _, template := range templates {
+		uriTemplates = append(uriTemplates, template.(map[string]interface{})["uriTemplate"].(string))
+	}
+	expected := []string{"todo://{id}", "project://{id}/todos"}
+	if strings.Join(uriTemplates, ",") != strings.Join(expected, ",") {
+		t.Errorf("Expected %v, got %v", expected, uriTemplates)
+	}
+}
+
+func readResource(t *testing.T, s *MCPServer, uri string) MCPResponse {
+	return decodeResponse(t, postMCP(t, s, `{"jsonrpc":"2.0","id":1,"method":"resources/read","params":{"uri":"`+uri+`"}}`))
+}
+
+func TestResourcesRead_All(t *testing.T) {
+	s := createTestServer(t)
+	seedTodos(t, s, "First", "Second")
+
+	resp := readResource(t, s, "todo://all")
+	if resp.Error != nil {
+		t.Fatalf("Unexpected error: %+v", resp.Error)
+	}
+
+	contents := resp.Result.(map[string]interface{})["contents"].([]interface{})
+	if len(contents) != 2 {
+		t.Fatalf("Expected JSON and Markdown contents, got %d", len(contents))
+	}
+
+	jsonContent := contents[0].(map[string]interface{})
+	if jsonContent["mimeType"] != "application/json" {
+		t.Errorf("Expected application/json first, got %v", jsonContent["mimeType"])
+	}
+	var todos []data.Todo
+	if err := json.Unmarshal([]byte(jsonContent["text"].(string)), &todos); err != nil {
+		t.Fatalf("Failed to decode JSON content: %v", err)
+	}
+	if len(todos) != 2 {
+		t.Errorf("Expected 2 todos, got %d", len(todos))
+	}
+
+	markdown := contents[1].(map[string]interface{})
+	if markdown["mimeType"] != "text/markdown" {
+		t.Errorf("Expected text/markdown second, got %v", markdown["mimeType"])
+	}
+	if text := markdown["text"].(string); !strings.Contains(text, "**#1** First") || !strings.Contains(text, "**#2** Second") {
+		t.Errorf("Unexpected markdown: %s", text)
+	}
+}
+
+func TestResourcesRead_Single(t *testing.T) {
+	s := createTestServer(t)
+	seedTodos(t, s, "Only")
+
+	resp := readResource(t, s, "todo://1")
+	if resp.Error != nil {
+		t.Fatalf("Unexpected error: %+v", resp.Error)
+	}
+
+	contents := resp.Result.(map[string]interface{})["contents"].([]interface{})
+	var todo data.Todo
+	if err := json.Unmarshal([]byte(contents[0].(map[string]interface{})["text"].(string)), &todo); err != nil {
+		t.Fatalf("Failed to decode JSON content: %v", err)
+	}
+	if todo.ID != 1 || *todo.Description != "Only" {
+		t.Errorf("Unexpected todo: %+v", todo)
+	}
+}
+
+func TestResourcesRead_NotFound(t *testing.T) {
+	s := createTestServer(t)
+
+	for _, uri := range []string{"todo://42", "todo://abc", "project://42/todos", "project://1", "file:///etc/passwd"} {
+		resp := readResource(t, s, uri)
+		if resp.Error == nil || resp.Error.Code != resourceNotFound {
+			t.Errorf("%s: expected -32002 error, got %+v", uri, resp.Error)
+		}
+	}
+}
+
+func TestResourcesRead_Project(t *testing.T) {
+	s := createTestServer(t)
+	seedTodos(t, s, "Outside", "Inside")
+	project, err := s.todosTool.CreateProject(t.Context(), "Garden")
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	if _, err := s.todosTool.MoveTodo(t.Context(), "2", stringPtr("1")); err != nil {
+		t.Fatalf("Failed to move todo: %v", err)
+	}
+
+	resp := readResource(t, s, projectTodosURI(project.ID))
+	if resp.Error != nil {
+		t.Fatalf("Unexpected error: %+v", resp.Error)
+	}
+
+	contents := resp.Result.(map[string]interface{})["contents"].([]interface{})
+	var todos []data.Todo
+	if err := json.Unmarshal([]byte(contents[0].(map[string]interface{})["text"].(string)), &todos); err != nil {
+		t.Fatalf("Failed to decode JSON content: %v", err)
+	}
+	if len(todos) != 1 || *todos[0].Description != "Inside" {
+		t.Errorf("Expected only the todo in the project, got %+v", todos)
+	}
+	if markdown := contents[1].(map[string]interface{})["text"].(string); !strings.HasPrefix(markdown, "# Garden\n") {
+		t.Errorf("Expected Markdown titled with the project name, got %q", markdown)
+	}
+}
+
+func subscribedSession(t *testing.T, s *MCPServer, uri string) (*session, <-chan interface{}) {
+	sess, err := s.createSession()
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+	sess.markInitialized()
+	messages, _ := sess.attachStream()
+
+	resp := s.handleMessage(t.Context(), sess, MCPRequest{JSONRPC: "2.0", ID: json.RawMessage(`1`), Method: "resources/subscribe", Params: json.RawMessage(`{"uri":"` + uri + `"}`)})
+	if resp.Error != nil {
+		t.Fatalf("Failed to subscribe to %s: %+v", uri, resp.Error)
+	}
+	return sess, messages
+}
+
+func drainNotifications(messages <-chan interface{}) []*MCPNotification {
+	var notifications []*MCPNotification
+	for {
+		select {
+		case message := <-messages:
+			notifications = append(notifications, message.(*MCPNotification))
+		default:
+			return notifications
+		}
+	}
+}
+
+func TestResourcesSubscribe_Updated(t *testing.T) {
+	s := createTestServer(t)
+	seedTodos(t, s, "Watched", "Ignored")
+	sess, messages := subscribedSession(t, s, "todo://1")
+
+	if _, err := s.todosTool.UpdateTodoAsync(t.Context(), "2", stringPtr("Still ignored"), nil); err != nil {
+		t.Fatalf("Failed to update todo: %v", err)
+	}
+	if notifications := drainNotifications(messages); len(notifications) != 0 {
+		t.Errorf("Expected no notifications for an unsubscribed todo, got %v", notifications)
+	}
+
+	if _, err := s.todosTool.UpdateTodoAsync(t.Context(), "1", stringPtr("Changed"), nil); err != nil {
+		t.Fatalf("Failed to update todo: %v", err)
+	}
+	notifications := drainNotifications(messages)
+	if len(notifications) != 1 || notifications[0].Method != "notifications/resources/updated" {
+		t.Fatalf("Expected one resources/updated notification, got %v", notifications)
+	}
+	if params := notifications[0].Params.(ResourceUpdatedParams); params.URI != "todo://1" {
+		t.Errorf("Expected update for todo://1, got %s", params.URI)
+	}
+
+	s.handleMessage(t.Context(), sess, MCPRequest{JSONRPC: "2.0", ID: json.RawMessage(`2`), Method: "resources/unsubscribe", Params: json.RawMessage(`{"uri":"todo://1"}`)})
+	if _, err := s.todosTool.UpdateTodoAsync(t.Context(), "1", stringPtr("Changed again"), nil); err != nil {
+		t.Fatalf("Failed to update todo: %v", err)
+	}
+	if notifications := drainNotifications(messages); len(notifications) != 0 {
+		t.Errorf("Expected no notifications after unsubscribing, got %v", notifications)
+	}
+}
+
+func TestResourcesSubscribe_ListChanged(t *testing.T) {
+	s := createTestServer(t)
+	_, messages := subscribedSession(t, s, "todo://all")
+
+	seedTodos(t, s, "New")
+	var methods []string
+	for _, notification := range drainNotifications(messages) {
+		methods = append(methods, notification.Method)
+	}
+	expected := "notifications/resources/updated,notifications/resources/list_changed"
+	if strings.Join(methods, ",") != expected {
+		t.Errorf("Expected %s, got %v", expected, methods)
+	}
+}
+
+func TestResourcesSubscribe_Project(t *testing.T) {
+	s := createTestServer(t)
+	seedTodos(t, s, "Loose", "Elsewhere")
+	for _, name := range []string{"Garden", "Work"} {
+		if _, err := s.todosTool.CreateProject(t.Context(), name); err != nil {
+			t.Fatalf("Failed to create project: %v", err)
+		}
+	}
+	_, garden := subscribedSession(t, s, "project://1/todos")
+	_, work := subscribedSession(t, s, "project://2/todos")
+
+	if _, err := s.todosTool.MoveTodo(t.Context(), "1", stringPtr("1")); err != nil {
+		t.Fatalf("Failed to move todo: %v", err)
+	}
+	notifications := drainNotifications(garden)
+	if len(notifications) != 1 || notifications[0].Params.(ResourceUpdatedParams).URI != "project://1/todos" {
+		t.Errorf("Expected one update for project://1/todos, got %v", notifications)
+	}
+	if notifications := drainNotifications(work); len(notifications) != 0 {
+		t.Errorf("Expected no notifications for an unrelated project, got %v", notifications)
+	}
+
+	if _, err := s.todosTool.UpdateTodoAsync(t.Context(), "2", stringPtr("Still elsewhere"), nil); err != nil {
+		t.Fatalf("Failed to update todo: %v", err)
+	}
+	if notifications := append(drainNotifications(garden), drainNotifications(work)...); len(notifications) != 0 {
+		t.Errorf("Expected no project notifications for a todo outside them, got %v", notifications)
+	}
+
+	// Moving between projects tells both
+	if _, err := s.todosTool.MoveTodo(t.Context(), "1", stringPtr("2")); err != nil {
+		t.Fatalf("Failed to move todo: %v", err)
+	}
+	if notifications := drainNotifications(garden); len(notifications) != 1 {
+		t.Errorf("Expected the project left to be updated, got %v", notifications)
+	}
+	if notifications := drainNotifications(work); len(notifications) != 1 || notifications[0].Params.(ResourceUpdatedParams).URI != "project://2/todos" {
+		t.Errorf("Expected the project joined to be updated, got %v", notifications)
+	}
+}
+
+func TestResourcesSubscribe_Errors(t *testing.T) {
+	s := createTestServer(t)
+
+	resp := decodeResponse(t, postMCP(t, s, `{"jsonrpc":"2.0","id":1,"method":"resources/subscribe","params":{"uri":"todo://all"}}`))
+	if resp.Error == nil || resp.Error.Code != -32600 {
+		t.Errorf("Expected stateless subscribe to be rejected, got %+v", resp.Error)
+	}
+
+	sess, err := s.createSession()
+	if err != nil {
+		t.Fatalf("Failed to create session: %v", err)
+	}
+	response := s.handleMessage(t.Context(), sess, MCPRequest{JSONRPC: "2.0", ID: json.RawMessage(`1`), Method: "resources/subscribe", Params: json.RawMessage(`{"uri":"todo://99"}`)})
+	if response.Error == nil || response.Error.Code != resourceNotFound {
+		t.Errorf("Expected -32002 for unknown resource, got %+v", response.Error)
+	}
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
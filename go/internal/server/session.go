@@ -0,0 +1,354 @@
+package server
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// sessionStreamBuffer is the number of server-to-client messages queued for a
+// session stream before further messages are dropped
+const sessionStreamBuffer = 64
+
+// Session limits used unless changed with SetSessionLimits
+const (
+	// DefaultSessionIdleTimeout is how long a session may go unused before it is ended
+	DefaultSessionIdleTimeout = 30 * time.Minute
+	// DefaultMaxSessions is how many sessions may be live at once
+	DefaultMaxSessions = 1000
+)
+
+// errTooManySessions reports that every session slot is held by a session in use
+var errTooManySessions = errors.New("too many sessions")
+
+// session tracks the state of a single MCP client connection
+type session struct {
+	id string
+
+	mu              sync.Mutex
+	protocolVersion string
+	initialized     bool
+	stream          chan interface{}
+	subscriptions   map[string]struct{}
+	logLevel        LoggingLevel
+	inFlight        map[string]context.CancelFunc
+	lastSeen        time.Time
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// newSession creates a session with the given id; an empty id denotes a
+// stateless session that lives only for the duration of one request
+func newSession(id string) *session {
+	return &session{
+		id:       id,
+		done:     make(chan struct{}),
+		lastSeen: time.Now(),
+	}
+}
+
+// touch records that the client used the session
+func (sess *session) touch(now time.Time) {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	sess.lastSeen = now
+}
+
+// idleSince returns when the session was last used, and false while it is in
+// use by an open stream or a running request
+func (sess *session) idleSince() (time.Time, bool) {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	if sess.stream != nil || len(sess.inFlight) > 0 {
+		return time.Time{}, false
+	}
+	return sess.lastSeen, true
+}
+
+// newSessionID returns a cryptographically random session identifier
+func newSessionID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate session id: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
+
+// setProtocolVersion records the protocol version negotiated during initialization
+func (sess *session) setProtocolVersion(version string) {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	sess.protocolVersion = version
+}
+
+// markInitialized records that the client has completed initialization
+func (sess *session) markInitialized() {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	sess.initialized = true
+}
+
+// isInitialized reports whether the client has completed initialization
+func (sess *session) isInitialized() bool {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	return sess.initialized
+}
+
+// setLogLevel sets the minimum level of log messages the client receives
+func (sess *session) setLogLevel(level LoggingLevel) {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	sess.logLevel = level
+}
+
+// logEnabled reports whether the client asked for messages at level. Clients
+// that never call logging/setLevel receive no log messages.
+func (sess *session) logEnabled(level LoggingLevel) bool {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	return sess.logLevel != "" && level.atLeast(sess.logLevel)
+}
+
+// trackRequest records how to cancel an in-flight request
+func (sess *session) trackRequest(id json.RawMessage, cancel context.CancelFunc) {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	if sess.inFlight == nil {
+		sess.inFlight = make(map[string]context.CancelFunc)
+	}
+	sess.inFlight[requestKey(id)] = cancel
+}
+
+// untrackRequest forgets a request once it has completed
+func (sess *session) untrackRequest(id json.RawMessage) {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	delete(sess.inFlight, requestKey(id))
+}
+
+// cancelRequest cancels an in-flight request. Unknown or completed requests
+// are ignored, as a cancellation may race with the response.
+func (sess *session) cancelRequest(id json.RawMessage) {
+	sess.mu.Lock()
+	cancel, ok := sess.inFlight[requestKey(id)]
+	sess.mu.Unlock()
+	if ok {
+		cancel()
+	}
+}
+
+// requestKey identifies a request by its id; string and number ids never collide
+// because the JSON encoding of a string keeps its quotes
+func requestKey(id json.RawMessage) string {
+	return string(bytes.TrimSpace(id))
+}
+
+// attachStream opens the server-to-client message stream for the session.
+// It returns false when a stream is already attached.
+func (sess *session) attachStream() (<-chan interface{}, bool) {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	if sess.stream != nil {
+		return nil, false
+	}
+	sess.stream = make(chan interface{}, sessionStreamBuffer)
+	return sess.stream, true
+}
+
+// detachStream closes the server-to-client message stream, if attached. The
+// session's idle time starts from here.
+func (sess *session) detachStream() {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	sess.stream = nil
+	sess.lastSeen = time.Now()
+}
+
+// send queues a server-to-client message on the session stream. It returns
+// false when no stream is attached or the stream buffer is full.
+func (sess *session) send(message interface{}) bool {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	if sess.stream == nil {
+		return false
+	}
+	select {
+	case sess.stream <- message:
+		return true
+	default:
+		return false
+	}
+}
+
+// stateful reports whether the session outlives a single request, which is
+// required for anything the server sends later, such as resource updates
+func (sess *session) stateful() bool {
+	return sess.id != ""
+}
+
+// subscribe records the client's interest in updates to a resource
+func (sess *session) subscribe(uri string) {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	if sess.subscriptions == nil {
+		sess.subscriptions = make(map[string]struct{})
+	}
+	sess.subscriptions[uri] = struct{}{}
+}
+
+// unsubscribe removes a resource subscription, if present
+func (sess *session) unsubscribe(uri string) {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	delete(sess.subscriptions, uri)
+}
+
+// isSubscribed reports whether the client subscribed to a resource
+func (sess *session) isSubscribed(uri string) bool {
+	sess.mu.Lock()
+	defer sess.mu.Unlock()
+	_, ok := sess.subscriptions[uri]
+	return ok
+}
+
+// close terminates the session and releases any attached stream
+func (sess *session) close() {
+	sess.closeOnce.Do(func() {
+		close(sess.done)
+	})
+}
+
+// createSession registers a new stateful session. When maxSessions are
+// already live, sessions past the idle timeout are ended to make room;
+// errTooManySessions is returned when none has expired, so new clients cannot
+// push out sessions that are still in use.
+func (s *MCPServer) createSession() (*session, error) {
+	id, err := newSessionID()
+	if err != nil {
+		return nil, err
+	}
+
+	s.reaperOnce.Do(func() { go s.reapSessions() })
+
+	sess := newSession(id)
+	s.sessionsMu.Lock()
+	var expired []*session
+	if len(s.sessions) >= s.maxSessions {
+		expired = s.expireIdleLocked(time.Now())
+	}
+	full := len(s.sessions) >= s.maxSessions
+	if !full {
+		s.sessions[id] = sess
+	}
+	s.sessionsMu.Unlock()
+
+	for _, sess := range expired {
+		sess.close()
+	}
+	if full {
+		return nil, errTooManySessions
+	}
+	return sess, nil
+}
+
+// expireIdleLocked unregisters the sessions that have been idle for longer
+// than the idle timeout and returns them, so they can be closed once
+// sessionsMu is released. sessionsMu must be held.
+func (s *MCPServer) expireIdleLocked(now time.Time) []*session {
+	var expired []*session
+	for id, sess := range s.sessions {
+		if seen, idle := sess.idleSince(); idle && now.Sub(seen) > s.sessionIdleTimeout {
+			expired = append(expired, sess)
+			delete(s.sessions, id)
+		}
+	}
+	return expired
+}
+
+// lookupSession returns the registered session with the given id and records
+// that the client used it
+func (s *MCPServer) lookupSession(id string) (*session, bool) {
+	s.sessionsMu.Lock()
+	defer s.sessionsMu.Unlock()
+	sess, ok := s.sessions[id]
+	if ok {
+		sess.touch(time.Now())
+	}
+	return sess, ok
+}
+
+// reapIdleSessions ends every session that has been idle for longer than the
+// idle timeout, as of now. Later requests naming them receive 404, telling the
+// client to initialize again.
+func (s *MCPServer) reapIdleSessions(now time.Time) {
+	s.sessionsMu.Lock()
+	expired := s.expireIdleLocked(now)
+	s.sessionsMu.Unlock()
+
+	for _, sess := range expired {
+		sess.close()
+	}
+}
+
+// reapSessions ends idle sessions periodically until the server is closed
+func (s *MCPServer) reapSessions() {
+	interval := max(s.sessionIdleTimeout/2, time.Second)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-s.closed:
+			return
+		case now := <-ticker.C:
+			s.reapIdleSessions(now)
+		}
+	}
+}
+
+// sessionList returns a snapshot of the registered sessions
+func (s *MCPServer) sessionList() []*session {
+	s.sessionsMu.Lock()
+	defer s.sessionsMu.Unlock()
+	sessions := make([]*session, 0, len(s.sessions))
+	for _, sess := range s.sessions {
+		sessions = append(sessions, sess)
+	}
+	return sessions
+}
+
+// removeSession unregisters and closes a session. It returns false when no such session exists.
+func (s *MCPServer) removeSession(id string) bool {
+	s.sessionsMu.Lock()
+	sess, ok := s.sessions[id]
+	delete(s.sessions, id)
+	s.sessionsMu.Unlock()
+
+	if ok {
+		sess.close()
+	}
+	return ok
+}
+
+// Close terminates every session, ending any open server-to-client streams so
+// that an HTTP server shutdown is not held up by long-lived connections, and
+// stops the idle session reaper
+func (s *MCPServer) Close() {
+	s.closeOnce.Do(func() { close(s.closed) })
+
+	s.sessionsMu.Lock()
+	sessions := s.sessions
+	s.sessions = make(map[string]*session)
+	s.sessionsMu.Unlock()
+
+	for _, sess := range sessions {
+		sess.close()
+	}
+}
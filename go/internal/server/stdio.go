@@ -0,0 +1,238 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// maxStdioConcurrency bounds how many stdio requests are processed at once
+const maxStdioConcurrency = 8
+
+// stdioTransport exchanges newline-delimited JSON-RPC messages over a pair of streams
+type stdioTransport struct {
+	session *session
+	reader  *bufio.Reader
+
+	mu       sync.Mutex
+	writer   io.Writer
+	writeErr error
+
+	requests sync.WaitGroup
+	slots    chan struct{}
+}
+
+// stdioLine is one line read from the input stream, with the error that ended
+// the read, if any
+type stdioLine struct {
+	data []byte
+	err  error
+}
+
+// ServeStdio serves MCP over newline-delimited JSON-RPC, reading requests from in
+// and writing responses to out until in is exhausted or ctx is cancelled.
+// Requests are processed concurrently, so responses may be written out of order.
+// Messages must not contain embedded newlines, as required by the MCP stdio transport.
+//
+// Cancelling ctx stops reading at once, even while a read is blocked, then
+// waits for requests already in flight to finish and returns nil. In-flight
+// requests are not cancelled by ctx; the call timeout still bounds them.
+func (s *MCPServer) ServeStdio(ctx context.Context, in io.Reader, out io.Writer) error {
+	// A stdio connection serves exactly one client, so it is a single session.
+	// It is registered with the server so it receives resource notifications.
+	sess, err := s.createSession()
+	if err != nil {
+		return err
+	}
+	t := &stdioTransport{
+		session: sess,
+		reader:  bufio.NewReader(in),
+		writer:  out,
+		slots:   make(chan struct{}, maxStdioConcurrency),
+	}
+
+	messages, _ := sess.attachStream()
+	var forwarding sync.WaitGroup
+	forwarding.Add(1)
+	go func() {
+		defer forwarding.Done()
+		t.forward(messages)
+	}()
+	defer func() {
+		t.requests.Wait()
+		s.removeSession(sess.id)
+		forwarding.Wait()
+	}()
+
+	// Reads block until a line arrives, so they run in the background where
+	// cancellation cannot be held up by them. After a shutdown the reader is
+	// left blocked on its last read; it exits once in is closed.
+	stopped := make(chan struct{})
+	defer close(stopped)
+	lines := make(chan stdioLine)
+	go t.read(lines, stopped)
+
+	requestCtx := context.WithoutCancel(ctx)
+	for {
+		if err := t.failure(); err != nil {
+			return err
+		}
+
+		var line stdioLine
+		select {
+		case <-ctx.Done():
+			return nil
+		case line = <-lines:
+		}
+
+		if len(bytes.TrimSpace(line.data)) > 0 {
+			t.handleLine(requestCtx, s, line.data)
+		}
+
+		if errors.Is(line.err, io.EOF) {
+			return nil
+		}
+		if line.err != nil {
+			return fmt.Errorf("failed to read stdio message: %w", line.err)
+		}
+	}
+}
+
+// read sends each line of the input stream to lines until a read fails or
+// stopped is closed
+func (t *stdioTransport) read(lines chan<- stdioLine, stopped <-chan struct{}) {
+	for {
+		data, err := t.reader.ReadBytes('\n')
+		select {
+		case lines <- stdioLine{data: data, err: err}:
+		case <-stopped:
+			return
+		}
+		if err != nil {
+			return
+		}
+	}
+}
+
+// handleLine decodes and dispatches a single message or batch. Requests run
+// in the background; notifications and initialize are handled before the next
+// line is read, so initialization completes before any later request starts.
+// A single request is registered with the session before it is dispatched, so
+// a cancellation on the very next line reaches it even if it has not started.
+func (t *stdioTransport) handleLine(ctx context.Context, s *MCPServer, line []byte) {
+	requests, invalid, isBatch, err := decodePayload(line)
+	if err != nil {
+		t.send(payloadErrorResponse(err))
+		return
+	}
+
+	if !isBatch {
+		req := requests[0]
+		if req.isNotification() || req.Method == "initialize" {
+			t.respond(s.handleMessage(ctx, t.session, req))
+			return
+		}
+		requestCtx, cancel := context.WithCancel(ctx)
+		if isValidID(req.ID) {
+			t.session.trackRequest(req.ID, cancel)
+		}
+		t.dispatch(func() {
+			defer cancel()
+			defer t.session.untrackRequest(req.ID)
+			t.respond(s.handleMessage(requestCtx, t.session, req))
+		})
+		return
+	}
+
+	if len(requests) == 0 && invalid == 0 {
+		t.send(newErrorResponse(nil, -32600, "Invalid Request", "empty batch"))
+		return
+	}
+
+	t.dispatch(func() {
+		if responses := s.handleBatch(ctx, t.session, requests, invalid); len(responses) > 0 {
+			t.send(responses)
+		}
+	})
+}
+
+// dispatch runs a request handler in the background, waiting for a free slot
+// when maxStdioConcurrency requests are already running
+func (t *stdioTransport) dispatch(handle func()) {
+	t.slots <- struct{}{}
+	t.requests.Add(1)
+	go func() {
+		defer t.requests.Done()
+		defer func() { <-t.slots }()
+		handle()
+	}()
+}
+
+// respond writes a response, if there is one
+func (t *stdioTransport) respond(response *MCPResponse) {
+	if response != nil {
+		t.send(response)
+	}
+}
+
+// send writes a message, recording the first failure so that ServeStdio can stop
+func (t *stdioTransport) send(message interface{}) {
+	if err := t.write(message); err != nil {
+		t.mu.Lock()
+		if t.writeErr == nil {
+			t.writeErr = err
+		}
+		t.mu.Unlock()
+	}
+}
+
+// failure returns the first write failure, if any
+func (t *stdioTransport) failure() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.writeErr
+}
+
+// forward writes server-initiated messages to the output stream until the
+// session ends, then flushes any messages still queued
+func (t *stdioTransport) forward(messages <-chan interface{}) {
+	for {
+		select {
+		case <-t.session.done:
+			for {
+				select {
+				case message := <-messages:
+					if err := t.write(message); err != nil {
+						return
+					}
+				default:
+					return
+				}
+			}
+		case message := <-messages:
+			if err := t.write(message); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// write encodes a message as a single line on the output stream
+func (t *stdioTransport) write(message interface{}) error {
+	encoded, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to encode stdio message: %w", err)
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, err := t.writer.Write(append(encoded, '\n')); err != nil {
+		return fmt.Errorf("failed to write stdio message: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,281 @@
+package server
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matpadley/MCPServer_Demo/go/internal/tools"
+)
+
+func serveStdio(t *testing.T, s *MCPServer, input string) (responses []MCPResponse, notifications []MCPNotification) {
+	var out bytes.Buffer
+	if err := s.ServeStdio(context.Background(), strings.NewReader(input), &out); err != nil {
+		t.Fatalf("ServeStdio failed: %v", err)
+	}
+
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		if line == "" {
+			continue
+		}
+		var notification MCPNotification
+		if err := json.Unmarshal([]byte(line), &notification); err == nil && notification.Method != "" {
+			notifications = append(notifications, notification)
+			continue
+		}
+		var resp MCPResponse
+		if err := json.Unmarshal([]byte(line), &resp); err != nil {
+			t.Fatalf("Failed to decode stdio line %q: %v", line, err)
+		}
+		responses = append(responses, resp)
+	}
+	return responses, notifications
+}
+
+func serveStdioLines(t *testing.T, s *MCPServer, input string) []MCPResponse {
+	responses, _ := serveStdio(t, s, input)
+	return responses
+}
+
+func TestServeStdio_Session(t *testing.T) {
+	s := createTestServer(t)
+
+	input := strings.Join([]string{
+		`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","capabilities":{},"clientInfo":{"name":"test","version":"1.0"}}}`,
+		`{"jsonrpc":"2.0","method":"notifications/initialized"}`,
+		``,
+		`{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"create_todo","arguments":{"description":"Stdio todo","createdDate":"2024-01-01T10:00:00Z"}}}`,
+		`{"jsonrpc":"2.0","id":3,"method":"tools/list"}`,
+	}, "\n")
+
+	responses := serveStdioLines(t, s, input)
+	if len(responses) != 3 {
+		t.Fatalf("Expected 3 responses, got %d", len(responses))
+	}
+	// Requests after initialize run concurrently, so responses may arrive in any order
+	sort.Slice(responses, func(i, j int) bool {
+		return responses[i].ID.(float64) < responses[j].ID.(float64)
+	})
+	for i, resp := range responses {
+		if resp.Error != nil {
+			t.Errorf("Response %d: unexpected error %+v", i, resp.Error)
+		}
+		if resp.ID != float64(i+1) {
+			t.Errorf("Response %d: expected id %d, got %v", i, i+1, resp.ID)
+		}
+	}
+
+	content := responses[1].Result.(map[string]interface{})["content"].([]interface{})
+	text := content[0].(map[string]interface{})["text"].(string)
+	if !strings.Contains(text, "Stdio todo") {
+		t.Errorf("Expected created todo in result, got %s", text)
+	}
+}
+
+func TestServeStdio_ParseError(t *testing.T) {
+	s := createTestServer(t)
+
+	responses := serveStdioLines(t, s, "not json\n"+`{"jsonrpc":"2.0","id":7,"method":"ping"}`+"\n")
+	if len(responses) != 2 {
+		t.Fatalf("Expected 2 responses, got %d", len(responses))
+	}
+	if responses[0].Error == nil || responses[0].Error.Code != -32700 {
+		t.Errorf("Expected parse error, got %+v", responses[0].Error)
+	}
+	if responses[1].Error != nil || responses[1].ID != float64(7) {
+		t.Errorf("Expected ping to succeed after parse error, got %+v", responses[1])
+	}
+
+	responses = serveStdioLines(t, s, "42\n")
+	if len(responses) != 1 || responses[0].Error == nil || responses[0].Error.Code != -32600 {
+		t.Errorf("Expected valid JSON that is not a request to be an invalid request, got %+v", responses)
+	}
+}
+
+func TestServeStdio_ResourceNotifications(t *testing.T) {
+	s := createTestServer(t)
+	client := startStdioClient(t.Context(), t, s)
+
+	client.send(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","capabilities":{},"clientInfo":{"name":"test","version":"1.0"}}}`)
+	client.next()
+	client.send(`{"jsonrpc":"2.0","method":"notifications/initialized"}`)
+	client.send(`{"jsonrpc":"2.0","id":2,"method":"resources/subscribe","params":{"uri":"todo://all"}}`)
+	if resp := client.next(); resp["id"] != float64(2) || resp["error"] != nil {
+		t.Fatalf("Subscribe failed: %v", resp)
+	}
+
+	client.send(`{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"create_todo","arguments":{"description":"Stdio todo","createdDate":"2024-01-01T10:00:00Z"}}}`)
+
+	// The response and the notifications are written independently
+	var methods []string
+	for i := 0; i < 3; i++ {
+		message := client.next()
+		if method, ok := message["method"].(string); ok {
+			methods = append(methods, method)
+		} else if message["id"] != float64(3) {
+			t.Errorf("Unexpected message %v", message)
+		}
+	}
+	sort.Strings(methods)
+	expected := "notifications/resources/list_changed,notifications/resources/updated"
+	if strings.Join(methods, ",") != expected {
+		t.Errorf("Expected %s, got %v", expected, methods)
+	}
+}
+
+func TestServeStdio_Cancellation(t *testing.T) {
+	s := createTestServer(t)
+	started := make(chan struct{})
+	registerBlockingTool(t, s, started)
+	client := startStdioClient(t.Context(), t, s)
+
+	client.send(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"block","arguments":{}}}`)
+	<-started
+	client.send(`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":1,"reason":"User cancelled"}}`)
+	client.send(`{"jsonrpc":"2.0","id":2,"method":"ping"}`)
+
+	if resp := client.next(); resp["id"] != float64(2) {
+		t.Errorf("Expected only the ping response, got %v", resp)
+	}
+	if remaining := client.close(); len(remaining) != 0 {
+		t.Errorf("Expected no response for the cancelled request, got %v", remaining)
+	}
+}
+
+func TestServeStdio_CancellationBeforeStart(t *testing.T) {
+	s := createTestServer(t)
+	registerBlockingTool(t, s, make(chan struct{}))
+	client := startStdioClient(t.Context(), t, s)
+
+	// The cancellation arrives before the request has had a chance to start
+	client.send(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"block","arguments":{}}}` + "\n" +
+		`{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":1}}`)
+	client.send(`{"jsonrpc":"2.0","id":2,"method":"ping"}`)
+
+	if resp := client.next(); resp["id"] != float64(2) {
+		t.Errorf("Expected only the ping response, got %v", resp)
+	}
+	closed := make(chan []string, 1)
+	go func() { closed <- client.close() }()
+	select {
+	case remaining := <-closed:
+		if len(remaining) != 0 {
+			t.Errorf("Expected no response for the cancelled request, got %v", remaining)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("The cancelled request is still running")
+	}
+}
+
+func TestServeStdio_ShutdownWhileReading(t *testing.T) {
+	s := createTestServer(t)
+	started, release := make(chan struct{}), make(chan struct{})
+	slow := tools.NewFuncTool("slow", "Slow", "Waits to be released.", tools.Annotations{ReadOnlyHint: true}, map[string]interface{}{"type": "object"}, func(ctx context.Context, args map[string]interface{}) (*tools.Result, error) {
+		close(started)
+		<-release
+		return tools.TextResult("done"), ctx.Err()
+	})
+	if err := s.RegisterTool(slow); err != nil {
+		t.Fatalf("Failed to register tool: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(t.Context())
+	client := startStdioClient(ctx, t, s)
+	client.send(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"slow","arguments":{}}}`)
+	<-started
+
+	// The input stays open, so the reader is blocked when the shutdown arrives
+	cancel()
+	select {
+	case err := <-client.done:
+		t.Fatalf("Expected ServeStdio to wait for the in-flight request, returned %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	if resp := client.next(); resp["id"] != float64(1) || resp["error"] != nil {
+		t.Errorf("Expected the in-flight request to complete, got %v", resp)
+	}
+	select {
+	case err := <-client.done:
+		if err != nil {
+			t.Errorf("Expected a clean shutdown, got %v", err)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("ServeStdio did not return after cancellation")
+	}
+}
+
+// stdioClient drives ServeStdio interactively through a pair of pipes
+type stdioClient struct {
+	t     *testing.T
+	in    *io.PipeWriter
+	lines chan string
+	done  chan error
+}
+
+func startStdioClient(ctx context.Context, t *testing.T, s *MCPServer) *stdioClient {
+	inReader, inWriter := io.Pipe()
+	outReader, outWriter := io.Pipe()
+	c := &stdioClient{t: t, in: inWriter, lines: make(chan string, 16), done: make(chan error, 1)}
+
+	go func() {
+		err := s.ServeStdio(ctx, inReader, outWriter)
+		outWriter.Close()
+		c.done <- err
+	}()
+	go func() {
+		scanner := bufio.NewScanner(outReader)
+		for scanner.Scan() {
+			c.lines <- scanner.Text()
+		}
+		close(c.lines)
+	}()
+
+	t.Cleanup(func() { inWriter.Close() })
+	return c
+}
+
+// send writes one message line to the server
+func (c *stdioClient) send(line string) {
+	if _, err := io.WriteString(c.in, line+"\n"); err != nil {
+		c.t.Fatalf("Failed to write stdio message: %v", err)
+	}
+}
+
+// next returns the next message written by the server
+func (c *stdioClient) next() map[string]interface{} {
+	select {
+	case line, ok := <-c.lines:
+		if !ok {
+			c.t.Fatal("Stdio output closed while waiting for a message")
+		}
+		var message map[string]interface{}
+		if err := json.Unmarshal([]byte(line), &message); err != nil {
+			c.t.Fatalf("Failed to decode stdio line %q: %v", line, err)
+		}
+		return message
+	case <-time.After(5 * time.Second):
+		c.t.Fatal("Timed out waiting for a stdio message")
+		return nil
+	}
+}
+
+// close ends the input, waits for ServeStdio to return and collects any remaining output
+func (c *stdioClient) close() []string {
+	c.in.Close()
+	var remaining []string
+	for line := range c.lines {
+		remaining = append(remaining, line)
+	}
+	if err := <-c.done; err != nil {
+		c.t.Errorf("ServeStdio failed: %v", err)
+	}
+	return remaining
+}
@@ -0,0 +1,359 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HTTP headers defined by the MCP Streamable HTTP transport
+const (
+	headerSessionID       = "Mcp-Session-Id"
+	headerProtocolVersion = "Mcp-Protocol-Version"
+)
+
+// sseKeepAliveInterval is how often an idle server-to-client stream receives a
+// comment line so that intermediaries do not time out the connection
+const sseKeepAliveInterval = 25 * time.Second
+
+// HandleMCP handles MCP protocol requests using the Streamable HTTP transport.
+// POST carries a client message or JSON-RPC batch and is answered with JSON or an SSE stream,
+// GET opens a server-to-client SSE stream and DELETE ends a session.
+func (s *MCPServer) HandleMCP(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Access-Control-Allow-Origin", "*")
+	w.Header().Set("Access-Control-Allow-Methods", "GET, POST, DELETE, OPTIONS")
+	w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Accept, Mcp-Session-Id, Mcp-Protocol-Version")
+	w.Header().Set("Access-Control-Expose-Headers", headerSessionID)
+
+	if r.Method == "OPTIONS" {
+		w.WriteHeader(http.StatusOK)
+		return
+	}
+
+	if version := r.Header.Get(headerProtocolVersion); version != "" && !isSupportedProtocolVersion(version) {
+		writeHTTPError(w, http.StatusBadRequest, -32000, fmt.Sprintf("Bad Request: unsupported protocol version %s", version))
+		return
+	}
+
+	switch r.Method {
+	case "POST":
+		s.handlePost(w, r)
+	case "GET":
+		s.handleStream(w, r)
+	case "DELETE":
+		s.handleDeleteSession(w, r)
+	default:
+		w.WriteHeader(http.StatusMethodNotAllowed)
+	}
+}
+
+// handlePost dispatches a client message or batch and writes the response as
+// JSON, or as an SSE stream when the client accepts text/event-stream
+func (s *MCPServer) handlePost(w http.ResponseWriter, r *http.Request) {
+	payload, err := io.ReadAll(r.Body)
+	if err != nil {
+		writeJSONResponse(w, newErrorResponse(nil, -32700, "Parse error", nil))
+		return
+	}
+
+	requests, invalid, isBatch, err := decodePayload(payload)
+	if err != nil {
+		writeJSONResponse(w, payloadErrorResponse(err))
+		return
+	}
+
+	if !isBatch {
+		s.handleSinglePost(w, r, requests[0])
+		return
+	}
+
+	if len(requests) == 0 && invalid == 0 {
+		writeJSONResponse(w, newErrorResponse(nil, -32600, "Invalid Request", "empty batch"))
+		return
+	}
+
+	sess, ok := s.resolvePostSession(w, r, false)
+	if !ok {
+		return
+	}
+
+	responses := s.handleBatch(r.Context(), sess, requests, invalid)
+	if len(responses) == 0 {
+		// A batch made up only of notifications gets no response body
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	if acceptsEventStream(r) {
+		if stream, ok := startEventStream(w); ok {
+			for _, response := range responses {
+				stream.writeEvent(response)
+			}
+			return
+		}
+	}
+
+	writeJSON(w, http.StatusOK, responses)
+}
+
+// handleSinglePost dispatches a single, non-batched client message
+func (s *MCPServer) handleSinglePost(w http.ResponseWriter, r *http.Request, req MCPRequest) {
+	initialize := req.Method == "initialize" && !req.isNotification()
+	sess, ok := s.resolvePostSession(w, r, initialize)
+	if !ok {
+		return
+	}
+
+	if !initialize && !req.isNotification() && acceptsEventStream(r) {
+		if stream, ok := startEventStream(w); ok {
+			s.streamResponse(r.Context(), stream, sess, req)
+			return
+		}
+	}
+
+	response := s.handleMessage(r.Context(), sess, req)
+
+	if initialize {
+		if response == nil || response.Error != nil {
+			s.removeSession(sess.id)
+		} else {
+			w.Header().Set(headerSessionID, sess.id)
+		}
+	}
+
+	if response == nil {
+		// Notifications and cancelled requests get no response body
+		w.WriteHeader(http.StatusAccepted)
+		return
+	}
+
+	if acceptsEventStream(r) {
+		if stream, ok := startEventStream(w); ok {
+			stream.writeEvent(response)
+			return
+		}
+	}
+	writeJSONResponse(w, response)
+}
+
+// streamResponse handles a request whose response is an SSE stream. Messages
+// about the request, such as progress notifications, are written to the stream
+// ahead of the response.
+func (s *MCPServer) streamResponse(ctx context.Context, stream *eventStream, sess *session, req MCPRequest) {
+	ctx = withNotificationSink(ctx, func(message interface{}) {
+		stream.writeEvent(message)
+	})
+	if response := s.handleMessage(ctx, sess, req); response != nil {
+		stream.writeEvent(response)
+	}
+}
+
+// resolvePostSession finds the session a POST belongs to. An initialize request
+// always starts a new session, requests carrying Mcp-Session-Id must name a live
+// session, and requests without one are served statelessly. Sessions ended
+// for being idle, like deleted ones, are answered with 404.
+func (s *MCPServer) resolvePostSession(w http.ResponseWriter, r *http.Request, initialize bool) (*session, bool) {
+	if initialize {
+		sess, err := s.createSession()
+		if errors.Is(err, errTooManySessions) {
+			writeHTTPError(w, http.StatusServiceUnavailable, -32000, "Service Unavailable: too many sessions")
+			return nil, false
+		}
+		if err != nil {
+			writeHTTPError(w, http.StatusInternalServerError, -32603, "Internal error")
+			return nil, false
+		}
+		return sess, true
+	}
+
+	id := r.Header.Get(headerSessionID)
+	if id == "" {
+		return newSession(""), true
+	}
+
+	sess, ok := s.lookupSession(id)
+	if !ok {
+		writeHTTPError(w, http.StatusNotFound, -32001, "Session not found")
+		return nil, false
+	}
+	return sess, true
+}
+
+// requireSession returns the session named by the Mcp-Session-Id header,
+// writing an error response when it is missing or unknown
+func (s *MCPServer) requireSession(w http.ResponseWriter, r *http.Request) (*session, bool) {
+	id := r.Header.Get(headerSessionID)
+	if id == "" {
+		writeHTTPError(w, http.StatusBadRequest, -32000, "Bad Request: Mcp-Session-Id header is required")
+		return nil, false
+	}
+
+	sess, ok := s.lookupSession(id)
+	if !ok {
+		writeHTTPError(w, http.StatusNotFound, -32001, "Session not found")
+		return nil, false
+	}
+	return sess, true
+}
+
+// handleStream opens a long-lived SSE stream carrying server-initiated messages for a session
+func (s *MCPServer) handleStream(w http.ResponseWriter, r *http.Request) {
+	if !acceptsEventStream(r) {
+		writeHTTPError(w, http.StatusNotAcceptable, -32000, "Not Acceptable: client must accept text/event-stream")
+		return
+	}
+
+	sess, ok := s.requireSession(w, r)
+	if !ok {
+		return
+	}
+
+	messages, ok := sess.attachStream()
+	if !ok {
+		writeHTTPError(w, http.StatusConflict, -32000, "Conflict: a stream is already open for this session")
+		return
+	}
+	defer sess.detachStream()
+
+	stream, ok := startEventStream(w)
+	if !ok {
+		return
+	}
+
+	keepAlive := time.NewTicker(sseKeepAliveInterval)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-sess.done:
+			return
+		case message := <-messages:
+			if err := stream.writeEvent(message); err != nil {
+				return
+			}
+		case <-keepAlive.C:
+			if err := stream.writeComment("keep-alive"); err != nil {
+				return
+			}
+		}
+	}
+}
+
+// handleDeleteSession ends a session at the client's request
+func (s *MCPServer) handleDeleteSession(w http.ResponseWriter, r *http.Request) {
+	sess, ok := s.requireSession(w, r)
+	if !ok {
+		return
+	}
+
+	s.removeSession(sess.id)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// eventStream writes Server-Sent Events to an HTTP response
+type eventStream struct {
+	mu      sync.Mutex
+	w       http.ResponseWriter
+	flusher http.Flusher
+}
+
+// startEventStream writes the SSE response headers. It returns false when the
+// response writer cannot flush incrementally.
+func startEventStream(w http.ResponseWriter) (*eventStream, bool) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		return nil, false
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	return &eventStream{w: w, flusher: flusher}, true
+}
+
+// writeEvent sends a JSON-RPC message as an SSE message event
+func (es *eventStream) writeEvent(message interface{}) error {
+	encoded, err := json.Marshal(message)
+	if err != nil {
+		return fmt.Errorf("failed to encode event: %w", err)
+	}
+
+	es.mu.Lock()
+	defer es.mu.Unlock()
+	if _, err := fmt.Fprintf(es.w, "event: message\ndata: %s\n\n", encoded); err != nil {
+		return err
+	}
+	es.flusher.Flush()
+	return nil
+}
+
+// writeComment sends an SSE comment line, which clients ignore
+func (es *eventStream) writeComment(comment string) error {
+	es.mu.Lock()
+	defer es.mu.Unlock()
+	if _, err := fmt.Fprintf(es.w, ": %s\n\n", comment); err != nil {
+		return err
+	}
+	es.flusher.Flush()
+	return nil
+}
+
+// acceptsEventStream reports whether the client listed text/event-stream in its Accept header
+func acceptsEventStream(r *http.Request) bool {
+	for _, accept := range r.Header.Values("Accept") {
+		for _, mediaType := range strings.Split(accept, ",") {
+			if strings.HasPrefix(strings.TrimSpace(mediaType), "text/event-stream") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// httpStatusForResponse maps a JSON-RPC response to an HTTP status. Payloads
+// that are not well-formed JSON-RPC are a client fault (400); every other
+// outcome, including method and tool errors, is a delivered response (200).
+func httpStatusForResponse(response *MCPResponse) int {
+	if response.Error != nil {
+		switch response.Error.Code {
+		case -32700, -32600:
+			return http.StatusBadRequest
+		}
+	}
+	return http.StatusOK
+}
+
+// writeJSONResponse writes an MCP response as a JSON body
+func writeJSONResponse(w http.ResponseWriter, response *MCPResponse) {
+	writeJSON(w, httpStatusForResponse(response), response)
+}
+
+// writeHTTPError writes a transport-level failure as a JSON-RPC error with no id
+func writeHTTPError(w http.ResponseWriter, status int, code int, message string) {
+	writeJSON(w, status, newErrorResponse(nil, code, message, nil))
+}
+
+// writeJSON encodes the body before committing the status, so an encoding
+// failure can still be reported as a 500 rather than a truncated response
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	encoded, err := json.Marshal(body)
+	if err != nil {
+		status = http.StatusInternalServerError
+		encoded, _ = json.Marshal(newErrorResponse(nil, -32603, "Internal error", nil))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(append(encoded, '\n'))
+}
@@ -0,0 +1,271 @@
+package server
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const initializeBody = `{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"protocolVersion":"2025-06-18","capabilities":{},"clientInfo":{"name":"test","version":"1.0"}}}`
+
+func initializeSession(t *testing.T, s *MCPServer) string {
+	rec := postMCP(t, s, initializeBody)
+	sessionID := rec.Header().Get(headerSessionID)
+	if sessionID == "" {
+		t.Fatalf("Expected %s header on initialize response", headerSessionID)
+	}
+	return sessionID
+}
+
+func TestStreamableHTTP_SessionLifecycle(t *testing.T) {
+	s := createTestServer(t)
+	sessionID := initializeSession(t, s)
+
+	req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(`{"jsonrpc":"2.0","id":2,"method":"ping"}`))
+	req.Header.Set(headerSessionID, sessionID)
+	rec := httptest.NewRecorder()
+	s.HandleMCP(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200 for request in session, got %d", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodDelete, "/mcp", nil)
+	req.Header.Set(headerSessionID, sessionID)
+	rec = httptest.NewRecorder()
+	s.HandleMCP(rec, req)
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("Expected status 204 for DELETE, got %d", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(`{"jsonrpc":"2.0","id":3,"method":"ping"}`))
+	req.Header.Set(headerSessionID, sessionID)
+	rec = httptest.NewRecorder()
+	s.HandleMCP(rec, req)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 after session deleted, got %d", rec.Code)
+	}
+}
+
+func TestStreamableHTTP_IdleSessionReaped(t *testing.T) {
+	s := createTestServer(t)
+	s.SetSessionLimits(time.Minute, DefaultMaxSessions)
+	idle := initializeSession(t, s)
+	active := initializeSession(t, s)
+
+	// Only the active session is used within the idle timeout
+	later := time.Now().Add(2 * time.Minute)
+	if sess, ok := s.lookupSession(active); ok {
+		sess.touch(later)
+	}
+	s.reapIdleSessions(later.Add(time.Second))
+
+	if rec := postInSession(s, idle, `{"jsonrpc":"2.0","id":2,"method":"ping"}`); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for a reaped session, got %d", rec.Code)
+	}
+	if rec := postInSession(s, active, `{"jsonrpc":"2.0","id":3,"method":"ping"}`); rec.Code != http.StatusOK {
+		t.Errorf("Expected status 200 for an active session, got %d", rec.Code)
+	}
+}
+
+func TestStreamableHTTP_SessionCap(t *testing.T) {
+	s := createTestServer(t)
+	s.SetSessionLimits(time.Minute, 2)
+	expired := initializeSession(t, s)
+	live := initializeSession(t, s)
+	sess, _ := s.lookupSession(expired)
+	sess.touch(time.Now().Add(-time.Hour))
+
+	// A session past the idle timeout makes room for a new one
+	newest := initializeSession(t, s)
+	if rec := postInSession(s, expired, `{"jsonrpc":"2.0","id":2,"method":"ping"}`); rec.Code != http.StatusNotFound {
+		t.Errorf("Expected status 404 for an expired session, got %d", rec.Code)
+	}
+
+	// Sessions that have not expired are never ended to make room, however
+	// many clients try to initialize
+	for i := 0; i < 5; i++ {
+		rec := postMCP(t, s, initializeBody)
+		if rec.Code != http.StatusServiceUnavailable {
+			t.Fatalf("Expected status 503 when no session has expired, got %d", rec.Code)
+		}
+		if resp := decodeResponse(t, rec); resp.Error == nil || resp.Error.Code != -32000 {
+			t.Errorf("Expected a JSON-RPC error, got %+v", resp)
+		}
+	}
+	for _, sessionID := range []string{live, newest} {
+		if rec := postInSession(s, sessionID, `{"jsonrpc":"2.0","id":3,"method":"ping"}`); rec.Code != http.StatusOK {
+			t.Errorf("Expected live session %s to survive, got status %d", sessionID, rec.Code)
+		}
+	}
+}
+
+func TestStreamableHTTP_StatelessRequest(t *testing.T) {
+	s := createTestServer(t)
+
+	rec := postMCP(t, s, `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", rec.Code)
+	}
+	if rec.Header().Get(headerSessionID) != "" {
+		t.Errorf("Expected no session id for stateless request")
+	}
+}
+
+func TestStreamableHTTP_UnsupportedProtocolVersionHeader(t *testing.T) {
+	s := createTestServer(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"ping"}`))
+	req.Header.Set(headerProtocolVersion, "1999-01-01")
+	rec := httptest.NewRecorder()
+	s.HandleMCP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400, got %d", rec.Code)
+	}
+}
+
+func TestStreamableHTTP_PostWithEventStream(t *testing.T) {
+	s := createTestServer(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(`{"jsonrpc":"2.0","id":5,"method":"ping"}`))
+	req.Header.Set("Accept", "application/json, text/event-stream")
+	rec := httptest.NewRecorder()
+	s.HandleMCP(rec, req)
+
+	if ct := rec.Header().Get("Content-Type"); ct != "text/event-stream" {
+		t.Fatalf("Expected text/event-stream, got %s", ct)
+	}
+	body := rec.Body.String()
+	if !strings.Contains(body, "event: message\n") || !strings.Contains(body, `"id":5`) {
+		t.Errorf("Expected SSE message event with response, got %q", body)
+	}
+}
+
+func TestStreamableHTTP_GetStreamRequiresSession(t *testing.T) {
+	s := createTestServer(t)
+
+	req := httptest.NewRequest(http.MethodGet, "/mcp", nil)
+	req.Header.Set("Accept", "text/event-stream")
+	rec := httptest.NewRecorder()
+	s.HandleMCP(rec, req)
+	if rec.Code != http.StatusBadRequest {
+		t.Errorf("Expected status 400 without session, got %d", rec.Code)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/mcp", nil)
+	rec = httptest.NewRecorder()
+	s.HandleMCP(rec, req)
+	if rec.Code != http.StatusNotAcceptable {
+		t.Errorf("Expected status 406 without event-stream Accept, got %d", rec.Code)
+	}
+}
+
+func TestStreamableHTTP_GetStreamDeliversMessages(t *testing.T) {
+	s := createTestServer(t)
+	httpServer := httptest.NewServer(http.HandlerFunc(s.HandleMCP))
+	defer httpServer.Close()
+	defer s.Close()
+
+	sessionID := initializeSession(t, s)
+
+	req, _ := http.NewRequest(http.MethodGet, httpServer.URL, nil)
+	req.Header.Set("Accept", "text/event-stream")
+	req.Header.Set(headerSessionID, sessionID)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatalf("GET stream failed: %v", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("Expected status 200, got %d", resp.StatusCode)
+	}
+
+	sess, _ := s.lookupSession(sessionID)
+	deadline := time.Now().Add(2 * time.Second)
+	for !sess.send(map[string]string{"jsonrpc": "2.0", "method": "notifications/test"}) {
+		if time.Now().After(deadline) {
+			t.Fatal("Stream was never attached")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	reader := bufio.NewReader(resp.Body)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatalf("Failed to read stream: %v", err)
+		}
+		if data, ok := strings.CutPrefix(line, "data: "); ok {
+			var message map[string]string
+			if err := json.Unmarshal([]byte(data), &message); err != nil {
+				t.Fatalf("Failed to decode event %q: %v", data, err)
+			}
+			if message["method"] != "notifications/test" {
+				t.Errorf("Expected notifications/test, got %v", message)
+			}
+			return
+		}
+	}
+}
+
+func postInSession(s *MCPServer, sessionID, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(body))
+	req.Header.Set(headerSessionID, sessionID)
+	rec := httptest.NewRecorder()
+	s.HandleMCP(rec, req)
+	return rec
+}
+
+func TestStreamableHTTP_CancelledNotification(t *testing.T) {
+	s := createTestServer(t)
+	started := make(chan struct{})
+	registerBlockingTool(t, s, started)
+	sessionID := initializeSession(t, s)
+
+	done := make(chan *httptest.ResponseRecorder)
+	go func() {
+		done <- postInSession(s, sessionID, `{"jsonrpc":"2.0","id":"call-1","method":"tools/call","params":{"name":"block","arguments":{}}}`)
+	}()
+	<-started
+
+	rec := postInSession(s, sessionID, `{"jsonrpc":"2.0","method":"notifications/cancelled","params":{"requestId":"call-1"}}`)
+	if rec.Code != http.StatusAccepted {
+		t.Errorf("Expected status 202 for the cancellation, got %d", rec.Code)
+	}
+
+	select {
+	case rec := <-done:
+		if rec.Code != http.StatusAccepted || rec.Body.Len() != 0 {
+			t.Errorf("Expected cancelled request to get 202 with no body, got %d %s", rec.Code, rec.Body.String())
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Cancelled request did not finish")
+	}
+}
+
+func TestStreamableHTTP_ClientDisconnect(t *testing.T) {
+	s := createTestServer(t)
+	started := make(chan struct{})
+	registerBlockingTool(t, s, started)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	req := httptest.NewRequest(http.MethodPost, "/mcp", strings.NewReader(`{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"block","arguments":{}}}`)).WithContext(ctx)
+
+	done := make(chan struct{})
+	go func() {
+		s.HandleMCP(httptest.NewRecorder(), req)
+		close(done)
+	}()
+	<-started
+	cancel()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Request kept running after the client disconnected")
+	}
+}
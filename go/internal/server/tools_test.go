@@ -0,0 +1,459 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matpadley/MCPServer_Demo/go/internal/data"
+	"github.com/matpadley/MCPServer_Demo/go/internal/tools"
+)
+
+func TestToolsList_FromRegistry(t *testing.T) {
+	s := createTestServer(t)
+
+	resp := decodeResponse(t, postMCP(t, s, `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+	if resp.Error != nil {
+		t.Fatalf("Unexpected error: %+v", resp.Error)
+	}
+
+	listed := resp.Result.(map[string]interface{})["tools"].([]interface{})
+	var names []string
+	for _, tool := range listed {
+		names = append(names, tool.(map[string]interface{})["name"].(string))
+	}
+
+	expected := []string{"create_todo", "read_todos", "update_todo", "delete_todo", "complete_todo", "reopen_todo", "get_agenda", "add_tags", "remove_tags", "list_tags", "create_project", "list_projects", "archive_project", "move_todo", "import_todos"}
+	if len(names) != len(expected) {
+		t.Fatalf("Expected tools %v, got %v", expected, names)
+	}
+	for i := range expected {
+		if names[i] != expected[i] {
+			t.Errorf("Expected tool %d to be %s, got %s", i, expected[i], names[i])
+		}
+	}
+}
+
+func TestRegisterTool_CustomTool(t *testing.T) {
+	s := createTestServer(t)
+
+	echo := tools.NewFuncTool("echo", "Echo", "Echoes the message argument.", tools.Annotations{ReadOnlyHint: true}, map[string]interface{}{"type": "object"}, func(ctx context.Context, args map[string]interface{}) (*tools.Result, error) {
+		message, _ := args["message"].(string)
+		return tools.TextResult(message), nil
+	})
+	if err := s.RegisterTool(echo); err != nil {
+		t.Fatalf("RegisterTool failed: %v", err)
+	}
+	if err := s.RegisterTool(echo); err == nil {
+		t.Error("Expected duplicate registration to fail")
+	}
+
+	resp := decodeResponse(t, postMCP(t, s, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"echo","arguments":{"message":"hello"}}}`))
+	if resp.Error != nil {
+		t.Fatalf("Unexpected error: %+v", resp.Error)
+	}
+	content := resp.Result.(map[string]interface{})["content"].([]interface{})
+	if text := content[0].(map[string]interface{})["text"]; text != "hello" {
+		t.Errorf("Expected 'hello', got %v", text)
+	}
+}
+
+func TestToolsCall_UnknownTool(t *testing.T) {
+	s := createTestServer(t)
+
+	resp := decodeResponse(t, postMCP(t, s, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"missing"}}`))
+	if resp.Error == nil || resp.Error.Code != -32602 {
+		t.Fatalf("Expected -32602 error, got %+v", resp.Error)
+	}
+	if resp.Error.Message != "Unknown tool: missing" {
+		t.Errorf("Unexpected message: %s", resp.Error.Message)
+	}
+}
+
+func TestToolsCall_InvalidArguments(t *testing.T) {
+	s := createTestServer(t)
+
+	resp := decodeResponse(t, postMCP(t, s, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"create_todo","arguments":{"description":"x"}}}`))
+	if resp.Error == nil || resp.Error.Code != -32602 {
+		t.Errorf("Expected -32602 error, got %+v", resp.Error)
+	}
+}
+
+func TestToolsCall_UnknownArgument(t *testing.T) {
+	s := createTestServer(t)
+
+	resp := decodeResponse(t, postMCP(t, s, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"create_todo","arguments":{"description":"x","createdDate":"2024-01-01T10:00:00Z","bogus":1}}}`))
+	if resp.Error == nil || resp.Error.Code != -32602 {
+		t.Fatalf("Expected -32602 for an unknown argument, got %+v", resp.Error)
+	}
+	if data, _ := json.Marshal(resp.Error.Data); !strings.Contains(string(data), `{"field":"bogus","message":"is not a known argument"}`) {
+		t.Errorf("Expected bogus to be reported, got %s", data)
+	}
+}
+
+func TestToolsCall_OutOfRangeAndNullArguments(t *testing.T) {
+	s := createTestServer(t)
+
+	resp := decodeResponse(t, postMCP(t, s, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"read_todos","arguments":{"limit":1e30,"tags":null}}}`))
+	if resp.Error == nil || resp.Error.Code != -32602 {
+		t.Fatalf("Expected -32602, got %+v", resp.Error)
+	}
+	data, _ := json.Marshal(resp.Error.Data)
+	for _, expected := range []string{`{"field":"limit","message":"must be between`, `{"field":"tags","message":"must not be null"}`} {
+		if !strings.Contains(string(data), expected) {
+			t.Errorf("Expected %s in %s", expected, data)
+		}
+	}
+	if strings.Contains(string(data), "json:") {
+		t.Errorf("Expected no decoder details, got %s", data)
+	}
+}
+
+func TestToolsCall_ValidationErrorData(t *testing.T) {
+	s := createTestServer(t)
+
+	resp := decodeResponse(t, postMCP(t, s, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"create_todo","arguments":{"description":42,"createdDate":"not a date"}}}`))
+	if resp.Error == nil || resp.Error.Code != -32602 {
+		t.Fatalf("Expected -32602 error, got %+v", resp.Error)
+	}
+
+	errs := resp.Error.Data.(map[string]interface{})["errors"].([]interface{})
+	fields := map[string]bool{}
+	for _, e := range errs {
+		fields[e.(map[string]interface{})["field"].(string)] = true
+	}
+	if !fields["description"] || !fields["createdDate"] {
+		t.Errorf("Expected errors for description and createdDate, got %v", errs)
+	}
+}
+
+func callToolResult(t *testing.T, s *MCPServer, body string) map[string]interface{} {
+	resp := decodeResponse(t, postMCP(t, s, body))
+	if resp.Error != nil {
+		t.Fatalf("Expected tool result, got protocol error %+v", resp.Error)
+	}
+	return resp.Result.(map[string]interface{})
+}
+
+func resultText(result map[string]interface{}) string {
+	content := result["content"].([]interface{})
+	return content[0].(map[string]interface{})["text"].(string)
+}
+
+func TestToolsCall_NotFoundIsToolError(t *testing.T) {
+	s := createTestServer(t)
+
+	result := callToolResult(t, s, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"update_todo","arguments":{"id":"5","description":"x"}}}`)
+	if result["isError"] != true {
+		t.Errorf("Expected isError result, got %v", result)
+	}
+	if text := resultText(result); text != "Todo with Id 5 not found." {
+		t.Errorf("Unexpected text: %s", text)
+	}
+}
+
+func TestToolsCall_InvalidIDIsToolError(t *testing.T) {
+	s := createTestServer(t)
+
+	result := callToolResult(t, s, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"delete_todo","arguments":{"id":"abc"}}}`)
+	if result["isError"] != true {
+		t.Errorf("Expected isError result, got %v", result)
+	}
+	if text := resultText(result); text != "Invalid todo id." {
+		t.Errorf("Unexpected text: %s", text)
+	}
+}
+
+func TestToolsCall_ReadTodosUnknownIDIsToolError(t *testing.T) {
+	s := createTestServer(t)
+
+	for id, expected := range map[string]string{"zzz": "Invalid todo id.", "7": "Todo with Id 7 not found."} {
+		result := callToolResult(t, s, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"read_todos","arguments":{"id":"`+id+`"}}}`)
+		if result["isError"] != true || resultText(result) != expected {
+			t.Errorf("%s: expected isError result %q, got %v", id, expected, result)
+		}
+	}
+}
+
+func TestToolsCall_DatabaseFailureIsToolError(t *testing.T) {
+	db := data.NewMemoryStore()
+	s := NewMCPServer(db)
+	db.Close()
+
+	result := callToolResult(t, s, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"create_todo","arguments":{"description":"x","createdDate":"2024-01-01T10:00:00Z"}}}`)
+	if result["isError"] != true {
+		t.Errorf("Expected isError result, got %v", result)
+	}
+}
+
+func TestToolsCall_SuccessHasNoErrorFlag(t *testing.T) {
+	s := createTestServer(t)
+
+	result := callToolResult(t, s, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"create_todo","arguments":{"description":"x","createdDate":"2024-01-01T10:00:00Z"}}}`)
+	if _, ok := result["isError"]; ok {
+		t.Errorf("Expected no isError field on success, got %v", result)
+	}
+}
+
+func TestToolsList_OutputSchemas(t *testing.T) {
+	s := createTestServer(t)
+
+	resp := decodeResponse(t, postMCP(t, s, `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+	for _, tool := range resp.Result.(map[string]interface{})["tools"].([]interface{}) {
+		descriptor := tool.(map[string]interface{})
+		outputSchema, ok := descriptor["outputSchema"].(map[string]interface{})
+		if !ok {
+			t.Errorf("Tool %v has no outputSchema", descriptor["name"])
+			continue
+		}
+		if outputSchema["type"] != "object" {
+			t.Errorf("Tool %v outputSchema must be an object, got %v", descriptor["name"], outputSchema["type"])
+		}
+	}
+}
+
+func TestToolsList_Annotations(t *testing.T) {
+	s := createTestServer(t)
+
+	expected := map[string]struct {
+		title                             string
+		readOnly, destructive, idempotent bool
+	}{
+		"create_todo":     {"Create todo", false, false, false},
+		"read_todos":      {"Read todos", true, false, true},
+		"update_todo":     {"Update todo", false, true, true},
+		"delete_todo":     {"Delete todo", false, true, true},
+		"complete_todo":   {"Complete todo", false, false, true},
+		"reopen_todo":     {"Reopen todo", false, false, true},
+		"get_agenda":      {"Get agenda", true, false, true},
+		"add_tags":        {"Add tags", false, false, true},
+		"remove_tags":     {"Remove tags", false, true, true},
+		"list_tags":       {"List tags", true, false, true},
+		"create_project":  {"Create project", false, false, false},
+		"list_projects":   {"List projects", true, false, true},
+		"archive_project": {"Archive project", false, false, true},
+		"move_todo":       {"Move todo", false, false, true},
+		"import_todos":    {"Import todos", false, false, false},
+	}
+
+	resp := decodeResponse(t, postMCP(t, s, `{"jsonrpc":"2.0","id":1,"method":"tools/list"}`))
+	for _, tool := range resp.Result.(map[string]interface{})["tools"].([]interface{}) {
+		descriptor := tool.(map[string]interface{})
+		name := descriptor["name"].(string)
+		want, ok := expected[name]
+		if !ok {
+			t.Errorf("Unexpected tool %s", name)
+			continue
+		}
+		if descriptor["title"] != want.title {
+			t.Errorf("Tool %s: expected title %q, got %v", name, want.title, descriptor["title"])
+		}
+		annotations, ok := descriptor["annotations"].(map[string]interface{})
+		if !ok {
+			t.Errorf("Tool %s has no annotations", name)
+			continue
+		}
+		if annotations["readOnlyHint"] != want.readOnly || annotations["destructiveHint"] != want.destructive || annotations["idempotentHint"] != want.idempotent {
+			t.Errorf("Tool %s: unexpected annotations %v", name, annotations)
+		}
+		if annotations["openWorldHint"] != false {
+			t.Errorf("Tool %s: expected openWorldHint false, got %v", name, annotations["openWorldHint"])
+		}
+	}
+}
+
+func TestToolsCall_StructuredContent(t *testing.T) {
+	s := createTestServer(t)
+
+	created := callToolResult(t, s, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"create_todo","arguments":{"description":"Structured","createdDate":"2024-01-01T10:00:00Z"}}}`)
+	todo := created["structuredContent"].(map[string]interface{})
+	if todo["id"] != float64(1) || todo["description"] != "Structured" {
+		t.Errorf("Unexpected created todo: %v", todo)
+	}
+
+	read := callToolResult(t, s, `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"read_todos"}}`)
+	todos := read["structuredContent"].(map[string]interface{})["todos"].([]interface{})
+	if len(todos) != 1 {
+		t.Fatalf("Expected 1 todo, got %d", len(todos))
+	}
+	if !strings.Contains(resultText(read), "Structured") {
+		t.Errorf("Expected text fallback to contain the todo, got %s", resultText(read))
+	}
+
+	updated := callToolResult(t, s, `{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"update_todo","arguments":{"id":"1","description":"Changed"}}}`)
+	if updated["structuredContent"].(map[string]interface{})["description"] != "Changed" {
+		t.Errorf("Expected updated todo in structured content, got %v", updated["structuredContent"])
+	}
+
+	deleted := callToolResult(t, s, `{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"delete_todo","arguments":{"id":"1"}}}`)
+	if deleted["structuredContent"].(map[string]interface{})["deleted"] != true {
+		t.Errorf("Expected deleted flag in structured content, got %v", deleted["structuredContent"])
+	}
+}
+
+func TestToolsCall_EmptyReadReturnsEmptyArray(t *testing.T) {
+	s := createTestServer(t)
+
+	read := callToolResult(t, s, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"read_todos"}}`)
+	if resultText(read) != "[]" {
+		t.Errorf("Expected empty JSON array text, got %s", resultText(read))
+	}
+}
+
+func TestToolsCall_CompleteAndFilterByStatus(t *testing.T) {
+	s := createTestServer(t)
+	seedTodos(t, s, "First", "Second")
+
+	completed := callToolResult(t, s, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"complete_todo","arguments":{"id":"2"}}}`)
+	todo := completed["structuredContent"].(map[string]interface{})
+	if todo["status"] != "done" || todo["completedAt"] == nil {
+		t.Errorf("Expected done todo with completedAt, got %v", todo)
+	}
+	if resultText(completed) != "Todo 2 completed." {
+		t.Errorf("Unexpected text: %s", resultText(completed))
+	}
+
+	for status, expected := range map[string]string{"open": "First", "done": "Second"} {
+		read := callToolResult(t, s, `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"read_todos","arguments":{"status":"`+status+`"}}}`)
+		todos := read["structuredContent"].(map[string]interface{})["todos"].([]interface{})
+		if len(todos) != 1 || todos[0].(map[string]interface{})["description"] != expected {
+			t.Errorf("Status %s: expected only %q, got %v", status, expected, todos)
+		}
+	}
+
+	resp := decodeResponse(t, postMCP(t, s, `{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"read_todos","arguments":{"status":"pending"}}}`))
+	if resp.Error == nil || resp.Error.Code != -32602 {
+		t.Errorf("Expected -32602 for an unknown status, got %+v", resp.Error)
+	}
+
+	reopened := callToolResult(t, s, `{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"reopen_todo","arguments":{"id":"2"}}}`)
+	todo = reopened["structuredContent"].(map[string]interface{})
+	if todo["status"] != "open" || todo["completedAt"] != nil {
+		t.Errorf("Expected reopened todo, got %v", todo)
+	}
+}
+
+func TestToolsCall_GetAgenda(t *testing.T) {
+	s := createTestServer(t)
+
+	yesterday := time.Now().UTC().AddDate(0, 0, -1).Format(time.DateOnly)
+	tomorrow := time.Now().UTC().AddDate(0, 0, 1).Format(time.DateOnly)
+	callToolResult(t, s, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"create_todo","arguments":{"description":"Late","createdDate":"2024-01-01T10:00:00Z","dueDate":"`+yesterday+`"}}}`)
+	callToolResult(t, s, `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"create_todo","arguments":{"description":"Soon","createdDate":"2024-01-01T10:00:00Z","dueDate":"`+tomorrow+`","dueTime":"12:00"}}}`)
+
+	result := callToolResult(t, s, `{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"get_agenda","arguments":{"timeZone":"UTC","days":3}}}`)
+	agenda := result["structuredContent"].(map[string]interface{})
+	overdue := agenda["overdue"].([]interface{})
+	upcoming := agenda["upcoming"].([]interface{})
+	if len(overdue) != 1 || len(upcoming) != 1 {
+		t.Fatalf("Expected one overdue and one upcoming todo, got %v", agenda)
+	}
+	if todo := overdue[0].(map[string]interface{})["todo"].(map[string]interface{}); todo["dueDate"] != yesterday {
+		t.Errorf("Expected overdue todo due %s, got %v", yesterday, todo)
+	}
+	if !strings.Contains(resultText(result), "Overdue:\n- #1 Late") {
+		t.Errorf("Expected text agenda to list the overdue todo, got %s", resultText(result))
+	}
+
+	for _, args := range []string{`{"timeZone":"Nowhere/Special"}`, `{"days":-1}`} {
+		resp := decodeResponse(t, postMCP(t, s, `{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"get_agenda","arguments":`+args+`}}`))
+		if resp.Error == nil || resp.Error.Code != -32602 {
+			t.Errorf("Args %s: expected -32602 error, got %+v", args, resp.Error)
+		}
+	}
+}
+
+// registerBlockingTool adds a "block" tool that signals started and then waits
+// until its call is cancelled
+func registerBlockingTool(t *testing.T, s *MCPServer, started chan<- struct{}) {
+	block := tools.NewFuncTool("block", "Block", "Blocks until cancelled.", tools.Annotations{ReadOnlyHint: true}, map[string]interface{}{"type": "object"}, func(ctx context.Context, args map[string]interface{}) (*tools.Result, error) {
+		close(started)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	if err := s.RegisterTool(block); err != nil {
+		t.Fatalf("Failed to register tool: %v", err)
+	}
+}
+
+func TestToolsCall_Tags(t *testing.T) {
+	s := createTestServer(t)
+	callToolResult(t, s, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"create_todo","arguments":{"description":"Report","createdDate":"2024-01-01T10:00:00Z","tags":["Work"," urgent "]}}}`)
+	callToolResult(t, s, `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"create_todo","arguments":{"description":"Groceries","createdDate":"2024-01-01T10:00:00Z"}}}`)
+
+	added := callToolResult(t, s, `{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"add_tags","arguments":{"id":"2","tags":["home","work"]}}}`)
+	if resultText(added) != "Todo 2 tags: home, work." {
+		t.Errorf("Unexpected text: %s", resultText(added))
+	}
+
+	for _, tt := range []struct {
+		arguments string
+		expected  int
+	}{
+		{`{"tags":["work"]}`, 2},
+		{`{"tags":["urgent","home"]}`, 2},
+		{`{"tags":["work","home"],"tagMatch":"all"}`, 1},
+	} {
+		read := callToolResult(t, s, `{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"read_todos","arguments":`+tt.arguments+`}}`)
+		if todos := read["structuredContent"].(map[string]interface{})["todos"].([]interface{}); len(todos) != tt.expected {
+			t.Errorf("%s: expected %d todos, got %v", tt.arguments, tt.expected, todos)
+		}
+	}
+
+	removed := callToolResult(t, s, `{"jsonrpc":"2.0","id":5,"method":"tools/call","params":{"name":"remove_tags","arguments":{"id":"1","tags":["URGENT"]}}}`)
+	if tags := removed["structuredContent"].(map[string]interface{})["tags"].([]interface{}); len(tags) != 1 || tags[0] != "work" {
+		t.Errorf("Expected only work to remain, got %v", tags)
+	}
+
+	listed := callToolResult(t, s, `{"jsonrpc":"2.0","id":6,"method":"tools/call","params":{"name":"list_tags","arguments":{}}}`)
+	tags := listed["structuredContent"].(map[string]interface{})["tags"].([]interface{})
+	if len(tags) != 2 || tags[1].(map[string]interface{})["name"] != "work" || tags[1].(map[string]interface{})["count"] != float64(2) {
+		t.Errorf("Expected home and work with counts, got %v", tags)
+	}
+
+	resp := decodeResponse(t, postMCP(t, s, `{"jsonrpc":"2.0","id":7,"method":"tools/call","params":{"name":"add_tags","arguments":{"id":"1","tags":["  "]}}}`))
+	if resp.Error == nil || resp.Error.Code != -32602 {
+		t.Errorf("Expected -32602 for a blank tag, got %+v", resp.Error)
+	}
+}
+
+func TestToolsCall_Projects(t *testing.T) {
+	s := createTestServer(t)
+	created := callToolResult(t, s, `{"jsonrpc":"2.0","id":1,"method":"tools/call","params":{"name":"create_project","arguments":{"name":"Garden"}}}`)
+	if resultText(created) != "Project created: Garden (Id: 1)" {
+		t.Errorf("Unexpected text: %s", resultText(created))
+	}
+
+	callToolResult(t, s, `{"jsonrpc":"2.0","id":2,"method":"tools/call","params":{"name":"create_todo","arguments":{"description":"Weed","createdDate":"2024-01-01T10:00:00Z","projectId":"1"}}}`)
+	callToolResult(t, s, `{"jsonrpc":"2.0","id":3,"method":"tools/call","params":{"name":"create_todo","arguments":{"description":"Groceries","createdDate":"2024-01-01T10:00:00Z"}}}`)
+
+	read := callToolResult(t, s, `{"jsonrpc":"2.0","id":4,"method":"tools/call","params":{"name":"read_todos","arguments":{"projectId":"1"}}}`)
+	todos := read["structuredContent"].(map[string]interface{})["todos"].([]interface{})
+	if len(todos) != 1 || todos[0].(map[string]interface{})["projectId"] != float64(1) {
+		t.Errorf("Expected only the todo in project 1, got %v", todos)
+	}
+
+	moved := callToolResult(t, s, `{"jsonrpc":"2.0","id":5,"method":"tools/call","params":{"name":"move_todo","arguments":{"id":"1"}}}`)
+	if resultText(moved) != "Todo 1 is no longer in a project." || moved["structuredContent"].(map[string]interface{})["projectId"] != nil {
+		t.Errorf("Expected the todo to leave its project, got %v", moved)
+	}
+
+	callToolResult(t, s, `{"jsonrpc":"2.0","id":6,"method":"tools/call","params":{"name":"archive_project","arguments":{"id":"1"}}}`)
+	listed := callToolResult(t, s, `{"jsonrpc":"2.0","id":7,"method":"tools/call","params":{"name":"list_projects","arguments":{}}}`)
+	if projects := listed["structuredContent"].(map[string]interface{})["projects"].([]interface{}); len(projects) != 0 {
+		t.Errorf("Expected archived projects to be hidden, got %v", projects)
+	}
+
+	for _, arguments := range []string{
+		`{"name":"move_todo","arguments":{"id":"2","projectId":"1"}}`,
+		`{"name":"create_project","arguments":{"name":"garden"}}`,
+		`{"name":"read_todos","arguments":{"projectId":"9"}}`,
+	} {
+		resp := decodeResponse(t, postMCP(t, s, `{"jsonrpc":"2.0","id":8,"method":"tools/call","params":`+arguments+`}`))
+		if resp.Error != nil || resp.Result.(map[string]interface{})["isError"] != true {
+			t.Errorf("%s: expected a tool error, got %+v", arguments, resp)
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/matpadley/MCPServer_Demo/go/internal/data"
+)
+
+// Window of the get_agenda tool, in days after today
+const (
+	defaultAgendaDays = 7
+	maxAgendaDays     = 90
+)
+
+// Agenda groups the open todos that have a due date into those overdue, due
+// later today and due within the following days, as seen from loc. Todos
+// without their own time zone are read in loc.
+func (t *TodosMcpTool) Agenda(ctx context.Context, loc *time.Location, days int) (*AgendaOutput, error) {
+	now := t.now().In(loc)
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, loc)
+	tomorrow := today.AddDate(0, 0, 1)
+	end := today.AddDate(0, 0, days+1)
+
+	// A todo's own time zone can move its due date by up to a day, so the
+	// query reaches one day past the window and the instants decide
+	todos, _, err := t.db.ListTodosAsync(ctx, data.TodoQuery{
+		Status:        data.StatusOpen,
+		DueOnOrBefore: end.Format(dueDateLayout),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error reading agenda: %w", err)
+	}
+
+	agenda := &AgendaOutput{
+		TimeZone: loc.String(),
+		Today:    today.Format(dueDateLayout),
+		Overdue:  []AgendaItem{},
+		DueToday: []AgendaItem{},
+		Upcoming: []AgendaItem{},
+	}
+	for _, todo := range todos {
+		due, ok := dueAt(todo, loc)
+		if !ok {
+			continue
+		}
+		item := AgendaItem{Todo: todo, DueAt: due.In(loc)}
+		switch {
+		case due.Before(now):
+			agenda.Overdue = append(agenda.Overdue, item)
+		case due.Before(tomorrow):
+			agenda.DueToday = append(agenda.DueToday, item)
+		case due.Before(end):
+			agenda.Upcoming = append(agenda.Upcoming, item)
+		}
+	}
+
+	for _, items := range [][]AgendaItem{agenda.Overdue, agenda.DueToday, agenda.Upcoming} {
+		// Todos arrive in id order, so a stable sort breaks ties by id
+		sort.SliceStable(items, func(i, j int) bool { return items[i].DueAt.Before(items[j].DueAt) })
+	}
+	return agenda, nil
+}
+
+// formatAgenda renders an agenda as text for clients without structured content
+func formatAgenda(agenda *AgendaOutput) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Agenda for %s (%s)\n", agenda.Today, agenda.TimeZone)
+	sections := []struct {
+		title string
+		items []AgendaItem
+	}{
+		{"Overdue", agenda.Overdue},
+		{"Due today", agenda.DueToday},
+		{"Upcoming", agenda.Upcoming},
+	}
+	for _, section := range sections {
+		fmt.Fprintf(&b, "\n%s:\n", section.title)
+		if len(section.items) == 0 {
+			b.WriteString("- none\n")
+			continue
+		}
+		for _, item := range section.items {
+			description := ""
+			if item.Todo.Description != nil {
+				description = *item.Todo.Description
+			}
+			fmt.Fprintf(&b, "- #%d %s (due %s)\n", item.Todo.ID, description, item.DueAt.Format("2006-01-02 15:04"))
+		}
+	}
+	return b.String()
+}
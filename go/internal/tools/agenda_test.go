@@ -0,0 +1,108 @@
+package tools
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/matpadley/MCPServer_Demo/go/internal/data"
+)
+
+func agendaIDs(items []AgendaItem) string {
+	ids := make([]int, 0, len(items))
+	for _, item := range items {
+		ids = append(ids, item.Todo.ID)
+	}
+	return fmt.Sprint(ids)
+}
+
+func TestAgenda_GroupsInCallersTimeZone(t *testing.T) {
+	db := createTestStore(t)
+	defer db.Close()
+
+	tool := NewTodosMcpTool(db)
+	// 23:30 on 1 March in New York is already 2 March in UTC
+	tool.now = func() time.Time { return time.Date(2024, 3, 2, 4, 30, 0, 0, time.UTC) }
+
+	due := func(description, date, clock, zone string) {
+		input := data.CreateTodoInput{Description: description, CreatedDate: time.Now(), DueDate: &date}
+		if clock != "" {
+			input.DueTime = &clock
+		}
+		if zone != "" {
+			input.DueTimeZone = &zone
+		}
+		if _, err := tool.CreateTodo(t.Context(), input); err != nil {
+			t.Fatalf("Failed to create todo: %v", err)
+		}
+	}
+	due("Yesterday", "2024-02-29", "", "")                    // 1: overdue
+	due("Earlier today", "2024-03-01", "20:00", "")           // 2: overdue
+	due("End of today", "2024-03-01", "", "")                 // 3: due today
+	due("Tokyo morning", "2024-03-02", "09:00", "Asia/Tokyo") // 4: 19:00 on 1 March in New York, overdue
+	due("Next week", "2024-03-08", "", "")                    // 5: upcoming
+	due("Too far", "2024-03-09", "", "")                      // 6: outside the window
+	due("Done", "2024-03-01", "", "")                         // 7: completed, never listed
+	if _, err := tool.CompleteTodo(t.Context(), "7"); err != nil {
+		t.Fatalf("Failed to complete todo: %v", err)
+	}
+	_, _ = tool.CreateTodoAsync(t.Context(), "No due date", time.Now()) // 8
+
+	newYork, _ := time.LoadLocation("America/New_York")
+	agenda, err := tool.Agenda(t.Context(), newYork, 7)
+	if err != nil {
+		t.Fatalf("Agenda failed: %v", err)
+	}
+
+	if agenda.Today != "2024-03-01" || agenda.TimeZone != "America/New_York" {
+		t.Errorf("Expected today 2024-03-01 in America/New_York, got %s in %s", agenda.Today, agenda.TimeZone)
+	}
+	if got := agendaIDs(agenda.Overdue); got != "[1 4 2]" {
+		t.Errorf("Expected overdue [1 4 2], got %s", got)
+	}
+	if got := agendaIDs(agenda.DueToday); got != "[3]" {
+		t.Errorf("Expected due today [3], got %s", got)
+	}
+	if got := agendaIDs(agenda.Upcoming); got != "[5]" {
+		t.Errorf("Expected upcoming [5], got %s", got)
+	}
+	if agenda.DueToday[0].DueAt.Location() != newYork {
+		t.Errorf("Expected due times in the caller's time zone, got %v", agenda.DueToday[0].DueAt.Location())
+	}
+}
+
+func TestValidateDue(t *testing.T) {
+	date, badDate, clock, badClock, zone, badZone := "2024-03-01", "01/03/2024", "09:30", "9.30am", "Europe/London", "Mars/Olympus"
+
+	tests := []struct {
+		date, clock, zone *string
+		expected          string
+	}{
+		{&date, &clock, &zone, "[]"},
+		{&badDate, nil, nil, "[{dueDate must be a date as YYYY-MM-DD}]"},
+		{&date, &badClock, &badZone, "[{dueTime must be a time of day as HH:MM} {dueTimeZone must be an IANA time zone such as Europe/London}]"},
+		{nil, &clock, &zone, "[{dueTime requires dueDate} {dueTimeZone requires dueDate}]"},
+	}
+
+	for _, tt := range tests {
+		fields := validateDue("", tt.date, tt.clock, tt.zone)
+		if got := fmt.Sprint(append([]FieldError{}, fields...)); got != tt.expected {
+			t.Errorf("Expected %s, got %s", tt.expected, got)
+		}
+	}
+}
+
+func TestCreateTodo_InvalidDueDate(t *testing.T) {
+	db := createTestStore(t)
+	defer db.Close()
+
+	tool := NewTodosMcpTool(db)
+	clock := "09:30"
+	_, err := tool.CreateTodo(t.Context(), data.CreateTodoInput{Description: "No date", CreatedDate: time.Now(), DueTime: &clock})
+
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) || validationErr.Fields[0].Field != "dueTime" {
+		t.Errorf("Expected validation error on dueTime, got %v", err)
+	}
+}
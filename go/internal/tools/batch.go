@@ -0,0 +1,26 @@
+package tools
+
+import "context"
+
+// BatchFunc holds back change notifications until the returned end function
+// is called, so that a tool making many changes announces them once
+type BatchFunc func() (end func())
+
+// batchKey is the context key of a call's BatchFunc
+type batchKey struct{}
+
+// WithBatch returns a context through which tool handlers batch their change
+// notifications with begin
+func WithBatch(ctx context.Context, begin BatchFunc) context.Context {
+	return context.WithValue(ctx, batchKey{}, begin)
+}
+
+// BeginBatch holds back change notifications until end is called. It does
+// nothing when the caller provided no BatchFunc, so handlers can call it
+// unconditionally.
+func BeginBatch(ctx context.Context) (end func()) {
+	if begin, ok := ctx.Value(batchKey{}).(BatchFunc); ok {
+		return begin()
+	}
+	return func() {}
+}
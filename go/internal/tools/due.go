@@ -0,0 +1,80 @@
+package tools
+
+import (
+	"errors"
+	"time"
+	// Embed the time zone database so due dates resolve on hosts without zoneinfo
+	_ "time/tzdata"
+
+	"github.com/matpadley/MCPServer_Demo/go/internal/data"
+)
+
+// Layouts of the due date and due time fields
+const (
+	dueDateLayout = "2006-01-02"
+	dueTimeLayout = "15:04"
+)
+
+// loadTimeZone resolves an IANA time zone name. "Local" and the empty name are
+// rejected, because they depend on the server's configuration rather than the
+// caller's.
+func loadTimeZone(name string) (*time.Location, error) {
+	if name == "" || name == "Local" {
+		return nil, errors.New("time zone must be an IANA name")
+	}
+	return time.LoadLocation(name)
+}
+
+// validateDue checks the due date fields of a todo, naming each offending
+// field with prefix. A due time or time zone needs a due date to qualify.
+func validateDue(prefix string, date, clock, zone *string) []FieldError {
+	var fields []FieldError
+	fail := func(field, message string) {
+		fields = append(fields, FieldError{Field: prefix + field, Message: message})
+	}
+
+	if date != nil {
+		if _, err := time.Parse(dueDateLayout, *date); err != nil {
+			fail("dueDate", "must be a date as YYYY-MM-DD")
+		}
+	}
+	if clock != nil {
+		if date == nil {
+			fail("dueTime", "requires dueDate")
+		} else if _, err := time.Parse(dueTimeLayout, *clock); err != nil {
+			fail("dueTime", "must be a time of day as HH:MM")
+		}
+	}
+	if zone != nil {
+		if date == nil {
+			fail("dueTimeZone", "requires dueDate")
+		} else if _, err := loadTimeZone(*zone); err != nil {
+			fail("dueTimeZone", "must be an IANA time zone such as Europe/London")
+		}
+	}
+	return fields
+}
+
+// dueAt resolves a todo's due date to an instant. The todo's own time zone is
+// used when it has one, otherwise loc. A todo without a due time is due at the
+// end of its due date. It reports false when the todo has no due date.
+func dueAt(todo data.Todo, loc *time.Location) (time.Time, bool) {
+	if todo.DueDate == nil {
+		return time.Time{}, false
+	}
+	if todo.DueTimeZone != nil {
+		if zone, err := loadTimeZone(*todo.DueTimeZone); err == nil {
+			loc = zone
+		}
+	}
+
+	if todo.DueTime != nil {
+		due, err := time.ParseInLocation(dueDateLayout+" "+dueTimeLayout, *todo.DueDate+" "+*todo.DueTime, loc)
+		return due, err == nil
+	}
+	day, err := time.ParseInLocation(dueDateLayout, *todo.DueDate, loc)
+	if err != nil {
+		return time.Time{}, false
+	}
+	return day.AddDate(0, 0, 1).Add(-time.Second), true
+}
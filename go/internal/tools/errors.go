@@ -0,0 +1,59 @@
+package tools
+
+import (
+	"fmt"
+)
+
+// NotFoundError reports that no todo exists with the requested id
+type NotFoundError struct {
+	ID int
+}
+
+func (e *NotFoundError) Error() string {
+	return fmt.Sprintf("Todo with Id %d not found.", e.ID)
+}
+
+// InvalidIDError reports a todo id that is not a valid integer
+type InvalidIDError struct {
+	Value string
+}
+
+func (e *InvalidIDError) Error() string {
+	return "Invalid todo id."
+}
+
+// ProjectNotFoundError reports that no project exists with the requested id
+type ProjectNotFoundError struct {
+	ID int
+}
+
+func (e *ProjectNotFoundError) Error() string {
+	return fmt.Sprintf("Project with Id %d not found.", e.ID)
+}
+
+// InvalidProjectIDError reports a project id that is not a valid integer
+type InvalidProjectIDError struct {
+	Value string
+}
+
+func (e *InvalidProjectIDError) Error() string {
+	return "Invalid project id."
+}
+
+// ProjectArchivedError reports an attempt to add a todo to an archived project
+type ProjectArchivedError struct {
+	ID int
+}
+
+func (e *ProjectArchivedError) Error() string {
+	return fmt.Sprintf("Project %d is archived and accepts no new todos.", e.ID)
+}
+
+// ProjectNameTakenError reports a project name already used by another project
+type ProjectNameTakenError struct {
+	Name string
+}
+
+func (e *ProjectNameTakenError) Error() string {
+	return fmt.Sprintf("A project named %q already exists.", e.Name)
+}
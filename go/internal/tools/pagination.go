@@ -0,0 +1,40 @@
+package tools
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// errInvalidCursor is returned when a cursor was not produced by EncodeCursor
+// for the same list
+var errInvalidCursor = errors.New("invalid cursor")
+
+// EncodeCursor turns a position in the list named by list into an opaque
+// pagination cursor. Clients must hand cursors back unchanged and never parse them.
+func EncodeCursor(list string, position int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(list + ":" + strconv.Itoa(position)))
+}
+
+// DecodeCursor returns the list position held by a cursor that EncodeCursor
+// made for the same list, so a cursor from one list is refused by another.
+// An empty cursor is the start of the list.
+func DecodeCursor(list, cursor string) (int, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, errInvalidCursor
+	}
+	name, value, ok := strings.Cut(string(raw), ":")
+	if !ok || name != list {
+		return 0, errInvalidCursor
+	}
+	position, err := strconv.Atoi(value)
+	if err != nil || position < 0 {
+		return 0, errInvalidCursor
+	}
+	return position, nil
+}
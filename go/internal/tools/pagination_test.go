@@ -0,0 +1,109 @@
+package tools
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/matpadley/MCPServer_Demo/go/internal/data"
+)
+
+func TestCursor_RoundTrip(t *testing.T) {
+	for _, position := range []int{0, 1, 42, 100000} {
+		decoded, err := DecodeCursor("tools/list", EncodeCursor("tools/list", position))
+		if err != nil || decoded != position {
+			t.Errorf("Position %d: decoded %d, %v", position, decoded, err)
+		}
+	}
+
+	if position, err := DecodeCursor("tools/list", ""); err != nil || position != 0 {
+		t.Errorf("Expected empty cursor to be position 0, got %d, %v", position, err)
+	}
+	// "MQ" is valid base64 for "1", which names no list
+	for _, cursor := range []string{"!!!", "MQ", EncodeCursor("tools/list", -1), EncodeCursor("resources/list", 1)} {
+		if _, err := DecodeCursor("tools/list", cursor); err == nil {
+			t.Errorf("Expected cursor %q to be rejected", cursor)
+		}
+	}
+}
+
+func readTodosTool(t *testing.T, count int) Tool {
+	db := createTestStore(t)
+	t.Cleanup(func() { db.Close() })
+
+	todosTool := NewTodosMcpTool(db)
+	for i := 1; i <= count; i++ {
+		if _, err := todosTool.CreateTodo(t.Context(), data.CreateTodoInput{Description: fmt.Sprintf("Todo %d", i), CreatedDate: time.Now()}); err != nil {
+			t.Fatalf("Failed to create todo: %v", err)
+		}
+	}
+	for _, tool := range todosTool.Tools() {
+		if tool.Name() == "read_todos" {
+			return tool
+		}
+	}
+	t.Fatal("read_todos tool not found")
+	return nil
+}
+
+func TestReadTodos_Pages(t *testing.T) {
+	tool := readTodosTool(t, 5)
+
+	var ids []int
+	args := map[string]interface{}{"limit": float64(2)}
+	for pages := 1; ; pages++ {
+		if pages > 5 {
+			t.Fatal("Pagination did not terminate")
+		}
+		result, err := tool.Call(t.Context(), args)
+		if err != nil {
+			t.Fatalf("Call failed: %v", err)
+		}
+		output := result.StructuredContent.(ReadTodosOutput)
+		for _, todo := range output.Todos {
+			ids = append(ids, todo.ID)
+		}
+
+		var text []map[string]interface{}
+		if err := json.Unmarshal([]byte(result.Content[0].Text), &text); err != nil || len(text) != len(output.Todos) {
+			t.Errorf("Expected the first text block to hold the page as JSON, got %q", result.Content[0].Text)
+		}
+
+		if output.NextCursor == "" {
+			if len(result.Content) != 1 {
+				t.Errorf("Expected no continuation text on the last page, got %v", result.Content)
+			}
+			break
+		}
+		if len(result.Content) != 2 {
+			t.Errorf("Expected a continuation text block, got %v", result.Content)
+		}
+		args = map[string]interface{}{"limit": float64(2), "cursor": output.NextCursor}
+	}
+
+	if fmt.Sprint(ids) != "[1 2 3 4 5]" {
+		t.Errorf("Expected every todo exactly once, got %v", ids)
+	}
+}
+
+func TestReadTodos_InvalidPagination(t *testing.T) {
+	tool := readTodosTool(t, 1)
+
+	tests := []struct {
+		args  map[string]interface{}
+		field string
+	}{
+		{map[string]interface{}{"cursor": "not-a-cursor"}, "cursor"},
+		{map[string]interface{}{"limit": float64(0)}, "limit"},
+	}
+
+	for _, tt := range tests {
+		_, err := tool.Call(t.Context(), tt.args)
+		var validationErr *ValidationError
+		if !errors.As(err, &validationErr) || validationErr.Fields[0].Field != tt.field {
+			t.Errorf("Args %v: expected validation error on %s, got %v", tt.args, tt.field, err)
+		}
+	}
+}
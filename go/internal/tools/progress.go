@@ -0,0 +1,24 @@
+package tools
+
+import "context"
+
+// ProgressFunc receives progress updates from a running tool call. total is
+// zero when the amount of work is unknown.
+type ProgressFunc func(progress, total float64, message string)
+
+// progressKey is the context key of a call's ProgressFunc
+type progressKey struct{}
+
+// WithProgress returns a context through which tool handlers report progress to report
+func WithProgress(ctx context.Context, report ProgressFunc) context.Context {
+	return context.WithValue(ctx, progressKey{}, report)
+}
+
+// ReportProgress reports how far a tool call has got. It does nothing when the
+// client did not ask for progress, so handlers can call it unconditionally.
+// progress must increase with every call.
+func ReportProgress(ctx context.Context, progress, total float64, message string) {
+	if report, ok := ctx.Value(progressKey{}).(ProgressFunc); ok {
+		report(progress, total, message)
+	}
+}
@@ -0,0 +1,104 @@
+package tools
+
+import (
+	"context"
+	"testing"
+)
+
+func TestReportProgress(t *testing.T) {
+	// Without a reporter, reporting progress is a no-op
+	ReportProgress(t.Context(), 1, 2, "ignored")
+
+	var reports []float64
+	ctx := WithProgress(t.Context(), func(progress, total float64, message string) {
+		reports = append(reports, progress, total)
+	})
+	ReportProgress(ctx, 1, 2, "half")
+
+	if len(reports) != 2 || reports[0] != 1 || reports[1] != 2 {
+		t.Errorf("Expected [1 2], got %v", reports)
+	}
+}
+
+func importTodosTool(t *testing.T) Tool {
+	db := createTestStore(t)
+	t.Cleanup(func() { db.Close() })
+
+	for _, tool := range NewTodosMcpTool(db).Tools() {
+		if tool.Name() == "import_todos" {
+			return tool
+		}
+	}
+	t.Fatal("import_todos tool not found")
+	return nil
+}
+
+func TestImportTodos_ReportsProgress(t *testing.T) {
+	tool := importTodosTool(t)
+
+	var messages []string
+	ctx := WithProgress(t.Context(), func(progress, total float64, message string) {
+		messages = append(messages, message)
+	})
+
+	result, err := tool.Call(ctx, map[string]interface{}{
+		"todos": []interface{}{
+			map[string]interface{}{"description": "First", "createdDate": "2024-01-01T10:00:00Z"},
+			map[string]interface{}{"description": "Second", "createdDate": "2024-01-01T11:00:00Z"},
+			map[string]interface{}{"description": "Third", "createdDate": "2024-01-01T12:00:00Z"},
+		},
+	})
+	if err != nil {
+		t.Fatalf("Call failed: %v", err)
+	}
+
+	output := result.StructuredContent.(ImportTodosOutput)
+	if len(output.Todos) != 3 || *output.Todos[2].Description != "Third" {
+		t.Errorf("Expected 3 imported todos, got %+v", output.Todos)
+	}
+	if len(messages) != 3 || messages[2] != "Imported 3 of 3 todos" {
+		t.Errorf("Expected a progress report per todo, got %v", messages)
+	}
+}
+
+func TestImportTodos_Cancelled(t *testing.T) {
+	tool := importTodosTool(t)
+
+	ctx, cancel := context.WithCancel(t.Context())
+	ctx = WithProgress(ctx, func(progress, total float64, message string) {
+		// Cancel once the first todo has been created
+		cancel()
+	})
+
+	_, err := tool.Call(ctx, map[string]interface{}{
+		"todos": []interface{}{
+			map[string]interface{}{"description": "First", "createdDate": "2024-01-01T10:00:00Z"},
+			map[string]interface{}{"description": "Second", "createdDate": "2024-01-01T11:00:00Z"},
+		},
+	})
+	if err == nil || err.Error() != "import stopped after 1 of 2 todos: context canceled" {
+		t.Errorf("Expected import to stop after the first todo, got %v", err)
+	}
+}
+
+func TestImportTodos_ValidatesItems(t *testing.T) {
+	tool := importTodosTool(t)
+
+	_, err := tool.Call(t.Context(), map[string]interface{}{
+		"todos": []interface{}{
+			map[string]interface{}{"description": "No date"},
+		},
+	})
+	validationErr, ok := err.(*ValidationError)
+	if !ok || validationErr.Fields[0].Field != "todos[0].createdDate" {
+		t.Errorf("Expected todos[0].createdDate to be required, got %v", err)
+	}
+}
+
+func TestImportedMessage(t *testing.T) {
+	for count, expected := range map[int]string{0: "Imported 0 todos.", 1: "Imported 1 todo.", 2: "Imported 2 todos."} {
+		if got := importedMessage(count); got != expected {
+			t.Errorf("Expected %q, got %q", expected, got)
+		}
+	}
+}
@@ -0,0 +1,215 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/matpadley/MCPServer_Demo/go/internal/data"
+)
+
+// maxProjectNameLength is the longest project name accepted, in characters
+const maxProjectNameLength = 100
+
+// parseProjectID converts a project id argument, returning an
+// *InvalidProjectIDError when it is not an integer
+func parseProjectID(value string) (int, error) {
+	id, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return 0, &InvalidProjectIDError{Value: value}
+	}
+	return id, nil
+}
+
+// CreateProject creates a project and returns it. It returns a
+// *ValidationError when the name is blank or too long, and a
+// *ProjectNameTakenError when another project has the name, ignoring ASCII case.
+func (t *TodosMcpTool) CreateProject(ctx context.Context, name string) (*data.Project, error) {
+	name = strings.TrimSpace(name)
+	switch {
+	case name == "":
+		return nil, &ValidationError{Fields: []FieldError{{Field: "name", Message: "must not be blank"}}}
+	case utf8.RuneCountInString(name) > maxProjectNameLength:
+		return nil, &ValidationError{Fields: []FieldError{{Field: "name", Message: fmt.Sprintf("must be at most %d characters", maxProjectNameLength)}}}
+	}
+
+	project, err := t.db.CreateProjectAsync(ctx, data.CreateProjectInput{Name: name, CreatedDate: t.now().UTC()})
+	if errors.Is(err, data.ErrProjectNameTaken) {
+		return nil, &ProjectNameTakenError{Name: name}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error creating project: %w", err)
+	}
+	return project, nil
+}
+
+// ListProjects returns the projects in id order, leaving out archived
+// projects unless includeArchived is set
+func (t *TodosMcpTool) ListProjects(ctx context.Context, includeArchived bool) ([]data.Project, error) {
+	projects, err := t.db.ReadProjectsAsync(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error listing projects: %w", err)
+	}
+
+	listed := []data.Project{}
+	for _, project := range projects {
+		if includeArchived || project.ArchivedAt == nil {
+			listed = append(listed, project)
+		}
+	}
+	return listed, nil
+}
+
+// ArchiveProject archives a project and returns it. Its todos stay in the
+// project. It returns an *InvalidProjectIDError or *ProjectNotFoundError when
+// the id does not name a project.
+func (t *TodosMcpTool) ArchiveProject(ctx context.Context, id string) (*data.Project, error) {
+	projectID, err := parseProjectID(id)
+	if err != nil {
+		return nil, err
+	}
+
+	archived, err := t.db.ArchiveProjectAsync(ctx, projectID, t.now().UTC())
+	if err != nil {
+		return nil, fmt.Errorf("error archiving project: %w", err)
+	}
+
+	if !archived {
+		return nil, &ProjectNotFoundError{ID: projectID}
+	}
+	return t.readProject(ctx, projectID)
+}
+
+// MoveTodo moves a todo into a project, or out of its project when projectID
+// is nil, and returns the todo. It returns an *InvalidIDError or
+// *NotFoundError when the id does not name a todo, and an
+// *InvalidProjectIDError, *ProjectNotFoundError or *ProjectArchivedError when
+// the project cannot take the todo. A todo already in the project is returned
+// unchanged, even when the project has since been archived.
+func (t *TodosMcpTool) MoveTodo(ctx context.Context, id string, projectID *string) (*data.Todo, error) {
+	todoID, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, &InvalidIDError{Value: id}
+	}
+
+	var target *int
+	if projectID != nil && strings.TrimSpace(*projectID) != "" {
+		parsed, err := parseProjectID(*projectID)
+		if err != nil {
+			return nil, err
+		}
+		target = &parsed
+	}
+
+	todo, err := t.readTodo(ctx, todoID)
+	if err != nil {
+		return nil, err
+	}
+	if sameProject(todo.ProjectID, target) {
+		return todo, nil
+	}
+	if target != nil {
+		if err := t.checkProjectOpen(ctx, *target); err != nil {
+			return nil, err
+		}
+	}
+
+	moved, err := t.db.MoveTodoAsync(ctx, todoID, target)
+	if err != nil {
+		return nil, fmt.Errorf("error moving todo: %w", err)
+	}
+
+	if !moved {
+		return nil, &NotFoundError{ID: todoID}
+	}
+	return t.readTodo(ctx, todoID)
+}
+
+// sameProject reports whether two optional project ids name the same project,
+// treating two nils as the same absence of a project
+func sameProject(a, b *int) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return *a == *b
+}
+
+// CompleteProjectIDs suggests up to MaxCompletionValues project ids for a
+// partially typed value, and returns how many projects match. Ids starting
+// with the value come first, followed by projects whose name contains it,
+// ignoring ASCII case. Archived projects are not suggested.
+func (t *TodosMcpTool) CompleteProjectIDs(ctx context.Context, value string) ([]string, int, error) {
+	projects, total, err := t.db.SearchProjectsAsync(ctx, strings.TrimSpace(value), MaxCompletionValues)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error completing project ids: %w", err)
+	}
+
+	ids := make([]string, 0, len(projects))
+	for _, project := range projects {
+		ids = append(ids, strconv.Itoa(project.ID))
+	}
+	return ids, total, nil
+}
+
+// readProject reads a project after a change, so callers get its stored state
+func (t *TodosMcpTool) readProject(ctx context.Context, id int) (*data.Project, error) {
+	projects, err := t.db.ReadProjectsAsync(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("error reading project: %w", err)
+	}
+	if len(projects) == 0 {
+		return nil, &ProjectNotFoundError{ID: id}
+	}
+	return &projects[0], nil
+}
+
+// checkProjectOpen returns a *ProjectNotFoundError or *ProjectArchivedError
+// unless the project exists and accepts todos
+func (t *TodosMcpTool) checkProjectOpen(ctx context.Context, id int) error {
+	project, err := t.readProject(ctx, id)
+	if err != nil {
+		return err
+	}
+	if project.ArchivedAt != nil {
+		return &ProjectArchivedError{ID: id}
+	}
+	return nil
+}
+
+// projectCreatedMessage describes a newly created project
+func projectCreatedMessage(project *data.Project) string {
+	return fmt.Sprintf("Project created: %s (Id: %d)", project.Name, project.ID)
+}
+
+// projectArchivedMessage describes an archived project
+func projectArchivedMessage(project *data.Project) string {
+	return fmt.Sprintf("Project %d archived.", project.ID)
+}
+
+// movedMessage describes a todo moved between projects
+func movedMessage(todo *data.Todo) string {
+	if todo.ProjectID == nil {
+		return fmt.Sprintf("Todo %d is no longer in a project.", todo.ID)
+	}
+	return fmt.Sprintf("Todo %d moved to project %d.", todo.ID, *todo.ProjectID)
+}
+
+// formatProjects renders projects as a list, marking the archived ones
+func formatProjects(projects []data.Project) string {
+	if len(projects) == 0 {
+		return "No projects."
+	}
+	var b strings.Builder
+	b.WriteString("Projects:\n")
+	for _, project := range projects {
+		if project.ArchivedAt != nil {
+			fmt.Fprintf(&b, "- #%d %s (archived)\n", project.ID, project.Name)
+			continue
+		}
+		fmt.Fprintf(&b, "- #%d %s\n", project.ID, project.Name)
+	}
+	return b.String()
+}
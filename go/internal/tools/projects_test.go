@@ -0,0 +1,163 @@
+package tools
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matpadley/MCPServer_Demo/go/internal/data"
+)
+
+func TestCreateProject(t *testing.T) {
+	db := createTestStore(t)
+	defer db.Close()
+
+	tool := NewTodosMcpTool(db)
+	project, err := tool.CreateProject(t.Context(), "  Garden ")
+	if err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	if project.ID != 1 || project.Name != "Garden" || project.ArchivedAt != nil {
+		t.Errorf("Unexpected project: %+v", project)
+	}
+
+	var nameTaken *ProjectNameTakenError
+	if _, err := tool.CreateProject(t.Context(), "GARDEN"); !errors.As(err, &nameTaken) {
+		t.Errorf("Expected ProjectNameTakenError, got %v", err)
+	}
+	for _, name := range []string{" ", strings.Repeat("x", maxProjectNameLength+1)} {
+		var validation *ValidationError
+		if _, err := tool.CreateProject(t.Context(), name); !errors.As(err, &validation) || validation.Fields[0].Field != "name" {
+			t.Errorf("%q: expected the name to be reported, got %v", name, err)
+		}
+	}
+}
+
+func TestArchiveProject(t *testing.T) {
+	db := createTestStore(t)
+	defer db.Close()
+
+	tool := NewTodosMcpTool(db)
+	archivedAt := time.Date(2024, 3, 1, 9, 0, 0, 0, time.UTC)
+	tool.now = func() time.Time { return archivedAt }
+	if _, err := tool.CreateProject(t.Context(), "Garden"); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	if _, err := tool.CreateProject(t.Context(), "Work"); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+
+	project, err := tool.ArchiveProject(t.Context(), "1")
+	if err != nil {
+		t.Fatalf("Failed to archive project: %v", err)
+	}
+	if project.ArchivedAt == nil || !project.ArchivedAt.Equal(archivedAt) {
+		t.Errorf("Expected archive time %v, got %v", archivedAt, project.ArchivedAt)
+	}
+
+	open, err := tool.ListProjects(t.Context(), false)
+	if err != nil {
+		t.Fatalf("Failed to list projects: %v", err)
+	}
+	if len(open) != 1 || open[0].Name != "Work" {
+		t.Errorf("Expected only Work, got %+v", open)
+	}
+	if all, _ := tool.ListProjects(t.Context(), true); len(all) != 2 {
+		t.Errorf("Expected both projects, got %+v", all)
+	}
+
+	var notFound *ProjectNotFoundError
+	if _, err := tool.ArchiveProject(t.Context(), "99"); !errors.As(err, &notFound) {
+		t.Errorf("Expected ProjectNotFoundError, got %v", err)
+	}
+	var invalidID *InvalidProjectIDError
+	if _, err := tool.ArchiveProject(t.Context(), "abc"); !errors.As(err, &invalidID) {
+		t.Errorf("Expected InvalidProjectIDError, got %v", err)
+	}
+}
+
+func TestMoveTodo(t *testing.T) {
+	db := createTestStore(t)
+	defer db.Close()
+
+	tool := NewTodosMcpTool(db)
+	if _, err := tool.CreateTodo(t.Context(), data.CreateTodoInput{Description: "Weed", CreatedDate: time.Now()}); err != nil {
+		t.Fatalf("Failed to create todo: %v", err)
+	}
+	for _, name := range []string{"Garden", "Old"} {
+		if _, err := tool.CreateProject(t.Context(), name); err != nil {
+			t.Fatalf("Failed to create project: %v", err)
+		}
+	}
+	if _, err := tool.ArchiveProject(t.Context(), "2"); err != nil {
+		t.Fatalf("Failed to archive project: %v", err)
+	}
+
+	projectID := "1"
+	todo, err := tool.MoveTodo(t.Context(), "1", &projectID)
+	if err != nil {
+		t.Fatalf("Failed to move todo: %v", err)
+	}
+	if todo.ProjectID == nil || *todo.ProjectID != 1 {
+		t.Errorf("Expected the todo in project 1, got %v", todo.ProjectID)
+	}
+
+	todo, err = tool.MoveTodo(t.Context(), "1", nil)
+	if err != nil {
+		t.Fatalf("Failed to move todo out of its project: %v", err)
+	}
+	if todo.ProjectID != nil {
+		t.Errorf("Expected the todo in no project, got %v", *todo.ProjectID)
+	}
+
+	archived := "2"
+	var archivedErr *ProjectArchivedError
+	if _, err := tool.MoveTodo(t.Context(), "1", &archived); !errors.As(err, &archivedErr) {
+		t.Errorf("Expected ProjectArchivedError, got %v", err)
+	}
+
+	// A todo already in an archived project stays there without an error
+	if _, err := tool.MoveTodo(t.Context(), "1", &projectID); err != nil {
+		t.Fatalf("Failed to move todo: %v", err)
+	}
+	if _, err := tool.ArchiveProject(t.Context(), "1"); err != nil {
+		t.Fatalf("Failed to archive project: %v", err)
+	}
+	todo, err = tool.MoveTodo(t.Context(), "1", &projectID)
+	if err != nil || todo.ProjectID == nil || *todo.ProjectID != 1 {
+		t.Errorf("Expected the todo to stay in project 1, got %+v (%v)", todo, err)
+	}
+	missing := "99"
+	var projectNotFound *ProjectNotFoundError
+	if _, err := tool.MoveTodo(t.Context(), "1", &missing); !errors.As(err, &projectNotFound) {
+		t.Errorf("Expected ProjectNotFoundError, got %v", err)
+	}
+	var notFound *NotFoundError
+	if _, err := tool.MoveTodo(t.Context(), "99", &projectID); !errors.As(err, &notFound) {
+		t.Errorf("Expected NotFoundError, got %v", err)
+	}
+}
+
+func TestImportTodos_ChecksProjects(t *testing.T) {
+	db := createTestStore(t)
+	defer db.Close()
+
+	tool := NewTodosMcpTool(db)
+	if _, err := tool.CreateProject(t.Context(), "Garden"); err != nil {
+		t.Fatalf("Failed to create project: %v", err)
+	}
+	garden, missing := "1", "7"
+	_, err := tool.callImportTodos(t.Context(), ImportTodosArgs{Todos: []CreateTodoArgs{
+		{Description: "Weed", CreatedDate: time.Now(), ProjectID: &garden},
+		{Description: "Lost", CreatedDate: time.Now(), ProjectID: &missing},
+	}})
+	var notFound *ProjectNotFoundError
+	if !errors.As(err, &notFound) || !strings.HasPrefix(err.Error(), "todos[1]: ") {
+		t.Fatalf("Expected todos[1] to name a missing project, got %v", err)
+	}
+
+	if todos, _ := db.ReadTodosAsync(t.Context()); len(todos) != 0 {
+		t.Errorf("Expected nothing to be imported, got %v", todos)
+	}
+}
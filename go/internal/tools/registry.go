@@ -0,0 +1,293 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// Tool describes an MCP tool and executes calls to it
+type Tool interface {
+	// Name is the unique identifier clients use to call the tool
+	Name() string
+	// Title is the human-readable name hosts show to the user
+	Title() string
+	// Description tells the model what the tool does
+	Description() string
+	// Annotations describe the tool's side effects, so hosts can decide
+	// which calls need the user's confirmation
+	Annotations() Annotations
+	// InputSchema is the JSON Schema of the tool's arguments
+	InputSchema() map[string]interface{}
+	// OutputSchema is the JSON Schema of the tool's structured content, or nil
+	// when the tool only returns unstructured content
+	OutputSchema() map[string]interface{}
+	// Call executes the tool with the arguments supplied by the client. ctx is
+	// cancelled when the client cancels the request or the call times out. A
+	// *ValidationError becomes a JSON-RPC invalid params error; any other error
+	// is a tool failure reported to the client through an isError result.
+	Call(ctx context.Context, args map[string]interface{}) (*Result, error)
+}
+
+// Annotations are hints about a tool's behaviour. Hosts use them to decide
+// when to ask the user before a call; they are not a security boundary.
+type Annotations struct {
+	// ReadOnlyHint is true when the tool does not modify its environment
+	ReadOnlyHint bool `json:"readOnlyHint"`
+	// DestructiveHint is true when the tool may overwrite or delete existing
+	// data. It is only meaningful when ReadOnlyHint is false.
+	DestructiveHint bool `json:"destructiveHint"`
+	// IdempotentHint is true when repeating a call with the same arguments
+	// has no additional effect. It is only meaningful when ReadOnlyHint is false.
+	IdempotentHint bool `json:"idempotentHint"`
+	// OpenWorldHint is true when the tool interacts with external systems,
+	// rather than only the server's own todo database
+	OpenWorldHint bool `json:"openWorldHint"`
+}
+
+// Completer is implemented by tools that can suggest values for their
+// arguments while the user is still typing them
+type Completer interface {
+	// Complete returns up to MaxCompletionValues ranked suggestions for
+	// argument given its partial value, and the number of values that match,
+	// or nil when the argument has no completions
+	Complete(ctx context.Context, argument, value string) ([]string, int, error)
+}
+
+// MaxCompletionValues is the most suggestions MCP allows in one completion result
+const MaxCompletionValues = 100
+
+// CompletionFunc suggests up to MaxCompletionValues values for an argument
+// from its partial value, and returns the number of values that match
+type CompletionFunc func(ctx context.Context, value string) ([]string, int, error)
+
+// Content is a single block of tool output
+type Content struct {
+	Type string `json:"type"`
+	Text string `json:"text,omitempty"`
+}
+
+// Result is the outcome of a tool call
+type Result struct {
+	Content           []Content   `json:"content"`
+	StructuredContent interface{} `json:"structuredContent,omitempty"`
+	IsError           bool        `json:"isError,omitempty"`
+}
+
+// TextResult creates a result holding a single text block
+func TextResult(text string) *Result {
+	return &Result{
+		Content: []Content{{Type: "text", Text: text}},
+	}
+}
+
+// StructuredResult creates a result carrying typed data that conforms to the
+// tool's output schema, plus a text rendering for clients that predate
+// structured content
+func StructuredResult(text string, structured interface{}) *Result {
+	return &Result{
+		Content:           []Content{{Type: "text", Text: text}},
+		StructuredContent: structured,
+	}
+}
+
+// ErrorResult creates a result reporting a tool failure, so the model can see
+// what went wrong and react to it
+func ErrorResult(err error) *Result {
+	return &Result{
+		Content: []Content{{Type: "text", Text: err.Error()}},
+		IsError: true,
+	}
+}
+
+// IsExpectedError reports whether err is a routine tool failure caused by the
+// request, such as an unknown id, rather than a fault in the server
+func IsExpectedError(err error) bool {
+	var notFound *NotFoundError
+	var invalidID *InvalidIDError
+	var projectNotFound *ProjectNotFoundError
+	var invalidProjectID *InvalidProjectIDError
+	var projectArchived *ProjectArchivedError
+	var nameTaken *ProjectNameTakenError
+	return errors.As(err, &notFound) || errors.As(err, &invalidID) ||
+		errors.As(err, &projectNotFound) || errors.As(err, &invalidProjectID) ||
+		errors.As(err, &projectArchived) || errors.As(err, &nameTaken)
+}
+
+// Handler executes a tool call with the raw arguments supplied by the client
+type Handler func(ctx context.Context, args map[string]interface{}) (*Result, error)
+
+// FuncTool adapts a handler function to the Tool interface
+type FuncTool struct {
+	name        string
+	title       string
+	description string
+	annotations Annotations
+	inputSchema map[string]interface{}
+	handler     Handler
+}
+
+// NewFuncTool creates a tool backed by a handler function
+func NewFuncTool(name, title, description string, annotations Annotations, inputSchema map[string]interface{}, handler Handler) *FuncTool {
+	return &FuncTool{
+		name:        name,
+		title:       title,
+		description: description,
+		annotations: annotations,
+		inputSchema: inputSchema,
+		handler:     handler,
+	}
+}
+
+// Name returns the tool name
+func (t *FuncTool) Name() string { return t.name }
+
+// Title returns the human-readable tool name
+func (t *FuncTool) Title() string { return t.title }
+
+// Description returns the tool description
+func (t *FuncTool) Description() string { return t.description }
+
+// Annotations returns the tool's behaviour hints
+func (t *FuncTool) Annotations() Annotations { return t.annotations }
+
+// InputSchema returns the JSON Schema of the tool's arguments
+func (t *FuncTool) InputSchema() map[string]interface{} { return t.inputSchema }
+
+// OutputSchema returns nil, as function tools return unstructured content
+func (t *FuncTool) OutputSchema() map[string]interface{} { return nil }
+
+// Call invokes the handler
+func (t *FuncTool) Call(ctx context.Context, args map[string]interface{}) (*Result, error) {
+	return t.handler(ctx, args)
+}
+
+// TypedTool is a tool whose arguments are declared by the struct type A. Its
+// input schema is derived from A, and each call's arguments are validated
+// against that schema and decoded into an A before the handler runs.
+type TypedTool[A any] struct {
+	name         string
+	title        string
+	description  string
+	annotations  Annotations
+	inputSchema  map[string]interface{}
+	outputSchema map[string]interface{}
+	completions  map[string]CompletionFunc
+	handler      func(ctx context.Context, args A) (*Result, error)
+}
+
+// NewTypedTool creates a tool whose arguments are declared by the struct type A
+func NewTypedTool[A any](name, title, description string, annotations Annotations, handler func(ctx context.Context, args A) (*Result, error)) *TypedTool[A] {
+	var zero A
+	return &TypedTool[A]{
+		name:        name,
+		title:       title,
+		description: description,
+		annotations: annotations,
+		inputSchema: SchemaFor(zero),
+		handler:     handler,
+	}
+}
+
+// Name returns the tool name
+func (t *TypedTool[A]) Name() string { return t.name }
+
+// Title returns the human-readable tool name
+func (t *TypedTool[A]) Title() string { return t.title }
+
+// Description returns the tool description
+func (t *TypedTool[A]) Description() string { return t.description }
+
+// Annotations returns the tool's behaviour hints
+func (t *TypedTool[A]) Annotations() Annotations { return t.annotations }
+
+// InputSchema returns the JSON Schema derived from A
+func (t *TypedTool[A]) InputSchema() map[string]interface{} { return t.inputSchema }
+
+// WithOutput declares the tool's structured content, deriving the output
+// schema from the Go type of v
+func (t *TypedTool[A]) WithOutput(v interface{}) *TypedTool[A] {
+	t.outputSchema = SchemaFor(v)
+	return t
+}
+
+// OutputSchema returns the JSON Schema of the tool's structured content
+func (t *TypedTool[A]) OutputSchema() map[string]interface{} { return t.outputSchema }
+
+// WithCompletion registers a completion function for one of the tool's arguments
+func (t *TypedTool[A]) WithCompletion(argument string, complete CompletionFunc) *TypedTool[A] {
+	if t.completions == nil {
+		t.completions = make(map[string]CompletionFunc)
+	}
+	t.completions[argument] = complete
+	return t
+}
+
+// Complete suggests values for an argument registered with WithCompletion
+func (t *TypedTool[A]) Complete(ctx context.Context, argument, value string) ([]string, int, error) {
+	complete, ok := t.completions[argument]
+	if !ok {
+		return nil, 0, nil
+	}
+	return complete(ctx, value)
+}
+
+// Call validates and decodes the arguments, then invokes the handler
+func (t *TypedTool[A]) Call(ctx context.Context, args map[string]interface{}) (*Result, error) {
+	var decoded A
+	if err := decodeArguments(t.inputSchema, args, &decoded); err != nil {
+		return nil, err
+	}
+	return t.handler(ctx, decoded)
+}
+
+// Registry holds the tools a server exposes, in registration order
+type Registry struct {
+	mu    sync.RWMutex
+	tools map[string]Tool
+	order []string
+}
+
+// NewRegistry creates an empty tool registry
+func NewRegistry() *Registry {
+	return &Registry{tools: make(map[string]Tool)}
+}
+
+// Register adds a tool, rejecting duplicate names and tools without a title
+func (r *Registry) Register(tool Tool) error {
+	if tool.Name() == "" {
+		return fmt.Errorf("tool name must not be empty")
+	}
+	if tool.Title() == "" {
+		return fmt.Errorf("tool %q must have a title", tool.Name())
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.tools[tool.Name()]; exists {
+		return fmt.Errorf("tool %q is already registered", tool.Name())
+	}
+	r.tools[tool.Name()] = tool
+	r.order = append(r.order, tool.Name())
+	return nil
+}
+
+// Lookup returns the tool registered under name
+func (r *Registry) Lookup(name string) (Tool, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	tool, ok := r.tools[name]
+	return tool, ok
+}
+
+// List returns every registered tool in registration order
+func (r *Registry) List() []Tool {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	tools := make([]Tool, 0, len(r.order))
+	for _, name := range r.order {
+		tools = append(tools, r.tools[name])
+	}
+	return tools
+}
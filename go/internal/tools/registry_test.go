@@ -0,0 +1,106 @@
+package tools
+
+import (
+	"context"
+	"testing"
+)
+
+func newTestTool(name string) Tool {
+	return NewFuncTool(name, "Test", "Test tool", Annotations{ReadOnlyHint: true}, map[string]interface{}{"type": "object"}, func(ctx context.Context, args map[string]interface{}) (*Result, error) {
+		return TextResult(name), nil
+	})
+}
+
+func TestRegistry_RegisterAndLookup(t *testing.T) {
+	registry := NewRegistry()
+
+	if err := registry.Register(newTestTool("first")); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if err := registry.Register(newTestTool("second")); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	tool, ok := registry.Lookup("second")
+	if !ok {
+		t.Fatal("Expected to find tool 'second'")
+	}
+	result, err := tool.Call(t.Context(), nil)
+	if err != nil {
+		t.Fatalf("Call failed: %v", err)
+	}
+	if result.Content[0].Text != "second" {
+		t.Errorf("Expected 'second', got %s", result.Content[0].Text)
+	}
+
+	if _, ok := registry.Lookup("missing"); ok {
+		t.Error("Expected lookup of unregistered tool to fail")
+	}
+}
+
+func TestRegistry_DuplicateName(t *testing.T) {
+	registry := NewRegistry()
+
+	if err := registry.Register(newTestTool("dup")); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if err := registry.Register(newTestTool("dup")); err == nil {
+		t.Error("Expected error registering duplicate tool name")
+	}
+	if err := registry.Register(newTestTool("")); err == nil {
+		t.Error("Expected error registering empty tool name")
+	}
+}
+
+func TestRegistry_RequiresTitle(t *testing.T) {
+	registry := NewRegistry()
+
+	untitled := NewFuncTool("untitled", "", "No title", Annotations{}, map[string]interface{}{"type": "object"}, func(ctx context.Context, args map[string]interface{}) (*Result, error) {
+		return TextResult("ok"), nil
+	})
+	if err := registry.Register(untitled); err == nil {
+		t.Error("Expected error registering a tool without a title")
+	}
+}
+
+func TestRegistry_ListPreservesOrder(t *testing.T) {
+	registry := NewRegistry()
+	names := []string{"c", "a", "b"}
+	for _, name := range names {
+		registry.Register(newTestTool(name))
+	}
+
+	tools := registry.List()
+	if len(tools) != len(names) {
+		t.Fatalf("Expected %d tools, got %d", len(names), len(tools))
+	}
+	for i, tool := range tools {
+		if tool.Name() != names[i] {
+			t.Errorf("Expected tool %d to be %s, got %s", i, names[i], tool.Name())
+		}
+	}
+}
+
+func TestTypedTool_Complete(t *testing.T) {
+	tool := NewTypedTool("complete", "Complete", "Completes", Annotations{}, func(ctx context.Context, args struct {
+		ID string `json:"id"`
+	}) (*Result, error) {
+		return TextResult("ok"), nil
+	}).WithCompletion("id", func(ctx context.Context, value string) ([]string, int, error) {
+		return []string{value + "1", value + "2"}, 5, nil
+	})
+
+	var completer Completer = tool
+	values, total, err := completer.Complete(t.Context(), "id", "4")
+	if err != nil {
+		t.Fatalf("Complete failed: %v", err)
+	}
+	if len(values) != 2 || values[0] != "41" || values[1] != "42" || total != 5 {
+		t.Errorf("Expected [41 42] of 5, got %v of %d", values, total)
+	}
+
+	values, _, err = completer.Complete(t.Context(), "other", "4")
+	if err != nil || values != nil {
+		t.Errorf("Expected no completions for other argument, got %v, %v", values, err)
+	}
+}
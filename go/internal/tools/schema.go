@@ -0,0 +1,348 @@
+package tools
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// timeType is encoded as an RFC 3339 date-time string
+var timeType = reflect.TypeOf(time.Time{})
+
+// maxSafeInteger is the largest integer a JSON number holds exactly, and so the
+// largest magnitude an integer argument may have
+const maxSafeInteger = 1<<53 - 1
+
+// SchemaFor derives a JSON Schema from the Go type of v, which is normally a
+// struct describing a tool's arguments. Struct fields are named by their json
+// tag and may carry these additional tags:
+//
+//	description:"..."  describes the field to the model
+//	required:"true"    the argument must be supplied
+//	format:"..."       a JSON Schema string format such as date-time
+//	enum:"a,b,c"       the comma-separated values a string may take
+func SchemaFor(v interface{}) map[string]interface{} {
+	return schemaForType(reflect.TypeOf(v))
+}
+
+// schemaForType derives the JSON Schema of a Go type
+func schemaForType(t reflect.Type) map[string]interface{} {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t == timeType {
+		return map[string]interface{}{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]interface{}{"type": "string"}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]interface{}{"type": "array", "items": schemaForType(t.Elem())}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object"}
+	case reflect.Struct:
+		return schemaForStruct(t)
+	default:
+		return map[string]interface{}{}
+	}
+}
+
+// schemaForStruct derives an object schema from exported struct fields
+func schemaForStruct(t reflect.Type) map[string]interface{} {
+	properties := map[string]interface{}{}
+	required := []string{}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		name, ok := jsonFieldName(field)
+		if !ok {
+			continue
+		}
+
+		property := schemaForType(field.Type)
+		if typ, ok := property["type"].(string); ok && field.Type.Kind() == reflect.Pointer && !hasJSONOption(field, "omitempty") {
+			// A nil pointer without omitempty is encoded as null
+			property["type"] = []string{typ, "null"}
+		}
+		if description := field.Tag.Get("description"); description != "" {
+			property["description"] = description
+		}
+		if format := field.Tag.Get("format"); format != "" {
+			property["format"] = format
+		}
+		if enum := field.Tag.Get("enum"); enum != "" {
+			property["enum"] = strings.Split(enum, ",")
+		}
+		properties[name] = property
+
+		if field.Tag.Get("required") == "true" {
+			required = append(required, name)
+		}
+	}
+
+	schema := map[string]interface{}{
+		"type":                 "object",
+		"properties":           properties,
+		"additionalProperties": false,
+	}
+	if len(required) > 0 {
+		schema["required"] = required
+	}
+	return schema
+}
+
+// jsonFieldName returns the JSON property name of a struct field, or false
+// when the field is unexported or excluded from JSON
+func jsonFieldName(field reflect.StructField) (string, bool) {
+	if !field.IsExported() {
+		return "", false
+	}
+	tag := field.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "" {
+		name = field.Name
+	}
+	return name, true
+}
+
+// hasJSONOption reports whether a struct field's json tag carries option
+func hasJSONOption(field reflect.StructField, option string) bool {
+	_, options, _ := strings.Cut(field.Tag.Get("json"), ",")
+	for _, candidate := range strings.Split(options, ",") {
+		if candidate == option {
+			return true
+		}
+	}
+	return false
+}
+
+// FieldError describes a single argument that failed validation
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError reports every argument that failed schema validation
+type ValidationError struct {
+	Fields []FieldError
+}
+
+func (e *ValidationError) Error() string {
+	parts := make([]string, 0, len(e.Fields))
+	for _, field := range e.Fields {
+		parts = append(parts, fmt.Sprintf("%s: %s", field.Field, field.Message))
+	}
+	return "invalid arguments: " + strings.Join(parts, "; ")
+}
+
+// ValidateArguments checks decoded JSON arguments against a schema produced
+// by SchemaFor, returning a ValidationError listing every offending field
+func ValidateArguments(schema map[string]interface{}, args map[string]interface{}) error {
+	var errs []FieldError
+	validateValue(schema, args, "", &errs)
+	if len(errs) > 0 {
+		return &ValidationError{Fields: errs}
+	}
+	return nil
+}
+
+// validateValue appends a FieldError for each way value violates schema
+func validateValue(schema map[string]interface{}, value interface{}, path string, errs *[]FieldError) {
+	fail := func(message string) {
+		field := path
+		if field == "" {
+			field = "arguments"
+		}
+		*errs = append(*errs, FieldError{Field: field, Message: message})
+	}
+
+	if value == nil {
+		if !allowsNull(schema) {
+			fail("must not be null")
+		}
+		return
+	}
+
+	switch schemaType(schema) {
+	case "string":
+		str, ok := value.(string)
+		if !ok {
+			fail("must be a string")
+			return
+		}
+		if message := checkFormat(schema["format"], str); message != "" {
+			fail(message)
+		}
+		if enum, ok := schema["enum"].([]string); ok && !containsString(enum, str) {
+			fail(fmt.Sprintf("must be one of %s", strings.Join(enum, ", ")))
+		}
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			fail("must be a boolean")
+		}
+	case "integer":
+		number, ok := value.(float64)
+		switch {
+		case !ok || number != math.Trunc(number):
+			fail("must be an integer")
+		case math.Abs(number) > maxSafeInteger:
+			fail(fmt.Sprintf("must be between %d and %d", -maxSafeInteger, maxSafeInteger))
+		}
+	case "number":
+		if _, ok := value.(float64); !ok {
+			fail("must be a number")
+		}
+	case "array":
+		items, ok := value.([]interface{})
+		if !ok {
+			fail("must be an array")
+			return
+		}
+		itemSchema, _ := schema["items"].(map[string]interface{})
+		for i, item := range items {
+			validateValue(itemSchema, item, fmt.Sprintf("%s[%d]", path, i), errs)
+		}
+	case "object":
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			fail("must be an object")
+			return
+		}
+		validateObject(schema, object, path, errs)
+	}
+}
+
+// validateObject checks required properties and the value of each known
+// property. When the schema sets additionalProperties to false, each property
+// it does not declare is reported too, so a misspelled optional argument is
+// not silently dropped.
+func validateObject(schema map[string]interface{}, object map[string]interface{}, path string, errs *[]FieldError) {
+	prefix := ""
+	if path != "" {
+		prefix = path + "."
+	}
+
+	required, _ := schema["required"].([]string)
+	for _, name := range required {
+		if value, ok := object[name]; !ok || value == nil {
+			*errs = append(*errs, FieldError{Field: prefix + name, Message: "is required"})
+		}
+	}
+
+	properties, _ := schema["properties"].(map[string]interface{})
+	names := make([]string, 0, len(properties))
+	for name := range properties {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		value, ok := object[name]
+		if !ok || (value == nil && containsString(required, name)) {
+			// A missing required property has already been reported
+			continue
+		}
+		propertySchema, _ := properties[name].(map[string]interface{})
+		validateValue(propertySchema, value, prefix+name, errs)
+	}
+
+	if additional, ok := schema["additionalProperties"].(bool); !ok || additional {
+		return
+	}
+	var unknown []string
+	for name := range object {
+		if _, ok := properties[name]; !ok {
+			unknown = append(unknown, name)
+		}
+	}
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		*errs = append(*errs, FieldError{Field: prefix + name, Message: "is not a known argument"})
+	}
+}
+
+// schemaType returns the primary type of a schema, ignoring the null member
+// of a nullable type list
+func schemaType(schema map[string]interface{}) string {
+	switch t := schema["type"].(type) {
+	case string:
+		return t
+	case []string:
+		for _, candidate := range t {
+			if candidate != "null" {
+				return candidate
+			}
+		}
+	}
+	return ""
+}
+
+// allowsNull reports whether a schema accepts null, either because its type
+// list includes null or because it does not constrain the type at all
+func allowsNull(schema map[string]interface{}) bool {
+	switch t := schema["type"].(type) {
+	case string:
+		return t == "null"
+	case []string:
+		return containsString(t, "null")
+	}
+	return true
+}
+
+// checkFormat validates a string against a JSON Schema format, returning a
+// message describing the problem or an empty string when it is valid
+func checkFormat(format interface{}, value string) string {
+	switch format {
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339, value); err != nil {
+			return "must be an RFC 3339 date-time"
+		}
+	case "date":
+		if _, err := time.Parse(time.DateOnly, value); err != nil {
+			return "must be a date as YYYY-MM-DD"
+		}
+	}
+	return ""
+}
+
+// containsString reports whether values contains value
+func containsString(values []string, value string) bool {
+	for _, candidate := range values {
+		if candidate == value {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeArguments validates arguments against schema and decodes them into target
+func decodeArguments(schema map[string]interface{}, args map[string]interface{}, target interface{}) error {
+	if err := ValidateArguments(schema, args); err != nil {
+		return err
+	}
+
+	encoded, err := json.Marshal(args)
+	if err != nil {
+		return fmt.Errorf("failed to encode arguments: %w", err)
+	}
+	if err := json.Unmarshal(encoded, target); err != nil {
+		return &ValidationError{Fields: []FieldError{{Field: "arguments", Message: err.Error()}}}
+	}
+	return nil
+}
@@ -0,0 +1,238 @@
+package tools
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type schemaTestArgs struct {
+	Name     string    `json:"name" description:"Name" required:"true"`
+	Count    int       `json:"count,omitempty"`
+	When     time.Time `json:"when"`
+	Day      *string   `json:"day,omitempty" format:"date"`
+	Mode     string    `json:"mode,omitempty" enum:"fast,slow"`
+	Labels   []string  `json:"labels,omitempty"`
+	Internal string    `json:"-"`
+	hidden   string
+}
+
+func TestSchemaFor_Struct(t *testing.T) {
+	schema := SchemaFor(schemaTestArgs{})
+
+	if schema["type"] != "object" {
+		t.Errorf("Expected object schema, got %v", schema["type"])
+	}
+	if !reflect.DeepEqual(schema["required"], []string{"name"}) {
+		t.Errorf("Expected required [name], got %v", schema["required"])
+	}
+
+	properties := schema["properties"].(map[string]interface{})
+	expected := map[string]map[string]interface{}{
+		"name":   {"type": "string", "description": "Name"},
+		"count":  {"type": "integer"},
+		"when":   {"type": "string", "format": "date-time"},
+		"day":    {"type": "string", "format": "date"},
+		"mode":   {"type": "string", "enum": []string{"fast", "slow"}},
+		"labels": {"type": "array", "items": map[string]interface{}{"type": "string"}},
+	}
+	if len(properties) != len(expected) {
+		t.Errorf("Expected %d properties, got %d: %v", len(expected), len(properties), properties)
+	}
+	for name, want := range expected {
+		got, _ := properties[name].(map[string]interface{})
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Property %s: expected %v, got %v", name, want, got)
+		}
+	}
+}
+
+func TestSchemaFor_CreateTodoArgsMatchesPublishedSchema(t *testing.T) {
+	schema := SchemaFor(CreateTodoArgs{})
+
+	expected := map[string]interface{}{
+		"type": "object",
+		"properties": map[string]interface{}{
+			"description": map[string]interface{}{
+				"type":        "string",
+				"description": "Description of the todo",
+			},
+			"createdDate": map[string]interface{}{
+				"type":        "string",
+				"format":      "date-time",
+				"description": "Creation date of the todo",
+			},
+			"dueDate": map[string]interface{}{
+				"type":        "string",
+				"format":      "date",
+				"description": "Date the todo is due as YYYY-MM-DD (optional)",
+			},
+			"dueTime": map[string]interface{}{
+				"type":        "string",
+				"description": "Time of day the todo is due as HH:MM; without it the todo is due by the end of the day (optional)",
+			},
+			"dueTimeZone": map[string]interface{}{
+				"type":        "string",
+				"description": "IANA time zone of the due date, such as Europe/London; without it the due date follows the reader's time zone (optional)",
+			},
+			"tags": map[string]interface{}{
+				"type":        "array",
+				"items":       map[string]interface{}{"type": "string"},
+				"description": "Tags of the todo, such as work or home; matched without regard to case (optional)",
+			},
+			"projectId": map[string]interface{}{
+				"type":        "string",
+				"description": "Id of the project to add the todo to (optional)",
+			},
+		},
+		"required":             []string{"description", "createdDate"},
+		"additionalProperties": false,
+	}
+	if !reflect.DeepEqual(schema, expected) {
+		t.Errorf("Expected %v, got %v", expected, schema)
+	}
+}
+
+func TestValidateArguments_ReportsEveryField(t *testing.T) {
+	schema := SchemaFor(schemaTestArgs{})
+
+	err := ValidateArguments(schema, map[string]interface{}{
+		"count":  1.5,
+		"when":   "yesterday",
+		"mode":   "medium",
+		"labels": []interface{}{"ok", 3.0},
+	})
+
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Expected ValidationError, got %v", err)
+	}
+
+	fields := map[string]bool{}
+	for _, field := range validationErr.Fields {
+		fields[field.Field] = true
+	}
+	for _, name := range []string{"name", "count", "when", "mode", "labels[1]"} {
+		if !fields[name] {
+			t.Errorf("Expected validation error for %s, got %+v", name, validationErr.Fields)
+		}
+	}
+}
+
+func TestValidateArguments_Valid(t *testing.T) {
+	schema := SchemaFor(schemaTestArgs{})
+
+	err := ValidateArguments(schema, map[string]interface{}{
+		"name":   "ok",
+		"count":  2.0,
+		"when":   "2024-01-01T10:00:00Z",
+		"labels": []interface{}{"a"},
+	})
+	if err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+}
+
+func TestValidateArguments_UnknownProperties(t *testing.T) {
+	schema := SchemaFor(ImportTodosArgs{})
+	if schema["additionalProperties"] != false {
+		t.Errorf("Expected additionalProperties to be false, got %v", schema["additionalProperties"])
+	}
+
+	err := ValidateArguments(schema, map[string]interface{}{
+		"todos": []interface{}{map[string]interface{}{
+			"description": "Misspelt",
+			"createdDate": "2024-01-01T10:00:00Z",
+			"dueDtae":     "2024-01-02",
+		}},
+		"bogus": 1.0,
+	})
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Expected ValidationError, got %v", err)
+	}
+	expected := []FieldError{
+		{Field: "todos[0].dueDtae", Message: "is not a known argument"},
+		{Field: "bogus", Message: "is not a known argument"},
+	}
+	if !reflect.DeepEqual(validationErr.Fields, expected) {
+		t.Errorf("Expected %v, got %v", expected, validationErr.Fields)
+	}
+
+	// Hand-written schemas that do not forbid additional properties accept them
+	if err := ValidateArguments(map[string]interface{}{"type": "object"}, map[string]interface{}{"extra": 1.0}); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+}
+
+func TestValidateArguments_NullAndRange(t *testing.T) {
+	schema := SchemaFor(schemaTestArgs{})
+
+	err := ValidateArguments(schema, map[string]interface{}{
+		"name":   nil,
+		"count":  1e30,
+		"day":    nil,
+		"labels": nil,
+	})
+	var validationErr *ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("Expected ValidationError, got %v", err)
+	}
+	expected := []FieldError{
+		{Field: "name", Message: "is required"},
+		{Field: "count", Message: "must be between -9007199254740991 and 9007199254740991"},
+		{Field: "day", Message: "must not be null"},
+		{Field: "labels", Message: "must not be null"},
+	}
+	if !reflect.DeepEqual(validationErr.Fields, expected) {
+		t.Errorf("Expected %v, got %v", expected, validationErr.Fields)
+	}
+
+	// Nullable properties accept null
+	nullable := map[string]interface{}{
+		"type":       "object",
+		"properties": map[string]interface{}{"note": map[string]interface{}{"type": []string{"string", "null"}}},
+	}
+	if err := ValidateArguments(nullable, map[string]interface{}{"note": nil}); err != nil {
+		t.Errorf("Expected no error, got %v", err)
+	}
+}
+
+func TestTypedTool_DecodesArguments(t *testing.T) {
+	var received schemaTestArgs
+	tool := NewTypedTool("typed", "Typed", "Typed tool", Annotations{}, func(ctx context.Context, args schemaTestArgs) (*Result, error) {
+		received = args
+		return TextResult("ok"), nil
+	})
+
+	_, err := tool.Call(t.Context(), map[string]interface{}{
+		"name": "decoded",
+		"when": "2024-01-01T10:00:00Z",
+	})
+	if err != nil {
+		t.Fatalf("Call failed: %v", err)
+	}
+	if received.Name != "decoded" {
+		t.Errorf("Expected name 'decoded', got %s", received.Name)
+	}
+	if !received.When.Equal(time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("Unexpected when: %v", received.When)
+	}
+}
+
+func TestSchemaFor_NullablePointers(t *testing.T) {
+	type output struct {
+		Nullable *string `json:"nullable"`
+		Optional *string `json:"optional,omitempty"`
+	}
+
+	properties := SchemaFor(output{})["properties"].(map[string]interface{})
+	if got := properties["nullable"].(map[string]interface{})["type"]; !reflect.DeepEqual(got, []string{"string", "null"}) {
+		t.Errorf("Expected nullable string type, got %v", got)
+	}
+	if got := properties["optional"].(map[string]interface{})["type"]; got != "string" {
+		t.Errorf("Expected plain string type for omitempty pointer, got %v", got)
+	}
+}
@@ -0,0 +1,111 @@
+package tools
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/matpadley/MCPServer_Demo/go/internal/data"
+)
+
+// maxTagLength is the longest tag accepted, in characters
+const maxTagLength = 50
+
+// normalizeTags trims and lowercases tags, so "Work" and " work" name the same
+// tag, and reports each tag that is blank or too long as field[i]. A nil list
+// stays nil, so an update without tags leaves them unchanged.
+func normalizeTags(field string, tags []string) ([]string, []FieldError) {
+	if tags == nil {
+		return nil, nil
+	}
+
+	var fields []FieldError
+	normalized := make([]string, 0, len(tags))
+	for i, tag := range tags {
+		tag = strings.ToLower(strings.TrimSpace(tag))
+		switch {
+		case tag == "":
+			fields = append(fields, FieldError{Field: fmt.Sprintf("%s[%d]", field, i), Message: "must not be blank"})
+		case utf8.RuneCountInString(tag) > maxTagLength:
+			fields = append(fields, FieldError{Field: fmt.Sprintf("%s[%d]", field, i), Message: fmt.Sprintf("must be at most %d characters", maxTagLength)})
+		}
+		normalized = append(normalized, tag)
+	}
+	return normalized, fields
+}
+
+// AddTags adds tags to a todo and returns it. It returns an *InvalidIDError or
+// *NotFoundError when the id does not name a todo, and a *ValidationError when
+// a tag is blank or too long.
+func (t *TodosMcpTool) AddTags(ctx context.Context, id string, tags []string) (*data.Todo, error) {
+	return t.changeTags(ctx, id, tags, t.db.AddTagsAsync, "error adding tags")
+}
+
+// RemoveTags removes tags from a todo and returns it. Tags the todo does not
+// have are ignored. It returns an *InvalidIDError or *NotFoundError when the
+// id does not name a todo, and a *ValidationError when a tag is blank or too long.
+func (t *TodosMcpTool) RemoveTags(ctx context.Context, id string, tags []string) (*data.Todo, error) {
+	return t.changeTags(ctx, id, tags, t.db.RemoveTagsAsync, "error removing tags")
+}
+
+// changeTags applies a tag change to a todo and returns the todo
+func (t *TodosMcpTool) changeTags(ctx context.Context, id string, tags []string, change func(context.Context, int, []string) (bool, error), failure string) (*data.Todo, error) {
+	todoID, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, &InvalidIDError{Value: id}
+	}
+
+	tags, fields := normalizeTags("tags", tags)
+	if len(fields) > 0 {
+		return nil, &ValidationError{Fields: fields}
+	}
+
+	found, err := change(ctx, todoID, tags)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", failure, err)
+	}
+
+	if !found {
+		return nil, &NotFoundError{ID: todoID}
+	}
+	return t.readTodo(ctx, todoID)
+}
+
+// ListTags returns every tag in use with the number of todos that carry it
+func (t *TodosMcpTool) ListTags(ctx context.Context) ([]data.TagCount, error) {
+	tags, err := t.db.ListTagsAsync(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("error listing tags: %w", err)
+	}
+	if tags == nil {
+		tags = []data.TagCount{}
+	}
+	return tags, nil
+}
+
+// tagsMessage describes the tags a todo has after a change
+func tagsMessage(todo *data.Todo) string {
+	if len(todo.Tags) == 0 {
+		return fmt.Sprintf("Todo %d has no tags.", todo.ID)
+	}
+	return fmt.Sprintf("Todo %d tags: %s.", todo.ID, strings.Join(todo.Tags, ", "))
+}
+
+// formatTagCounts renders tags as a list with the number of todos carrying each
+func formatTagCounts(tags []data.TagCount) string {
+	if len(tags) == 0 {
+		return "No tags."
+	}
+	var b strings.Builder
+	b.WriteString("Tags:\n")
+	for _, tag := range tags {
+		noun := "todos"
+		if tag.Count == 1 {
+			noun = "todo"
+		}
+		fmt.Fprintf(&b, "- %s (%d %s)\n", tag.Name, tag.Count, noun)
+	}
+	return b.String()
+}
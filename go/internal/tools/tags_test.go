@@ -0,0 +1,93 @@
+package tools
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/matpadley/MCPServer_Demo/go/internal/data"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	tags, fields := normalizeTags("tags", []string{" Work ", "HOME"})
+	if len(fields) != 0 || !slices.Equal(tags, []string{"work", "home"}) {
+		t.Errorf("Expected trimmed lowercase tags, got %v (%v)", tags, fields)
+	}
+
+	if tags, _ := normalizeTags("tags", nil); tags != nil {
+		t.Errorf("Expected nil tags to stay nil, got %#v", tags)
+	}
+
+	_, fields = normalizeTags("todos[0].tags", []string{"ok", "", strings.Repeat("x", maxTagLength+1)})
+	if len(fields) != 2 || fields[0].Field != "todos[0].tags[1]" || fields[1].Field != "todos[0].tags[2]" {
+		t.Errorf("Expected the blank and long tags to be reported, got %v", fields)
+	}
+}
+
+func TestTags_AddRemoveAndList(t *testing.T) {
+	db := createTestStore(t)
+	defer db.Close()
+
+	tool := NewTodosMcpTool(db)
+	todo, err := tool.CreateTodo(t.Context(), data.CreateTodoInput{Description: "Tagged", CreatedDate: time.Now(), Tags: []string{"Work"}})
+	if err != nil {
+		t.Fatalf("Failed to create todo: %v", err)
+	}
+	if !slices.Equal(todo.Tags, []string{"work"}) {
+		t.Errorf("Expected normalized tags, got %v", todo.Tags)
+	}
+
+	todo, err = tool.AddTags(t.Context(), "1", []string{"Home", "work"})
+	if err != nil {
+		t.Fatalf("Failed to add tags: %v", err)
+	}
+	if !slices.Equal(todo.Tags, []string{"home", "work"}) {
+		t.Errorf("Expected home and work, got %v", todo.Tags)
+	}
+
+	todo, err = tool.RemoveTags(t.Context(), "1", []string{"WORK"})
+	if err != nil {
+		t.Fatalf("Failed to remove tags: %v", err)
+	}
+	if !slices.Equal(todo.Tags, []string{"home"}) {
+		t.Errorf("Expected only home, got %v", todo.Tags)
+	}
+
+	tags, err := tool.ListTags(t.Context())
+	if err != nil {
+		t.Fatalf("Failed to list tags: %v", err)
+	}
+	if !slices.Equal(tags, []data.TagCount{{Name: "home", Count: 1}}) {
+		t.Errorf("Expected home once, got %v", tags)
+	}
+
+	var notFound *NotFoundError
+	if _, err := tool.AddTags(t.Context(), "99", []string{"x"}); !errors.As(err, &notFound) {
+		t.Errorf("Expected NotFoundError, got %v", err)
+	}
+	var validation *ValidationError
+	if _, err := tool.UpdateTodo(t.Context(), "1", data.UpdateTodoInput{Tags: []string{""}}); !errors.As(err, &validation) {
+		t.Errorf("Expected ValidationError, got %v", err)
+	}
+}
+
+func TestImportTodos_ValidatesTags(t *testing.T) {
+	db := createTestStore(t)
+	defer db.Close()
+
+	tool := NewTodosMcpTool(db)
+	_, err := tool.callImportTodos(t.Context(), ImportTodosArgs{Todos: []CreateTodoArgs{
+		{Description: "Fine", CreatedDate: time.Now(), Tags: []string{"ok"}},
+		{Description: "Blank tag", CreatedDate: time.Now(), Tags: []string{" "}},
+	}})
+	var validation *ValidationError
+	if !errors.As(err, &validation) || validation.Fields[0].Field != "todos[1].tags[0]" {
+		t.Fatalf("Expected todos[1].tags[0] to be reported, got %v", err)
+	}
+
+	if todos, _ := db.ReadTodosAsync(t.Context()); len(todos) != 0 {
+		t.Errorf("Expected nothing to be imported, got %v", todos)
+	}
+}
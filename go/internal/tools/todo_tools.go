@@ -0,0 +1,481 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/matpadley/MCPServer_Demo/go/internal/data"
+)
+
+// CreateTodoArgs are the arguments of the create_todo tool
+type CreateTodoArgs struct {
+	Description string    `json:"description" description:"Description of the todo" required:"true"`
+	CreatedDate time.Time `json:"createdDate" description:"Creation date of the todo" required:"true"`
+	DueDate     *string   `json:"dueDate,omitempty" description:"Date the todo is due as YYYY-MM-DD (optional)" format:"date"`
+	DueTime     *string   `json:"dueTime,omitempty" description:"Time of day the todo is due as HH:MM; without it the todo is due by the end of the day (optional)"`
+	DueTimeZone *string   `json:"dueTimeZone,omitempty" description:"IANA time zone of the due date, such as Europe/London; without it the due date follows the reader's time zone (optional)"`
+	Tags        []string  `json:"tags,omitempty" description:"Tags of the todo, such as work or home; matched without regard to case (optional)"`
+	ProjectID   *string   `json:"projectId,omitempty" description:"Id of the project to add the todo to (optional)"`
+}
+
+// input converts the arguments to a data layer input. It returns an
+// *InvalidProjectIDError when the project id is not an integer.
+func (a CreateTodoArgs) input() (data.CreateTodoInput, error) {
+	input := data.CreateTodoInput{
+		Description: a.Description,
+		CreatedDate: a.CreatedDate,
+		DueDate:     a.DueDate,
+		DueTime:     a.DueTime,
+		DueTimeZone: a.DueTimeZone,
+		Tags:        a.Tags,
+	}
+	if a.ProjectID != nil && strings.TrimSpace(*a.ProjectID) != "" {
+		projectID, err := parseProjectID(*a.ProjectID)
+		if err != nil {
+			return data.CreateTodoInput{}, err
+		}
+		input.ProjectID = &projectID
+	}
+	return input, nil
+}
+
+// Page sizes of the read_todos tool
+const (
+	defaultReadTodosLimit = 100
+	maxReadTodosLimit     = 1000
+)
+
+// ReadTodosArgs are the arguments of the read_todos tool
+type ReadTodosArgs struct {
+	ID        *string  `json:"id,omitempty" description:"Id of the todo to read (optional)"`
+	Cursor    *string  `json:"cursor,omitempty" description:"nextCursor from a previous call, to read the following page (optional)"`
+	Limit     *int     `json:"limit,omitempty" description:"Maximum number of todos to return, from 1 to 1000 (optional, default 100)"`
+	Status    *string  `json:"status,omitempty" description:"Only return todos with this status (optional)" enum:"open,done"`
+	Tags      []string `json:"tags,omitempty" description:"Only return todos with these tags (optional)"`
+	TagMatch  *string  `json:"tagMatch,omitempty" description:"Whether todos need any or all of the tags (optional, default any)" enum:"any,all"`
+	ProjectID *string  `json:"projectId,omitempty" description:"Only return todos in this project, archived or not (optional)"`
+}
+
+// UpdateTodoArgs are the arguments of the update_todo tool
+type UpdateTodoArgs struct {
+	ID          string     `json:"id" description:"Id of the todo to update" required:"true"`
+	Description *string    `json:"description,omitempty" description:"New description (optional)"`
+	CreatedDate *time.Time `json:"createdDate,omitempty" description:"New creation date (optional)"`
+	DueDate     *string    `json:"dueDate,omitempty" description:"New due date as YYYY-MM-DD, replacing the due time and time zone too (optional)" format:"date"`
+	DueTime     *string    `json:"dueTime,omitempty" description:"Time of day the todo is due as HH:MM, with dueDate (optional)"`
+	DueTimeZone *string    `json:"dueTimeZone,omitempty" description:"IANA time zone of the due date, with dueDate (optional)"`
+	ClearDue    bool       `json:"clearDue,omitempty" description:"Remove the due date (optional)"`
+	Tags        []string   `json:"tags,omitempty" description:"Tags replacing every tag of the todo; an empty list removes them all (optional)"`
+}
+
+// DeleteTodoArgs are the arguments of the delete_todo tool
+type DeleteTodoArgs struct {
+	ID string `json:"id" description:"Id of the todo to delete" required:"true"`
+}
+
+// CompleteTodoArgs are the arguments of the complete_todo tool
+type CompleteTodoArgs struct {
+	ID string `json:"id" description:"Id of the todo to mark as done" required:"true"`
+}
+
+// ReopenTodoArgs are the arguments of the reopen_todo tool
+type ReopenTodoArgs struct {
+	ID string `json:"id" description:"Id of the todo to mark as open again" required:"true"`
+}
+
+// GetAgendaArgs are the arguments of the get_agenda tool
+type GetAgendaArgs struct {
+	TimeZone *string `json:"timeZone,omitempty" description:"IANA time zone to build the agenda in, such as Europe/London (optional, default UTC)"`
+	Days     *int    `json:"days,omitempty" description:"Number of days after today to include as upcoming, from 0 to 90 (optional, default 7)"`
+}
+
+// AddTagsArgs are the arguments of the add_tags tool
+type AddTagsArgs struct {
+	ID   string   `json:"id" description:"Id of the todo to tag" required:"true"`
+	Tags []string `json:"tags" description:"Tags to add; tags the todo already has are ignored" required:"true"`
+}
+
+// RemoveTagsArgs are the arguments of the remove_tags tool
+type RemoveTagsArgs struct {
+	ID   string   `json:"id" description:"Id of the todo to untag" required:"true"`
+	Tags []string `json:"tags" description:"Tags to remove; tags the todo does not have are ignored" required:"true"`
+}
+
+// ListTagsArgs are the arguments of the list_tags tool, which takes none
+type ListTagsArgs struct{}
+
+// CreateProjectArgs are the arguments of the create_project tool
+type CreateProjectArgs struct {
+	Name string `json:"name" description:"Name of the project; must differ from every other project's name, ignoring ASCII case" required:"true"`
+}
+
+// ListProjectsArgs are the arguments of the list_projects tool
+type ListProjectsArgs struct {
+	IncludeArchived bool `json:"includeArchived,omitempty" description:"Include archived projects (optional, default false)"`
+}
+
+// ArchiveProjectArgs are the arguments of the archive_project tool
+type ArchiveProjectArgs struct {
+	ID string `json:"id" description:"Id of the project to archive" required:"true"`
+}
+
+// MoveTodoArgs are the arguments of the move_todo tool
+type MoveTodoArgs struct {
+	ID        string  `json:"id" description:"Id of the todo to move" required:"true"`
+	ProjectID *string `json:"projectId,omitempty" description:"Id of the project to move the todo to; without it the todo leaves its project (optional)"`
+}
+
+// ImportTodosArgs are the arguments of the import_todos tool
+type ImportTodosArgs struct {
+	Todos []CreateTodoArgs `json:"todos" description:"The todos to create, in order" required:"true"`
+}
+
+// ReadTodosOutput is the structured content of the read_todos tool
+type ReadTodosOutput struct {
+	Todos      []data.Todo `json:"todos" description:"The matching todos, ordered by id" required:"true"`
+	NextCursor string      `json:"nextCursor,omitempty" description:"Cursor for the next page; absent on the last page"`
+}
+
+// ListTagsOutput is the structured content of the list_tags tool
+type ListTagsOutput struct {
+	Tags []data.TagCount `json:"tags" description:"Every tag in use, in alphabetical order" required:"true"`
+}
+
+// ListProjectsOutput is the structured content of the list_projects tool
+type ListProjectsOutput struct {
+	Projects []data.Project `json:"projects" description:"The projects, ordered by id" required:"true"`
+}
+
+// ImportTodosOutput is the structured content of the import_todos tool
+type ImportTodosOutput struct {
+	Todos []data.Todo `json:"todos" description:"The created todos, in the order they were supplied" required:"true"`
+}
+
+// AgendaItem is an open todo with its due date resolved in the agenda's time zone
+type AgendaItem struct {
+	Todo  data.Todo `json:"todo" description:"The todo" required:"true"`
+	DueAt time.Time `json:"dueAt" description:"When the todo is due, in the agenda's time zone" required:"true"`
+}
+
+// AgendaOutput is the structured content of the get_agenda tool
+type AgendaOutput struct {
+	TimeZone string       `json:"timeZone" description:"Time zone the agenda was built in" required:"true"`
+	Today    string       `json:"today" description:"Today's date in the agenda's time zone" format:"date" required:"true"`
+	Overdue  []AgendaItem `json:"overdue" description:"Open todos whose due time has passed, earliest first" required:"true"`
+	DueToday []AgendaItem `json:"dueToday" description:"Open todos due later today, earliest first" required:"true"`
+	Upcoming []AgendaItem `json:"upcoming" description:"Open todos due after today and within the requested days, earliest first" required:"true"`
+}
+
+// DeleteTodoOutput is the structured content of the delete_todo tool
+type DeleteTodoOutput struct {
+	ID      int  `json:"id" description:"Id of the deleted todo" required:"true"`
+	Deleted bool `json:"deleted" description:"Whether the todo was deleted" required:"true"`
+}
+
+// Tools returns the MCP tool definitions for todo management
+func (t *TodosMcpTool) Tools() []Tool {
+	return []Tool{
+		NewTypedTool("create_todo", "Create todo", "Creates a new todo with a description and creation date.", Annotations{}, t.callCreateTodo).
+			WithOutput(data.Todo{}).
+			WithCompletion("projectId", t.CompleteProjectIDs),
+		NewTypedTool("read_todos", "Read todos", "Reads todos a page at a time, or a single todo if an id is provided.", Annotations{ReadOnlyHint: true, IdempotentHint: true}, t.callReadTodos).
+			WithOutput(ReadTodosOutput{}).
+			WithCompletion("id", t.CompleteTodoIDs).
+			WithCompletion("projectId", t.CompleteProjectIDs),
+		NewTypedTool("update_todo", "Update todo", "Updates the specified todo fields by id.", Annotations{DestructiveHint: true, IdempotentHint: true}, t.callUpdateTodo).
+			WithOutput(data.Todo{}).
+			WithCompletion("id", t.CompleteTodoIDs),
+		NewTypedTool("delete_todo", "Delete todo", "Deletes a todo by id.", Annotations{DestructiveHint: true, IdempotentHint: true}, t.callDeleteTodo).
+			WithOutput(DeleteTodoOutput{}).
+			WithCompletion("id", t.CompleteTodoIDs),
+		NewTypedTool("complete_todo", "Complete todo", "Marks a todo as done, recording when it was completed.", Annotations{IdempotentHint: true}, t.callCompleteTodo).
+			WithOutput(data.Todo{}).
+			WithCompletion("id", t.CompleteTodoIDs),
+		NewTypedTool("reopen_todo", "Reopen todo", "Marks a done todo as open again.", Annotations{IdempotentHint: true}, t.callReopenTodo).
+			WithOutput(data.Todo{}).
+			WithCompletion("id", t.CompleteTodoIDs),
+		NewTypedTool("get_agenda", "Get agenda", "Lists open todos that are overdue, due today and due in the coming days, in the caller's time zone.", Annotations{ReadOnlyHint: true, IdempotentHint: true}, t.callGetAgenda).
+			WithOutput(AgendaOutput{}),
+		NewTypedTool("add_tags", "Add tags", "Adds tags to a todo, keeping the tags it already has.", Annotations{IdempotentHint: true}, t.callAddTags).
+			WithOutput(data.Todo{}).
+			WithCompletion("id", t.CompleteTodoIDs),
+		NewTypedTool("remove_tags", "Remove tags", "Removes tags from a todo.", Annotations{DestructiveHint: true, IdempotentHint: true}, t.callRemoveTags).
+			WithOutput(data.Todo{}).
+			WithCompletion("id", t.CompleteTodoIDs),
+		NewTypedTool("list_tags", "List tags", "Lists every tag in use with the number of todos that carry it.", Annotations{ReadOnlyHint: true, IdempotentHint: true}, t.callListTags).
+			WithOutput(ListTagsOutput{}),
+		NewTypedTool("create_project", "Create project", "Creates a project to group todos in.", Annotations{}, t.callCreateProject).
+			WithOutput(data.Project{}),
+		NewTypedTool("list_projects", "List projects", "Lists projects, leaving out archived ones unless asked.", Annotations{ReadOnlyHint: true, IdempotentHint: true}, t.callListProjects).
+			WithOutput(ListProjectsOutput{}),
+		NewTypedTool("archive_project", "Archive project", "Archives a project so it takes no new todos; its todos stay in it.", Annotations{IdempotentHint: true}, t.callArchiveProject).
+			WithOutput(data.Project{}).
+			WithCompletion("id", t.CompleteProjectIDs),
+		NewTypedTool("move_todo", "Move todo", "Moves a todo into a project, or out of its project when no project id is given.", Annotations{IdempotentHint: true}, t.callMoveTodo).
+			WithOutput(data.Todo{}).
+			WithCompletion("id", t.CompleteTodoIDs).
+			WithCompletion("projectId", t.CompleteProjectIDs),
+		NewTypedTool("import_todos", "Import todos", "Creates many todos in one call, reporting progress as each is created.", Annotations{}, t.callImportTodos).
+			WithOutput(ImportTodosOutput{}),
+	}
+}
+
+// callCreateTodo handles create_todo tool calls
+func (t *TodosMcpTool) callCreateTodo(ctx context.Context, args CreateTodoArgs) (*Result, error) {
+	input, err := args.input()
+	if err != nil {
+		return nil, err
+	}
+	todo, err := t.CreateTodo(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+	return StructuredResult(createdMessage(todo), todo), nil
+}
+
+// callReadTodos handles read_todos tool calls. Without an id the todos are
+// returned a page at a time; the text of a page that is followed by another
+// carries a second block telling the model which cursor to pass next.
+func (t *TodosMcpTool) callReadTodos(ctx context.Context, args ReadTodosArgs) (*Result, error) {
+	if args.ID != nil && strings.TrimSpace(*args.ID) != "" {
+		todos, err := t.ReadTodosAsync(ctx, args.ID)
+		if err != nil {
+			return nil, err
+		}
+		return StructuredResult(formatTodosAsJSON(todos), ReadTodosOutput{Todos: todos}), nil
+	}
+
+	limit := defaultReadTodosLimit
+	if args.Limit != nil {
+		if *args.Limit < 1 {
+			return nil, &ValidationError{Fields: []FieldError{{Field: "limit", Message: "must be at least 1"}}}
+		}
+		limit = min(*args.Limit, maxReadTodosLimit)
+	}
+	var cursor string
+	if args.Cursor != nil {
+		cursor = *args.Cursor
+	}
+	query := data.TodoQuery{
+		Limit:        limit,
+		Tags:         args.Tags,
+		MatchAllTags: args.TagMatch != nil && *args.TagMatch == "all",
+	}
+	if args.Status != nil {
+		query.Status = data.TodoStatus(*args.Status)
+	}
+	if args.ProjectID != nil && strings.TrimSpace(*args.ProjectID) != "" {
+		projectID, err := parseProjectID(*args.ProjectID)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := t.readProject(ctx, projectID); err != nil {
+			return nil, err
+		}
+		query.ProjectID = &projectID
+	}
+
+	todos, nextCursor, err := t.ListTodos(ctx, cursor, query)
+	if err != nil {
+		return nil, err
+	}
+	if todos == nil {
+		todos = []data.Todo{}
+	}
+	result := StructuredResult(formatTodosAsJSON(todos), ReadTodosOutput{Todos: todos, NextCursor: nextCursor})
+	if nextCursor != "" {
+		result.Content = append(result.Content, Content{Type: "text", Text: morePagesMessage(nextCursor)})
+	}
+	return result, nil
+}
+
+// callUpdateTodo handles update_todo tool calls
+func (t *TodosMcpTool) callUpdateTodo(ctx context.Context, args UpdateTodoArgs) (*Result, error) {
+	todo, err := t.UpdateTodo(ctx, args.ID, data.UpdateTodoInput{
+		Description: args.Description,
+		CreatedDate: args.CreatedDate,
+		DueDate:     args.DueDate,
+		DueTime:     args.DueTime,
+		DueTimeZone: args.DueTimeZone,
+		ClearDue:    args.ClearDue,
+		Tags:        args.Tags,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return StructuredResult(updatedMessage(todo.ID), todo), nil
+}
+
+// callDeleteTodo handles delete_todo tool calls
+func (t *TodosMcpTool) callDeleteTodo(ctx context.Context, args DeleteTodoArgs) (*Result, error) {
+	id, err := t.DeleteTodo(ctx, args.ID)
+	if err != nil {
+		return nil, err
+	}
+	return StructuredResult(deletedMessage(id), DeleteTodoOutput{ID: id, Deleted: true}), nil
+}
+
+// callCompleteTodo handles complete_todo tool calls
+func (t *TodosMcpTool) callCompleteTodo(ctx context.Context, args CompleteTodoArgs) (*Result, error) {
+	todo, err := t.CompleteTodo(ctx, args.ID)
+	if err != nil {
+		return nil, err
+	}
+	return StructuredResult(completedMessage(todo.ID), todo), nil
+}
+
+// callReopenTodo handles reopen_todo tool calls
+func (t *TodosMcpTool) callReopenTodo(ctx context.Context, args ReopenTodoArgs) (*Result, error) {
+	todo, err := t.ReopenTodo(ctx, args.ID)
+	if err != nil {
+		return nil, err
+	}
+	return StructuredResult(reopenedMessage(todo.ID), todo), nil
+}
+
+// callGetAgenda handles get_agenda tool calls
+func (t *TodosMcpTool) callGetAgenda(ctx context.Context, args GetAgendaArgs) (*Result, error) {
+	loc := time.UTC
+	if args.TimeZone != nil {
+		zone, err := loadTimeZone(*args.TimeZone)
+		if err != nil {
+			return nil, &ValidationError{Fields: []FieldError{{Field: "timeZone", Message: "must be an IANA time zone such as Europe/London"}}}
+		}
+		loc = zone
+	}
+	days := defaultAgendaDays
+	if args.Days != nil {
+		if *args.Days < 0 || *args.Days > maxAgendaDays {
+			return nil, &ValidationError{Fields: []FieldError{{Field: "days", Message: fmt.Sprintf("must be from 0 to %d", maxAgendaDays)}}}
+		}
+		days = *args.Days
+	}
+
+	agenda, err := t.Agenda(ctx, loc, days)
+	if err != nil {
+		return nil, err
+	}
+	return StructuredResult(formatAgenda(agenda), agenda), nil
+}
+
+// callAddTags handles add_tags tool calls
+func (t *TodosMcpTool) callAddTags(ctx context.Context, args AddTagsArgs) (*Result, error) {
+	todo, err := t.AddTags(ctx, args.ID, args.Tags)
+	if err != nil {
+		return nil, err
+	}
+	return StructuredResult(tagsMessage(todo), todo), nil
+}
+
+// callRemoveTags handles remove_tags tool calls
+func (t *TodosMcpTool) callRemoveTags(ctx context.Context, args RemoveTagsArgs) (*Result, error) {
+	todo, err := t.RemoveTags(ctx, args.ID, args.Tags)
+	if err != nil {
+		return nil, err
+	}
+	return StructuredResult(tagsMessage(todo), todo), nil
+}
+
+// callListTags handles list_tags tool calls
+func (t *TodosMcpTool) callListTags(ctx context.Context, args ListTagsArgs) (*Result, error) {
+	tags, err := t.ListTags(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return StructuredResult(formatTagCounts(tags), ListTagsOutput{Tags: tags}), nil
+}
+
+// callCreateProject handles create_project tool calls
+func (t *TodosMcpTool) callCreateProject(ctx context.Context, args CreateProjectArgs) (*Result, error) {
+	project, err := t.CreateProject(ctx, args.Name)
+	if err != nil {
+		return nil, err
+	}
+	return StructuredResult(projectCreatedMessage(project), project), nil
+}
+
+// callListProjects handles list_projects tool calls
+func (t *TodosMcpTool) callListProjects(ctx context.Context, args ListProjectsArgs) (*Result, error) {
+	projects, err := t.ListProjects(ctx, args.IncludeArchived)
+	if err != nil {
+		return nil, err
+	}
+	return StructuredResult(formatProjects(projects), ListProjectsOutput{Projects: projects}), nil
+}
+
+// callArchiveProject handles archive_project tool calls
+func (t *TodosMcpTool) callArchiveProject(ctx context.Context, args ArchiveProjectArgs) (*Result, error) {
+	project, err := t.ArchiveProject(ctx, args.ID)
+	if err != nil {
+		return nil, err
+	}
+	return StructuredResult(projectArchivedMessage(project), project), nil
+}
+
+// callMoveTodo handles move_todo tool calls
+func (t *TodosMcpTool) callMoveTodo(ctx context.Context, args MoveTodoArgs) (*Result, error) {
+	todo, err := t.MoveTodo(ctx, args.ID, args.ProjectID)
+	if err != nil {
+		return nil, err
+	}
+	return StructuredResult(movedMessage(todo), todo), nil
+}
+
+// callImportTodos handles import_todos tool calls. Every item, including the
+// project it names, is checked before any is created. Todos are then created
+// one at a time; if the call is cancelled or fails part way, the todos already
+// created are kept and the error says how many there were. Change
+// notifications are held back until the import ends.
+func (t *TodosMcpTool) callImportTodos(ctx context.Context, args ImportTodosArgs) (*Result, error) {
+	inputs := make([]data.CreateTodoInput, len(args.Todos))
+	var fields []FieldError
+	for i, item := range args.Todos {
+		input, err := item.input()
+		if err != nil {
+			return nil, fmt.Errorf("todos[%d]: %w", i, err)
+		}
+		_, itemFields := validateCreate(fmt.Sprintf("todos[%d].", i), input)
+		fields = append(fields, itemFields...)
+		inputs[i] = input
+	}
+	if len(fields) > 0 {
+		return nil, &ValidationError{Fields: fields}
+	}
+	for i, input := range inputs {
+		if input.ProjectID == nil {
+			continue
+		}
+		if err := t.checkProjectOpen(ctx, *input.ProjectID); err != nil {
+			return nil, fmt.Errorf("todos[%d]: %w", i, err)
+		}
+	}
+
+	defer BeginBatch(ctx)()
+
+	count := len(args.Todos)
+	todos := make([]data.Todo, 0, count)
+	for i, input := range inputs {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("import stopped after %d of %d todos: %w", i, count, err)
+		}
+		todo, err := t.CreateTodo(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("import stopped after %d of %d todos: %w", i, count, err)
+		}
+		todos = append(todos, *todo)
+		ReportProgress(ctx, float64(i+1), float64(count), fmt.Sprintf("Imported %d of %d todos", i+1, count))
+	}
+	return StructuredResult(importedMessage(len(todos)), ImportTodosOutput{Todos: todos}), nil
+}
+
+// formatTodosAsJSON formats todos as JSON string for response
+func formatTodosAsJSON(todos []data.Todo) string {
+	jsonBytes, err := json.Marshal(todos)
+	if err != nil {
+		return "[]"
+	}
+	return string(jsonBytes)
+}
@@ -1,6 +1,7 @@
 package tools
 
 import (
+	"context"
 	"fmt"
 	"strconv"
 	"strings"
@@ -11,83 +12,275 @@ import (
 
 // TodosMcpTool provides MCP tools for todo management
 type TodosMcpTool struct {
-	db *data.DatabaseContext
+	db data.TodoStore
+	// now returns the current time; tests replace it to fix the clock
+	now func() time.Time
 }
 
 // NewTodosMcpTool creates a new TodosMcpTool instance
-func NewTodosMcpTool(db *data.DatabaseContext) *TodosMcpTool {
-	return &TodosMcpTool{db: db}
+func NewTodosMcpTool(db data.TodoStore) *TodosMcpTool {
+	return &TodosMcpTool{db: db, now: time.Now}
 }
 
-// CreateTodoAsync creates a new todo with a description and creation date
-func (t *TodosMcpTool) CreateTodoAsync(description string, createdDate time.Time) (string, error) {
-	todo, err := t.db.CreateTodoAsync(data.CreateTodoInput{
-		Description: description,
-		CreatedDate: createdDate,
-	})
+// CreateTodo creates a new todo and returns it. It returns a *ValidationError
+// when the due date fields or tags are malformed, and a *ProjectNotFoundError
+// or *ProjectArchivedError when the project cannot take the todo.
+func (t *TodosMcpTool) CreateTodo(ctx context.Context, input data.CreateTodoInput) (*data.Todo, error) {
+	var fields []FieldError
+	input.Tags, fields = validateCreate("", input)
+	if len(fields) > 0 {
+		return nil, &ValidationError{Fields: fields}
+	}
+	if input.ProjectID != nil {
+		if err := t.checkProjectOpen(ctx, *input.ProjectID); err != nil {
+			return nil, err
+		}
+	}
+
+	todo, err := t.db.CreateTodoAsync(ctx, input)
 	if err != nil {
-		return "", fmt.Errorf("error creating todo: %w", err)
+		return nil, fmt.Errorf("error creating todo: %w", err)
 	}
+	return todo, nil
+}
 
-	return fmt.Sprintf("Todo created: %s (Id: %d)", *todo.Description, todo.ID), nil
+// CreateTodoAsync creates a new todo with a description and creation date
+func (t *TodosMcpTool) CreateTodoAsync(ctx context.Context, description string, createdDate time.Time) (string, error) {
+	todo, err := t.CreateTodo(ctx, data.CreateTodoInput{Description: description, CreatedDate: createdDate})
+	if err != nil {
+		return "", err
+	}
+	return createdMessage(todo), nil
 }
 
-// ReadTodosAsync reads all todos, or a single todo if an id is provided
-func (t *TodosMcpTool) ReadTodosAsync(id *string) ([]data.Todo, error) {
+// ReadTodosAsync reads all todos, or a single todo if an id is provided. It
+// returns an *InvalidIDError or *NotFoundError when the id does not name a todo.
+func (t *TodosMcpTool) ReadTodosAsync(ctx context.Context, id *string) ([]data.Todo, error) {
 	if id != nil && strings.TrimSpace(*id) != "" {
 		todoID, err := strconv.Atoi(strings.TrimSpace(*id))
 		if err != nil {
-			// Invalid ID, return empty list like other implementations
-			return []data.Todo{}, nil
+			return nil, &InvalidIDError{Value: *id}
+		}
+		todo, err := t.readTodo(ctx, todoID)
+		if err != nil {
+			return nil, err
 		}
-		return t.db.ReadTodosAsync(todoID)
+		return []data.Todo{*todo}, nil
 	}
 
-	return t.db.ReadTodosAsync()
+	return t.db.ReadTodosAsync(ctx)
 }
 
-// UpdateTodoAsync updates the specified todo fields by id
-func (t *TodosMcpTool) UpdateTodoAsync(id string, description *string, createdDate *time.Time) (string, error) {
+// readTodosCursor names the list that read_todos cursors belong to
+const readTodosCursor = "read_todos"
+
+// ListTodos reads the page of todos selected by query that follows the
+// position held by cursor, in id order; query.AfterID is replaced by the
+// cursor. It returns the cursor of the next page, or an empty string on the
+// last page, and a *ValidationError when the cursor is not one it issued or a
+// tag is malformed.
+func (t *TodosMcpTool) ListTodos(ctx context.Context, cursor string, query data.TodoQuery) ([]data.Todo, string, error) {
+	afterID, err := DecodeCursor(readTodosCursor, cursor)
+	if err != nil {
+		return nil, "", &ValidationError{Fields: []FieldError{{Field: "cursor", Message: "is not a valid cursor"}}}
+	}
+	query.AfterID = afterID
+
+	var fields []FieldError
+	if query.Tags, fields = normalizeTags("tags", query.Tags); len(fields) > 0 {
+		return nil, "", &ValidationError{Fields: fields}
+	}
+
+	todos, more, err := t.db.ListTodosAsync(ctx, query)
+	if err != nil {
+		return nil, "", fmt.Errorf("error listing todos: %w", err)
+	}
+	if !more {
+		return todos, "", nil
+	}
+	return todos, EncodeCursor(readTodosCursor, todos[len(todos)-1].ID), nil
+}
+
+// CompleteTodoIDs suggests up to MaxCompletionValues todo ids for a partially
+// typed value, and returns how many todos match. Ids starting with the value
+// come first, followed by todos whose description contains it.
+func (t *TodosMcpTool) CompleteTodoIDs(ctx context.Context, value string) ([]string, int, error) {
+	todos, total, err := t.db.SearchTodosAsync(ctx, strings.TrimSpace(value), MaxCompletionValues)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error completing todo ids: %w", err)
+	}
+
+	ids := make([]string, 0, len(todos))
+	for _, todo := range todos {
+		ids = append(ids, strconv.Itoa(todo.ID))
+	}
+	return ids, total, nil
+}
+
+// UpdateTodo updates the specified todo fields by id and returns the updated
+// todo. It returns an *InvalidIDError or *NotFoundError when the id does not
+// name a todo, and a *ValidationError when the due date fields or tags are
+// malformed. A blank description leaves the description unchanged.
+func (t *TodosMcpTool) UpdateTodo(ctx context.Context, id string, input data.UpdateTodoInput) (*data.Todo, error) {
 	todoID, err := strconv.Atoi(id)
 	if err != nil {
-		return "Invalid todo id.", nil
+		return nil, &InvalidIDError{Value: id}
 	}
 
-	updateInput := data.UpdateTodoInput{}
-	if description != nil && strings.TrimSpace(*description) != "" {
-		updateInput.Description = description
+	fields := validateDue("", input.DueDate, input.DueTime, input.DueTimeZone)
+	var tagFields []FieldError
+	input.Tags, tagFields = normalizeTags("tags", input.Tags)
+	if fields = append(fields, tagFields...); len(fields) > 0 {
+		return nil, &ValidationError{Fields: fields}
 	}
-	if createdDate != nil {
-		updateInput.CreatedDate = createdDate
+	if input.Description != nil && strings.TrimSpace(*input.Description) == "" {
+		input.Description = nil
 	}
 
-	updated, err := t.db.UpdateTodoAsync(todoID, updateInput)
+	updated, err := t.db.UpdateTodoAsync(ctx, todoID, input)
 	if err != nil {
-		return "", fmt.Errorf("error updating todo: %w", err)
+		return nil, fmt.Errorf("error updating todo: %w", err)
 	}
 
 	if !updated {
-		return fmt.Sprintf("Todo with Id %d not found.", todoID), nil
+		return nil, &NotFoundError{ID: todoID}
 	}
+	return t.readTodo(ctx, todoID)
+}
 
-	return fmt.Sprintf("Todo %d updated.", todoID), nil
+// UpdateTodoAsync updates the specified todo fields by id. It returns an
+// *InvalidIDError or *NotFoundError when the id does not name a todo.
+func (t *TodosMcpTool) UpdateTodoAsync(ctx context.Context, id string, description *string, createdDate *time.Time) (string, error) {
+	todo, err := t.UpdateTodo(ctx, id, data.UpdateTodoInput{Description: description, CreatedDate: createdDate})
+	if err != nil {
+		return "", err
+	}
+	return updatedMessage(todo.ID), nil
 }
 
-// DeleteTodoAsync deletes a todo by id
-func (t *TodosMcpTool) DeleteTodoAsync(id string) (string, error) {
+// CompleteTodo marks a todo as done and returns it. Completing a todo that is
+// already done keeps its original completion time. It returns an
+// *InvalidIDError or *NotFoundError when the id does not name a todo.
+func (t *TodosMcpTool) CompleteTodo(ctx context.Context, id string) (*data.Todo, error) {
 	todoID, err := strconv.Atoi(id)
 	if err != nil {
-		return "Invalid todo id.", nil
+		return nil, &InvalidIDError{Value: id}
 	}
 
-	deleted, err := t.db.DeleteTodoAsync(todoID)
+	completed, err := t.db.CompleteTodoAsync(ctx, todoID, t.now().UTC())
 	if err != nil {
-		return "", fmt.Errorf("error deleting todo: %w", err)
+		return nil, fmt.Errorf("error completing todo: %w", err)
+	}
+
+	if !completed {
+		return nil, &NotFoundError{ID: todoID}
+	}
+	return t.readTodo(ctx, todoID)
+}
+
+// ReopenTodo marks a todo as open again and returns it. It returns an
+// *InvalidIDError or *NotFoundError when the id does not name a todo.
+func (t *TodosMcpTool) ReopenTodo(ctx context.Context, id string) (*data.Todo, error) {
+	todoID, err := strconv.Atoi(id)
+	if err != nil {
+		return nil, &InvalidIDError{Value: id}
+	}
+
+	reopened, err := t.db.ReopenTodoAsync(ctx, todoID)
+	if err != nil {
+		return nil, fmt.Errorf("error reopening todo: %w", err)
+	}
+
+	if !reopened {
+		return nil, &NotFoundError{ID: todoID}
+	}
+	return t.readTodo(ctx, todoID)
+}
+
+// readTodo reads a todo after a change, so callers get its stored state
+func (t *TodosMcpTool) readTodo(ctx context.Context, id int) (*data.Todo, error) {
+	todos, err := t.db.ReadTodosAsync(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("error reading todo: %w", err)
+	}
+	if len(todos) == 0 {
+		return nil, &NotFoundError{ID: id}
+	}
+	return &todos[0], nil
+}
+
+// DeleteTodo deletes a todo by id and returns the id of the deleted todo. It
+// returns an *InvalidIDError or *NotFoundError when the id does not name a todo.
+func (t *TodosMcpTool) DeleteTodo(ctx context.Context, id string) (int, error) {
+	todoID, err := strconv.Atoi(id)
+	if err != nil {
+		return 0, &InvalidIDError{Value: id}
+	}
+
+	deleted, err := t.db.DeleteTodoAsync(ctx, todoID)
+	if err != nil {
+		return 0, fmt.Errorf("error deleting todo: %w", err)
 	}
 
 	if !deleted {
-		return fmt.Sprintf("Todo with Id %d not found.", todoID), nil
+		return 0, &NotFoundError{ID: todoID}
+	}
+	return todoID, nil
+}
+
+// DeleteTodoAsync deletes a todo by id. It returns an *InvalidIDError or
+// *NotFoundError when the id does not name a todo.
+func (t *TodosMcpTool) DeleteTodoAsync(ctx context.Context, id string) (string, error) {
+	todoID, err := t.DeleteTodo(ctx, id)
+	if err != nil {
+		return "", err
 	}
+	return deletedMessage(todoID), nil
+}
+
+// validateCreate checks the due date fields and tags of a new todo, naming
+// each offending field with prefix, and returns the normalized tags
+func validateCreate(prefix string, input data.CreateTodoInput) ([]string, []FieldError) {
+	fields := validateDue(prefix, input.DueDate, input.DueTime, input.DueTimeZone)
+	tags, tagFields := normalizeTags(prefix+"tags", input.Tags)
+	return tags, append(fields, tagFields...)
+}
 
-	return fmt.Sprintf("Todo %d deleted.", todoID), nil
-}
\ No newline at end of file
+// createdMessage describes a newly created todo
+func createdMessage(todo *data.Todo) string {
+	return fmt.Sprintf("Todo created: %s (Id: %d)", *todo.Description, todo.ID)
+}
+
+// updatedMessage describes a successful update
+func updatedMessage(id int) string {
+	return fmt.Sprintf("Todo %d updated.", id)
+}
+
+// completedMessage describes a todo marked as done
+func completedMessage(id int) string {
+	return fmt.Sprintf("Todo %d completed.", id)
+}
+
+// reopenedMessage describes a todo marked as open again
+func reopenedMessage(id int) string {
+	return fmt.Sprintf("Todo %d reopened.", id)
+}
+
+// deletedMessage describes a successful deletion
+func deletedMessage(id int) string {
+	return fmt.Sprintf("Todo %d deleted.", id)
+}
+
+// morePagesMessage tells the model how to read the page after the current one
+func morePagesMessage(cursor string) string {
+	return fmt.Sprintf("More todos follow. Call read_todos with cursor %q to read the next page.", cursor)
+}
+
+// importedMessage describes a completed import
+func importedMessage(count int) string {
+	noun := "todos"
+	if count == 1 {
+		noun = "todo"
+	}
+	return fmt.Sprintf("Imported %d %s.", count, noun)
+}
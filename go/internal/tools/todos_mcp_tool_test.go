@@ -1,6 +1,8 @@
 package tools
 
 import (
+	"context"
+	"errors"
 	"strings"
 	"testing"
 	"time"
@@ -8,23 +10,22 @@ import (
 	"github.com/matpadley/MCPServer_Demo/go/internal/data"
 )
 
-func createTestDatabase(t *testing.T) *data.DatabaseContext {
-	db, err := data.NewInMemoryDatabaseContext()
-	if err != nil {
-		t.Fatalf("Failed to create in-memory database: %v", err)
-	}
-	return db
+// createTestStore returns an empty in-memory store. The SQLite backend is
+// covered by the conformance suite in the data package.
+func createTestStore(t *testing.T) data.TodoStore {
+	t.Helper()
+	return data.NewMemoryStore()
 }
 
 func TestCreateTodoAsync(t *testing.T) {
-	db := createTestDatabase(t)
+	db := createTestStore(t)
 	defer db.Close()
 
 	tool := NewTodosMcpTool(db)
 	description := "Test todo"
 	createdDate := time.Now()
 
-	result, err := tool.CreateTodoAsync(description, createdDate)
+	result, err := tool.CreateTodoAsync(t.Context(), description, createdDate)
 	if err != nil {
 		t.Fatalf("CreateTodoAsync failed: %v", err)
 	}
@@ -41,16 +42,16 @@ func TestCreateTodoAsync(t *testing.T) {
 }
 
 func TestReadTodosAsync_AllTodos(t *testing.T) {
-	db := createTestDatabase(t)
+	db := createTestStore(t)
 	defer db.Close()
 
 	tool := NewTodosMcpTool(db)
 
 	// Create test todos
-	_, _ = tool.CreateTodoAsync("Todo 1", time.Now())
-	_, _ = tool.CreateTodoAsync("Todo 2", time.Now().Add(time.Hour))
+	_, _ = tool.CreateTodoAsync(t.Context(), "Todo 1", time.Now())
+	_, _ = tool.CreateTodoAsync(t.Context(), "Todo 2", time.Now().Add(time.Hour))
 
-	todos, err := tool.ReadTodosAsync(nil)
+	todos, err := tool.ReadTodosAsync(t.Context(), nil)
 	if err != nil {
 		t.Fatalf("ReadTodosAsync failed: %v", err)
 	}
@@ -61,17 +62,17 @@ func TestReadTodosAsync_AllTodos(t *testing.T) {
 }
 
 func TestReadTodosAsync_SpecificTodo(t *testing.T) {
-	db := createTestDatabase(t)
+	db := createTestStore(t)
 	defer db.Close()
 
 	tool := NewTodosMcpTool(db)
 
 	// Create test todo
-	_, _ = tool.CreateTodoAsync("Test todo", time.Now())
+	_, _ = tool.CreateTodoAsync(t.Context(), "Test todo", time.Now())
 
 	// Read by ID
 	id := "1"
-	todos, err := tool.ReadTodosAsync(&id)
+	todos, err := tool.ReadTodosAsync(t.Context(), &id)
 	if err != nil {
 		t.Fatalf("ReadTodosAsync failed: %v", err)
 	}
@@ -85,53 +86,47 @@ func TestReadTodosAsync_SpecificTodo(t *testing.T) {
 }
 
 func TestReadTodosAsync_InvalidId(t *testing.T) {
-	db := createTestDatabase(t)
+	db := createTestStore(t)
 	defer db.Close()
 
 	tool := NewTodosMcpTool(db)
 
 	// Try to read with invalid ID
 	invalidId := "invalid"
-	todos, err := tool.ReadTodosAsync(&invalidId)
-	if err != nil {
-		t.Fatalf("ReadTodosAsync failed: %v", err)
-	}
-
-	if len(todos) != 0 {
-		t.Errorf("Expected 0 todos for invalid ID, got %d", len(todos))
+	_, err := tool.ReadTodosAsync(t.Context(), &invalidId)
+	var invalidID *InvalidIDError
+	if !errors.As(err, &invalidID) {
+		t.Errorf("Expected InvalidIDError, got %v", err)
 	}
 }
 
 func TestReadTodosAsync_NonExistentId(t *testing.T) {
-	db := createTestDatabase(t)
+	db := createTestStore(t)
 	defer db.Close()
 
 	tool := NewTodosMcpTool(db)
 
 	// Try to read non-existent todo
 	id := "999"
-	todos, err := tool.ReadTodosAsync(&id)
-	if err != nil {
-		t.Fatalf("ReadTodosAsync failed: %v", err)
-	}
-
-	if len(todos) != 0 {
-		t.Errorf("Expected 0 todos for non-existent ID, got %d", len(todos))
+	_, err := tool.ReadTodosAsync(t.Context(), &id)
+	var notFound *NotFoundError
+	if !errors.As(err, &notFound) || notFound.ID != 999 {
+		t.Errorf("Expected NotFoundError for 999, got %v", err)
 	}
 }
 
 func TestUpdateTodoAsync_ValidId(t *testing.T) {
-	db := createTestDatabase(t)
+	db := createTestStore(t)
 	defer db.Close()
 
 	tool := NewTodosMcpTool(db)
 
 	// Create test todo
-	_, _ = tool.CreateTodoAsync("Original description", time.Now())
+	_, _ = tool.CreateTodoAsync(t.Context(), "Original description", time.Now())
 
 	newDescription := "Updated description"
 	newDate := time.Now().Add(24 * time.Hour)
-	result, err := tool.UpdateTodoAsync("1", &newDescription, &newDate)
+	result, err := tool.UpdateTodoAsync(t.Context(), "1", &newDescription, &newDate)
 	if err != nil {
 		t.Fatalf("UpdateTodoAsync failed: %v", err)
 	}
@@ -142,7 +137,7 @@ func TestUpdateTodoAsync_ValidId(t *testing.T) {
 
 	// Verify update
 	id := "1"
-	todos, _ := tool.ReadTodosAsync(&id)
+	todos, _ := tool.ReadTodosAsync(t.Context(), &id)
 	if len(todos) != 1 {
 		t.Fatalf("Expected 1 todo after update, got %d", len(todos))
 	}
@@ -155,16 +150,16 @@ func TestUpdateTodoAsync_ValidId(t *testing.T) {
 }
 
 func TestUpdateTodoAsync_OnlyDescription(t *testing.T) {
-	db := createTestDatabase(t)
+	db := createTestStore(t)
 	defer db.Close()
 
 	tool := NewTodosMcpTool(db)
 
 	originalDate := time.Now()
-	_, _ = tool.CreateTodoAsync("Original description", originalDate)
+	_, _ = tool.CreateTodoAsync(t.Context(), "Original description", originalDate)
 
 	newDescription := "Updated description"
-	result, err := tool.UpdateTodoAsync("1", &newDescription, nil)
+	result, err := tool.UpdateTodoAsync(t.Context(), "1", &newDescription, nil)
 	if err != nil {
 		t.Fatalf("UpdateTodoAsync failed: %v", err)
 	}
@@ -175,7 +170,7 @@ func TestUpdateTodoAsync_OnlyDescription(t *testing.T) {
 
 	// Verify update
 	id := "1"
-	todos, _ := tool.ReadTodosAsync(&id)
+	todos, _ := tool.ReadTodosAsync(t.Context(), &id)
 	if len(todos) != 1 {
 		t.Fatalf("Expected 1 todo after update, got %d", len(todos))
 	}
@@ -188,17 +183,17 @@ func TestUpdateTodoAsync_OnlyDescription(t *testing.T) {
 }
 
 func TestUpdateTodoAsync_EmptyDescription(t *testing.T) {
-	db := createTestDatabase(t)
+	db := createTestStore(t)
 	defer db.Close()
 
 	tool := NewTodosMcpTool(db)
 
 	originalDescription := "Original description"
-	_, _ = tool.CreateTodoAsync(originalDescription, time.Now())
+	_, _ = tool.CreateTodoAsync(t.Context(), originalDescription, time.Now())
 
 	emptyDescription := ""
 	newDate := time.Now().Add(24 * time.Hour)
-	result, err := tool.UpdateTodoAsync("1", &emptyDescription, &newDate)
+	result, err := tool.UpdateTodoAsync(t.Context(), "1", &emptyDescription, &newDate)
 	if err != nil {
 		t.Fatalf("UpdateTodoAsync failed: %v", err)
 	}
@@ -209,7 +204,7 @@ func TestUpdateTodoAsync_EmptyDescription(t *testing.T) {
 
 	// Verify description wasn't changed
 	id := "1"
-	todos, _ := tool.ReadTodosAsync(&id)
+	todos, _ := tool.ReadTodosAsync(t.Context(), &id)
 	if len(todos) != 1 {
 		t.Fatalf("Expected 1 todo after update, got %d", len(todos))
 	}
@@ -222,49 +217,51 @@ func TestUpdateTodoAsync_EmptyDescription(t *testing.T) {
 }
 
 func TestUpdateTodoAsync_InvalidId(t *testing.T) {
-	db := createTestDatabase(t)
+	db := createTestStore(t)
 	defer db.Close()
 
 	tool := NewTodosMcpTool(db)
 
 	newDescription := "New description"
-	result, err := tool.UpdateTodoAsync("invalid", &newDescription, nil)
-	if err != nil {
-		t.Fatalf("UpdateTodoAsync failed: %v", err)
-	}
+	_, err := tool.UpdateTodoAsync(t.Context(), "invalid", &newDescription, nil)
 
-	if result != "Invalid todo id." {
-		t.Errorf("Expected 'Invalid todo id.', got: %s", result)
+	var invalidID *InvalidIDError
+	if !errors.As(err, &invalidID) {
+		t.Fatalf("Expected InvalidIDError, got: %v", err)
+	}
+	if err.Error() != "Invalid todo id." {
+		t.Errorf("Expected 'Invalid todo id.', got: %s", err.Error())
 	}
 }
 
 func TestUpdateTodoAsync_NonExistentId(t *testing.T) {
-	db := createTestDatabase(t)
+	db := createTestStore(t)
 	defer db.Close()
 
 	tool := NewTodosMcpTool(db)
 
 	newDescription := "New description"
-	result, err := tool.UpdateTodoAsync("999", &newDescription, nil)
-	if err != nil {
-		t.Fatalf("UpdateTodoAsync failed: %v", err)
-	}
+	_, err := tool.UpdateTodoAsync(t.Context(), "999", &newDescription, nil)
 
-	if result != "Todo with Id 999 not found." {
-		t.Errorf("Expected 'Todo with Id 999 not found.', got: %s", result)
+	var notFound *NotFoundError
+	if !errors.As(err, &notFound) || notFound.ID != 999 {
+		t.Fatalf("Expected NotFoundError for 999, got: %v", err)
+	}
+	if err.Error() != "Todo with Id 999 not found." {
+		t.Errorf("Expected 'Todo with Id 999 not found.', got: %s", err.Error())
 	}
 }
 
 func TestDeleteTodoAsync_ValidId(t *testing.T) {
-	db := createTestDatabase(t)
+	db := createTestStore(t)
 	defer db.Close()
 
 	tool := NewTodosMcpTool(db)
 
 	// Create test todo
-	_, _ = tool.CreateTodoAsync("Test todo", time.Now())
+	_, _ = tool.CreateTodoAsync(t.Context(), "Test todo", time.Now())
 
-	result, err := tool.DeleteTodoAsync("1")
+	result, err := tool.DeleteTodoAsync(t.Context(), "1")
 	if err != nil {
 		t.Fatalf("DeleteTodoAsync failed: %v", err)
 	}
@@ -275,70 +272,108 @@ func TestDeleteTodoAsync_ValidId(t *testing.T) {
 
 	// Verify deletion
 	id := "1"
-	todos, _ := tool.ReadTodosAsync(&id)
+	todos, _ := tool.ReadTodosAsync(t.Context(), &id)
 	if len(todos) != 0 {
 		t.Errorf("Expected 0 todos after deletion, got %d", len(todos))
 	}
 }
 
 func TestDeleteTodoAsync_InvalidId(t *testing.T) {
-	db := createTestDatabase(t)
+	db := createTestStore(t)
 	defer db.Close()
 
 	tool := NewTodosMcpTool(db)
 
-	result, err := tool.DeleteTodoAsync("invalid")
-	if err != nil {
-		t.Fatalf("DeleteTodoAsync failed: %v", err)
-	}
+	_, err := tool.DeleteTodoAsync(t.Context(), "invalid")
 
-	if result != "Invalid todo id." {
-		t.Errorf("Expected 'Invalid todo id.', got: %s", result)
+	var invalidID *InvalidIDError
+	if !errors.As(err, &invalidID) {
+		t.Fatalf("Expected InvalidIDError, got: %v", err)
 	}
 }
 
 func TestDeleteTodoAsync_NonExistentId(t *testing.T) {
-	db := createTestDatabase(t)
+	db := createTestStore(t)
 	defer db.Close()
 
 	tool := NewTodosMcpTool(db)
 
-	result, err := tool.DeleteTodoAsync("999")
+	_, err := tool.DeleteTodoAsync(t.Context(), "999")
+
+	var notFound *NotFoundError
+	if !errors.As(err, &notFound) || notFound.ID != 999 {
+		t.Fatalf("Expected NotFoundError for 999, got: %v", err)
+	}
+}
+
+func TestCompleteTodo(t *testing.T) {
+	db := createTestStore(t)
+	defer db.Close()
+
+	tool := NewTodosMcpTool(db)
+	_, _ = tool.CreateTodoAsync(t.Context(), "Test todo", time.Now())
+
+	todo, err := tool.CompleteTodo(t.Context(), "1")
 	if err != nil {
-		t.Fatalf("DeleteTodoAsync failed: %v", err)
+		t.Fatalf("CompleteTodo failed: %v", err)
+	}
+	if todo.Status != data.StatusDone || todo.CompletedAt == nil {
+		t.Errorf("Expected todo to be done with a completion time, got %+v", todo)
+	}
+
+	todo, err = tool.ReopenTodo(t.Context(), "1")
+	if err != nil {
+		t.Fatalf("ReopenTodo failed: %v", err)
+	}
+	if todo.Status != data.StatusOpen || todo.CompletedAt != nil {
+		t.Errorf("Expected todo to be open again, got %+v", todo)
 	}
+}
+
+func TestCompleteTodo_InvalidAndNonExistentId(t *testing.T) {
+	db := createTestStore(t)
+	defer db.Close()
 
-	if result != "Todo with Id 999 not found." {
-		t.Errorf("Expected 'Todo with Id 999 not found.', got: %s", result)
+	tool := NewTodosMcpTool(db)
+
+	for _, call := range []func(context.Context, string) (*data.Todo, error){tool.CompleteTodo, tool.ReopenTodo} {
+		var invalidID *InvalidIDError
+		if _, err := call(t.Context(), "invalid"); !errors.As(err, &invalidID) {
+			t.Errorf("Expected InvalidIDError, got: %v", err)
+		}
+		var notFound *NotFoundError
+		if _, err := call(t.Context(), "999"); !errors.As(err, &notFound) || notFound.ID != 999 {
+			t.Errorf("Expected NotFoundError for 999, got: %v", err)
+		}
 	}
 }
 
 func TestMultipleOperationsInSequence(t *testing.T) {
-	db := createTestDatabase(t)
+	db := createTestStore(t)
 	defer db.Close()
 
 	tool := NewTodosMcpTool(db)
 
 	// Create multiple todos
-	_, _ = tool.CreateTodoAsync("Todo 1", time.Now())
-	_, _ = tool.CreateTodoAsync("Todo 2", time.Now().Add(time.Hour))
-	_, _ = tool.CreateTodoAsync("Todo 3", time.Now().Add(2*time.Hour))
+	_, _ = tool.CreateTodoAsync(t.Context(), "Todo 1", time.Now())
+	_, _ = tool.CreateTodoAsync(t.Context(), "Todo 2", time.Now().Add(time.Hour))
+	_, _ = tool.CreateTodoAsync(t.Context(), "Todo 3", time.Now().Add(2*time.Hour))
 
 	// Read all todos
-	todos, _ := tool.ReadTodosAsync(nil)
+	todos, _ := tool.ReadTodosAsync(t.Context(), nil)
 	if len(todos) != 3 {
 		t.Errorf("Expected 3 todos, got %d", len(todos))
 	}
 
 	// Update middle todo
 	newDescription := "Updated Todo 2"
-	_, _ = tool.UpdateTodoAsync("2", &newDescription, nil)
+	_, _ = tool.UpdateTodoAsync(t.Context(), "2", &newDescription, nil)
 
 	// Delete first todo
-	_, _ = tool.DeleteTodoAsync("1")
+	_, _ = tool.DeleteTodoAsync(t.Context(), "1")
 
 	// Verify final state
-	todos, _ = tool.ReadTodosAsync(nil)
+	todos, _ = tool.ReadTodosAsync(t.Context(), nil)
 	if len(todos) != 2 {
 		t.Errorf("Expected 2 todos after operations, got %d", len(todos))
 	}
@@ -356,4 +391,4 @@ func TestMultipleOperationsInSequence(t *testing.T) {
 	if !foundUpdatedTodo {
 		t.Error("Todo 2 should have been updated")
 	}
-}
\ No newline at end of file
+}